@@ -0,0 +1,275 @@
+// Package audit keeps an append-only, hash-chained log of the changes
+// waxseal makes to secrets: who rotated, updated, added or retired which
+// key, when, between which store versions, and why.
+//
+// The log is JSON Lines in .waxseal/audit/audit.jsonl. Each entry carries
+// a sequence number, the hash of the previous entry and its own hash, so
+// Verify detects edited, reordered, inserted or removed entries. The
+// sequence number and hash of the newest entry are also kept in
+// .waxseal/audit/head.json, so removing entries from the end is detected too.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/files"
+)
+
+// LogPath is the audit log location, relative to the repo.
+const LogPath = ".waxseal/audit/audit.jsonl"
+
+// HeadPath records the newest entry of the log, relative to the repo.
+const HeadPath = ".waxseal/audit/head.json"
+
+// Head anchors the end of the log: the sequence number and hash of its
+// newest entry.
+type Head struct {
+	Seq  int    `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// Entry is one audit record.
+type Entry struct {
+	Seq        int    `json:"seq"`
+	Time       string `json:"time"` // RFC3339
+	Actor      string `json:"actor"`
+	Command    string `json:"command"`
+	ShortName  string `json:"shortName"`
+	KeyName    string `json:"keyName,omitempty"`
+	OldVersion string `json:"oldVersion,omitempty"`
+	NewVersion string `json:"newVersion,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	PrevHash   string `json:"prevHash"`
+	Hash       string `json:"hash"`
+}
+
+// computeHash returns the hash of an entry: SHA-256 over its JSON encoding
+// with the Hash field empty. PrevHash is included, chaining the entries.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Log is the audit log of a repo.
+type Log struct {
+	path     string
+	headPath string
+}
+
+// Open returns the audit log of the repo at repoPath. The file is created
+// on the first Append.
+func Open(repoPath string) *Log {
+	return &Log{
+		path:     filepath.Join(repoPath, LogPath),
+		headPath: filepath.Join(repoPath, HeadPath),
+	}
+}
+
+// Append completes an entry (sequence number, time, previous and own hash)
+// and appends it to the log. Existing entries are never rewritten. The log
+// is locked while it is read and appended to, so concurrent runs do not
+// reuse a sequence number.
+func (l *Log) Append(e Entry) (Entry, error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return Entry{}, err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return Entry{}, fmt.Errorf("lock %s: %w", LogPath, err)
+	}
+	defer unlockFile(f)
+
+	entries, err := l.Entries()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e.Seq = 1
+	e.PrevHash = ""
+	if n := len(entries); n > 0 {
+		e.Seq = entries[n-1].Seq + 1
+		e.PrevHash = entries[n-1].Hash
+	}
+	if e.Time == "" {
+		e.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+	if e.Hash, err = e.computeHash(); err != nil {
+		return Entry{}, err
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return Entry{}, err
+	}
+	if err := f.Sync(); err != nil {
+		return Entry{}, err
+	}
+	if err := l.writeHead(Head{Seq: e.Seq, Hash: e.Hash}); err != nil {
+		return Entry{}, fmt.Errorf("write %s: %w", HeadPath, err)
+	}
+	return e, nil
+}
+
+// writeHead replaces the head file atomically.
+func (l *Log) writeHead(head Head) error {
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	return files.NewAtomicWriter().Write(l.headPath, append(data, '\n'))
+}
+
+// Head reads the head file. Returns nil if it does not exist.
+func (l *Log) Head() (*Head, error) {
+	data, err := os.ReadFile(l.headPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var head Head
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("%s: %w", HeadPath, err)
+	}
+	return &head, nil
+}
+
+// Entries reads every entry of the log, in file order.
+// Returns no entries if the log does not exist yet.
+func (l *Log) Entries() ([]Entry, error) {
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", LogPath, lineNo, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Problem is an integrity failure found by Verify.
+type Problem struct {
+	Seq     int // sequence number of the entry where the chain breaks
+	Message string
+}
+
+// Verify checks the log's hash chain. It reports entries whose hash does
+// not match their content, entries that do not point at the hash of the
+// entry before them, missing or out-of-order sequence numbers, and a log
+// whose newest entry is not the one the head file records.
+func (l *Log) Verify() ([]Problem, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	head, err := l.Head()
+	if err != nil {
+		return nil, err
+	}
+	return append(verifyEntries(entries), verifyHead(entries, head)...), nil
+}
+
+func verifyEntries(entries []Entry) []Problem {
+	var problems []Problem
+	prevHash := ""
+	for i, e := range entries {
+		if want := i + 1; e.Seq != want {
+			problems = append(problems, Problem{Seq: e.Seq,
+				Message: fmt.Sprintf("sequence number %d, expected %d (entries missing or reordered)", e.Seq, want)})
+		}
+		if e.PrevHash != prevHash {
+			problems = append(problems, Problem{Seq: e.Seq,
+				Message: "previous-entry hash does not match (an earlier entry was changed or removed)"})
+		}
+		if hash, err := e.computeHash(); err != nil || hash != e.Hash {
+			problems = append(problems, Problem{Seq: e.Seq,
+				Message: "entry hash does not match its content (entry was edited)"})
+		}
+		prevHash = e.Hash
+	}
+	return problems
+}
+
+// verifyHead checks that the log ends at the entry the head file records.
+func verifyHead(entries []Entry, head *Head) []Problem {
+	n := len(entries)
+	switch {
+	case head == nil && n == 0:
+		return nil
+	case head == nil:
+		return []Problem{{Seq: entries[n-1].Seq,
+			Message: fmt.Sprintf("%s is missing (newest entries may have been removed)", HeadPath)}}
+	case n == 0 || entries[n-1].Seq < head.Seq:
+		return []Problem{{Seq: head.Seq,
+			Message: fmt.Sprintf("entry missing: %s records it as the newest (entries removed from the end)", HeadPath)}}
+	case entries[n-1].Seq != head.Seq || entries[n-1].Hash != head.Hash:
+		return []Problem{{Seq: entries[n-1].Seq,
+			Message: fmt.Sprintf("newest entry does not match %s (log or head was changed)", HeadPath)}}
+	}
+	return nil
+}
+
+// Actor identifies who is running waxseal: the git user of the repo
+// ("Name <email>"), falling back to $USER.
+func Actor(repoPath string) string {
+	name := gitConfig(repoPath, "user.name")
+	email := gitConfig(repoPath, "user.email")
+	switch {
+	case name != "" && email != "":
+		return fmt.Sprintf("%s <%s>", name, email)
+	case name != "":
+		return name
+	case email != "":
+		return email
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "unknown"
+}
+
+func gitConfig(repoPath, key string) string {
+	out, err := exec.Command("git", "-C", repoPath, "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func appendEntries(t *testing.T, l *Log, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := l.Append(Entry{
+			Actor:      "Ada <ada@example.com>",
+			Command:    "rotate",
+			ShortName:  "db",
+			KeyName:    "password",
+			OldVersion: "1",
+			NewVersion: "2",
+			Reason:     "scheduled",
+		}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	l := Open(t.TempDir())
+
+	entries, err := l.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Entries on missing log = %v, %v", entries, err)
+	}
+
+	appendEntries(t, l, 3)
+
+	entries, err = l.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Seq != 1 || entries[0].PrevHash != "" {
+		t.Errorf("first entry = seq %d prev %q", entries[0].Seq, entries[0].PrevHash)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Seq != i+1 {
+			t.Errorf("entry %d seq = %d", i, entries[i].Seq)
+		}
+		if entries[i].PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d is not chained to the previous one", i)
+		}
+	}
+	if !strings.HasPrefix(entries[0].Hash, "sha256:") || entries[0].Time == "" {
+		t.Errorf("entry not completed: %+v", entries[0])
+	}
+
+	problems, err := l.Verify()
+	if err != nil || len(problems) != 0 {
+		t.Errorf("Verify = %v, %v", problems, err)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+	}{
+		{
+			name: "edited entry",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"reason":"scheduled"`, `"reason":"nothing to see"`, 1)
+				return lines
+			},
+		},
+		{
+			name: "removed entry",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+		},
+		{
+			name: "removed first entry",
+			tamper: func(lines []string) []string {
+				return lines[1:]
+			},
+		},
+		{
+			name: "removed newest entries",
+			tamper: func(lines []string) []string {
+				return lines[:1]
+			},
+		},
+		{
+			name: "reordered entries",
+			tamper: func(lines []string) []string {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l := Open(dir)
+			appendEntries(t, l, 3)
+
+			path := filepath.Join(dir, LogPath)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			lines = tt.tamper(lines)
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := l.Verify()
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if len(problems) == 0 {
+				t.Error("expected Verify to report tampering")
+			}
+		})
+	}
+}
+
+func TestVerify_DetectsMissingHead(t *testing.T) {
+	dir := t.TempDir()
+	l := Open(dir)
+	appendEntries(t, l, 2)
+
+	head, err := l.Head()
+	if err != nil || head == nil || head.Seq != 2 {
+		t.Fatalf("Head = %+v, %v; want seq 2", head, err)
+	}
+	if err := os.Remove(filepath.Join(dir, HeadPath)); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := l.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "missing") {
+		t.Errorf("problems = %+v, want the missing head", problems)
+	}
+}
+
+func TestAppend_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+
+	// Separate Logs stand in for separate waxseal runs
+	const runs = 8
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Open(dir).Append(Entry{Command: "rotate", ShortName: "db"}); err != nil {
+				t.Errorf("Append failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	l := Open(dir)
+	entries, err := l.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != runs {
+		t.Fatalf("got %d entries, want %d", len(entries), runs)
+	}
+	problems, err := l.Verify()
+	if err != nil || len(problems) != 0 {
+		t.Errorf("Verify = %v, %v", problems, err)
+	}
+}
+
+func TestVerify_UnparseableLine(t *testing.T) {
+	dir := t.TempDir()
+	l := Open(dir)
+	appendEntries(t, l, 1)
+
+	f, err := os.OpenFile(filepath.Join(dir, LogPath), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{not json\n")
+	f.Close()
+
+	if _, err := l.Verify(); err == nil {
+		t.Error("expected error for unparseable line")
+	}
+}
+
+func TestActor_FallsBackToUser(t *testing.T) {
+	t.Setenv("USER", "ops")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	// A directory outside any git repo with no git identity
+	if got := Actor(t.TempDir()); got != "ops" {
+		t.Errorf("Actor = %q, want ops", got)
+	}
+}
@@ -2,11 +2,15 @@ package cli
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
+	"github.com/shermanhuman/waxseal/internal/audit"
 	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
 	"github.com/shermanhuman/waxseal/internal/seal"
@@ -29,8 +33,24 @@ except static key values, which are prompted for securely (never in shell
 history). Without --key flags, an interactive TUI wizard collects all input.
 
 Key formats:
-  --key=name             Static key (prompts for value securely)
-  --key=name:random      Generated random value (mode: generated)
+  --key=name                         Static key (prompts for value securely)
+  --key=name:random                  Generated random value (mode: generated)
+  --key=name:<kind>[:param=value...] Generated value of another kind (mode: generated)
+
+Generator kinds: randomBase64, randomBase64URL, randomHex, randomBytes,
+password, passphrase, uuid, bcrypt, htpasswd, ed25519, sshEd25519, rsa, tls.
+Parameters: bytes, length, charset (e.g. lower+upper+digits), exclude,
+words, separator, cost, username, bits, pairedKey. bcrypt, htpasswd and
+the keypair kinds also produce a hash or public key. Name the key that
+receives it with pairedKey or the output name (hash, htpasswd, publicKey);
+that key is created alongside and rewritten on every rotation.
+
+tls issues a certificate for commonName and sans (joined with "+"), valid
+for validity (default 90d). It is self-signed, or signed by the CA whose
+certificate and key are at issuerCert and issuerKey (<secretResource>@<version>).
+The key holds the private key; the cert and ca outputs name the keys for
+the certificate and the CA certificate (with --type kubernetes.io/tls,
+cert defaults to tls.crt). The key's expiry is set from the certificate.
 
 Examples:
   # Interactive mode (no --key flags)
@@ -49,7 +69,20 @@ Examples:
     --namespace=default \
     --key=api_key:random \
     --key=db_password:random \
-    --random-length=64`,
+    --random-length=64
+
+  # Password without symbols, its bcrypt hash, and an SSH keypair
+  waxseal add app \
+    --namespace=default \
+    --key=admin_password:bcrypt:length=24:pairedKey=admin_password_hash \
+    --key=deploy_key:sshEd25519:publicKey=deploy_key.pub \
+    --key=session_id:uuid
+
+  # Webhook serving certificate signed by an internal CA
+  waxseal add webhook-tls \
+    --namespace=infra \
+    --type=kubernetes.io/tls \
+    --key=tls.key:tls:sans=webhook.infra.svc+webhook.infra.svc.cluster.local:validity=30d:issuerCert=projects/p/secrets/internal-ca-crt@1:issuerKey=projects/p/secrets/internal-ca-key@1`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAdd,
 }
@@ -61,17 +94,19 @@ var (
 	addScope        string
 	addSecretType   string
 	addRandomLength int
+	addReason       string
 )
 
 func init() {
 	rootCmd.AddCommand(addCmd)
 	addCmd.Flags().StringVar(&addNamespace, "namespace", "", "Kubernetes namespace")
-	addCmd.Flags().StringSliceVar(&addKeys, "key", nil, "Key name (use name:random to auto-generate)")
+	addCmd.Flags().StringSliceVar(&addKeys, "key", nil, "Key name (use name:random or name:<kind>[:param=value...] to auto-generate)")
 	addCmd.Flags().StringVar(&addManifestPath, "manifest-path", "", "Path for SealedSecret manifest")
 	addCmd.Flags().StringVar(&addScope, "scope", "strict", "Sealing scope (strict, namespace-wide, cluster-wide)")
 	addCmd.Flags().StringVar(&addSecretType, "type", "Opaque", "Secret type (Opaque, kubernetes.io/tls, etc.)")
 	addCmd.Flags().IntVar(&addRandomLength, "random-length", 32, "Length of generated random values (bytes)")
-	addPreflightChecks(addCmd, authNeeds{gsm: true, kubeseal: true})
+	addCmd.Flags().StringVar(&addReason, "reason", "", "Reason for adding the secret, recorded in the audit log")
+	addPreflightChecks(addCmd, authNeeds{store: true, sealer: true})
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
@@ -108,23 +143,66 @@ func runAdd(cmd *cobra.Command, args []string) error {
 			manifestPath = fmt.Sprintf("apps/%s/sealed-secret.yaml", shortName)
 		}
 
+		// Opened on demand, for a tls generator whose issuer is in the store
+		var issuerStore store.Store
+
 		// Parse keys: name:random (generated) or name (static, prompts for value)
 		for _, k := range addKeys {
 			var keyName string
 			var value []byte
 			var rotationMode string
 			var generator *core.GeneratorConfig
+			var expiry *core.ExpiryConfig
+
+			var outputs []addKeyInput
+			if name, spec, ok := strings.Cut(k, ":"); ok {
+				// name:random or name:<kind>[:param=value...] → generated key
+				keyName = name
+				if spec == "random" {
+					generator = &core.GeneratorConfig{Kind: "randomBase64", Bytes: addRandomLength}
+				} else {
+					var err error
+					generator, err = core.ParseGeneratorSpec(spec)
+					if err != nil {
+						return fmt.Errorf("key %q: %w", keyName, err)
+					}
+				}
+				// A kubernetes.io/tls secret's certificate goes to tls.crt
+				if generator.Kind == "tls" && secretType == "kubernetes.io/tls" && generator.Outputs["cert"] == "" {
+					if generator.Outputs == nil {
+						generator.Outputs = make(map[string]string)
+					}
+					generator.Outputs["cert"] = "tls.crt"
+				}
+				if generator.Issuer != nil && issuerStore == nil {
+					s, closeIssuerStore, err := resolveStore(ctx, cfg)
+					if err != nil {
+						return err
+					}
+					defer closeIssuerStore()
+					issuerStore = s
+				}
 
-			if parts := strings.SplitN(k, ":", 2); len(parts) > 1 && parts[1] == "random" {
-				// name:random → generated key
-				keyName = parts[0]
-				generator = &core.GeneratorConfig{Kind: "randomBase64", Bytes: addRandomLength}
-				var err error
-				value, err = core.GenerateValue(generator)
+				generated, err := generateBundle(ctx, issuerStore, generator)
 				if err != nil {
 					return fmt.Errorf("generate value for key %q: %w", keyName, err)
 				}
+				value = generated.Value
 				rotationMode = "generated"
+				if !generated.NotAfter.IsZero() {
+					expiry = &core.ExpiryConfig{ExpiresAt: generated.NotAfter.UTC().Format(time.RFC3339)}
+				}
+
+				// The other outputs (hash, public key) go to their own keys,
+				// which are rewritten whenever this key rotates.
+				outputKeys := generator.OutputKeys()
+				for _, output := range slices.Sorted(maps.Keys(outputKeys)) {
+					outputs = append(outputs, addKeyInput{
+						keyName:      outputKeys[output],
+						value:        generated.Outputs[output],
+						rotationMode: "static",
+					})
+				}
 			} else {
 				// name → static key, prompt for value securely
 				keyName = k
@@ -153,7 +231,17 @@ func runAdd(cmd *cobra.Command, args []string) error {
 				value:        value,
 				rotationMode: rotationMode,
 				generator:    generator,
+				expiry:       expiry,
 			})
+			keys = append(keys, outputs...)
+		}
+
+		seen := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			if seen[k.keyName] {
+				return fmt.Errorf("key %q is given more than once", k.keyName)
+			}
+			seen[k.keyName] = true
 		}
 	} else {
 		// Interactive mode
@@ -221,6 +309,13 @@ func runAdd(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("create GSM secret %s: %w", k.keyName, err)
 		}
 		printSuccess("Created GSM secret: %s (version %s)", k.keyName, version)
+		recordAudit(audit.Entry{
+			Command:    "addkey",
+			ShortName:  shortName,
+			KeyName:    k.keyName,
+			NewVersion: version,
+			Reason:     addReason,
+		})
 
 		keyMetadata = append(keyMetadata, core.KeyMetadata{
 			KeyName: k.keyName,
@@ -233,6 +328,7 @@ func runAdd(cmd *cobra.Command, args []string) error {
 				Mode:      keysByName[k.keyName].rotationMode,
 				Generator: keysByName[k.keyName].generator,
 			},
+			Expiry: keysByName[k.keyName].expiry,
 		})
 	}
 
@@ -263,8 +359,10 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	manifestFullPath := filepath.Join(repoPath, manifestPath)
 	os.MkdirAll(filepath.Dir(manifestFullPath), 0o755)
 
-	// Use kubeseal binary for encryption (guarantees controller compatibility)
-	sealer := resolveSealer(cfg)
+	sealer, err := resolveSealer(cfg)
+	if err != nil {
+		return err
+	}
 
 	// Seal each key and build SealedSecret
 	encryptedData := make(map[string]string)
@@ -303,6 +401,7 @@ type addKeyInput struct {
 	value        []byte
 	rotationMode string // "static", "generated", "external"
 	generator    *core.GeneratorConfig
+	expiry       *core.ExpiryConfig // set for generated certificates
 }
 
 func runAddInteractive(shortName, projectID string) (namespace, manifestPath, scope, secretType string, keys []addKeyInput, err error) {
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log",
+	Long: `Inspect the audit log in .waxseal/audit/audit.jsonl.
+
+Every change waxseal makes to a secret value (addkey, updatekey, rotate,
+bootstrap, retirekey) appends an entry recording who made it, the command,
+the key, the old and new store versions and the --reason given. Entries are
+hash-chained: each holds the hash of the one before it.
+
+Subcommands:
+  list     Show audit entries
+  verify   Check the hash chain for edits and gaps`,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list [shortName]",
+	Short: "Show audit entries",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runAuditList,
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the audit log's hash chain",
+	Long: `Verify recomputes the hash of every audit entry and checks that each
+entry points at the hash of the one before it, with consecutive sequence
+numbers. Edited, reordered, inserted or removed entries are reported.
+
+Exit codes:
+  0 - The log is intact
+  1 - The log was tampered with or is unreadable`,
+	Args: cobra.NoArgs,
+	RunE: runAuditVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+}
+
+func runAuditList(cmd *cobra.Command, args []string) error {
+	entries, err := audit.Open(repoPath).Entries()
+	if err != nil {
+		return err
+	}
+
+	shown := 0
+	for _, e := range entries {
+		if len(args) > 0 && e.ShortName != args[0] {
+			continue
+		}
+		shown++
+
+		target := e.ShortName
+		if e.KeyName != "" {
+			target += "/" + e.KeyName
+		}
+		fmt.Printf("#%d  %s  %-10s %s", e.Seq, e.Time, e.Command, target)
+		switch {
+		case e.OldVersion != "" && e.NewVersion != "":
+			fmt.Printf("  v%s → v%s", e.OldVersion, e.NewVersion)
+		case e.NewVersion != "":
+			fmt.Printf("  v%s", e.NewVersion)
+		}
+		fmt.Printf("  by %s\n", e.Actor)
+		if e.Reason != "" {
+			fmt.Printf("      reason: %s\n", e.Reason)
+		}
+	}
+
+	if shown == 0 {
+		fmt.Println("No audit entries.")
+	}
+	return nil
+}
+
+func runAuditVerify(cmd *cobra.Command, args []string) error {
+	log := audit.Open(repoPath)
+	entries, err := log.Entries()
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+	problems, err := log.Verify()
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+
+	if len(problems) > 0 {
+		for _, p := range problems {
+			printError("entry #%d: %s", p.Seq, p.Message)
+		}
+		return fmt.Errorf("audit log failed verification: %d problems in %d entries", len(problems), len(entries))
+	}
+
+	printSuccess("Audit log intact: %d entries", len(entries))
+	return nil
+}
@@ -2,17 +2,16 @@ package cli
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"net/url"
-	"os/exec"
 
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/config"
 	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
 	"github.com/shermanhuman/waxseal/internal/store"
 	"github.com/shermanhuman/waxseal/internal/template"
 	"github.com/spf13/cobra"
-	"sigs.k8s.io/yaml"
 )
 
 var bootstrapCmd = &cobra.Command{
@@ -26,7 +25,8 @@ After bootstrap, you can manage the secrets entirely through waxseal.
 If no shortName is provided, bootstraps ALL discovered secrets.
 
 Prerequisites:
-  - kubectl configured with cluster access
+  - A kubeconfig with cluster access (bootstrap.cluster.kubeContext selects
+    the context)
   - GSM API enabled and IAM permissions to create secrets
   - SealedSecrets already discovered (run 'waxseal discover' first)
 
@@ -55,7 +55,7 @@ var bootstrapKubeconfig string
 func init() {
 	// bootstrapCmd is added to gsmCmd in gcp_bootstrap.go
 	bootstrapCmd.Flags().StringVar(&bootstrapKubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
-	addPreflightChecks(bootstrapCmd, authNeeds{gsm: true, kubectl: true})
+	addPreflightChecks(bootstrapCmd, authNeeds{store: true, cluster: true})
 }
 
 func runBootstrap(cmd *cobra.Command, args []string) error {
@@ -117,7 +117,7 @@ func bootstrapOne(ctx context.Context, shortName string) error {
 	}
 
 	// Read secret from cluster
-	secretData, err := readSecretFromCluster(ctx, metadata.SealedSecret.Namespace, metadata.SealedSecret.Name)
+	secretData, err := readSecretFromCluster(ctx, cfg, metadata.SealedSecret.Namespace, metadata.SealedSecret.Name)
 	if err != nil {
 		return fmt.Errorf("read secret from cluster: %w", err)
 	}
@@ -267,6 +267,13 @@ func bootstrapOne(ctx context.Context, shortName string) error {
 			return fmt.Errorf("push %s to GSM: %w", k.keyName, err)
 		}
 		fmt.Printf("  %s✓%s %s (v%s)\n", styleGreen, styleReset, k.keyName, version)
+		recordAudit(audit.Entry{
+			Command:    "bootstrap",
+			ShortName:  shortName,
+			KeyName:    k.keyName,
+			NewVersion: version,
+			Reason:     "imported from cluster",
+		})
 
 		// Update metadata
 		found := false
@@ -337,45 +344,11 @@ func bootstrapOne(ctx context.Context, shortName string) error {
 	return nil
 }
 
-// readSecretFromCluster uses kubectl to read a secret from the cluster.
-// This is a variable to allow test injection.
-var readSecretFromCluster = defaultReadSecretFromCluster
-
-func defaultReadSecretFromCluster(ctx context.Context, namespace, name string) (map[string][]byte, error) {
-	// Build kubectl command
-	args := []string{"get", "secret", name, "-n", namespace, "-o", "yaml"}
-
-	if bootstrapKubeconfig != "" {
-		args = append([]string{"--kubeconfig", bootstrapKubeconfig}, args...)
-	}
-
-	// Execute kubectl
-	cmd := exec.CommandContext(ctx, "kubectl", args...)
-	output, err := cmd.Output()
+// readSecretFromCluster reads a Secret's data from the cluster cfg selects.
+func readSecretFromCluster(ctx context.Context, cfg *config.Config, namespace, name string) (map[string][]byte, error) {
+	kube, err := resolveCluster(cfg)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("kubectl failed: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("kubectl get secret: %w", err)
-	}
-
-	// Parse YAML
-	var secret struct {
-		Data map[string]string `json:"data"`
-	}
-	if err := yaml.Unmarshal(output, &secret); err != nil {
-		return nil, fmt.Errorf("parse secret YAML: %w", err)
+		return nil, err
 	}
-
-	// Decode base64 values
-	result := make(map[string][]byte)
-	for key, encoded := range secret.Data {
-		decoded, err := base64.StdEncoding.DecodeString(encoded)
-		if err != nil {
-			return nil, fmt.Errorf("decode %s: %w", key, err)
-		}
-		result[key] = decoded
-	}
-
-	return result, nil
+	return kube.GetSecret(ctx, namespace, name)
 }
@@ -3,13 +3,21 @@ package cli
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/config"
+	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/reseal"
+	"github.com/shermanhuman/waxseal/internal/rotation"
 	"github.com/shermanhuman/waxseal/internal/seal"
+	"github.com/shermanhuman/waxseal/internal/state"
 	"github.com/shermanhuman/waxseal/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +27,7 @@ import (
 var (
 	checkWarnDays      int
 	checkFailOnWarning bool
+	checkOutput        string
 )
 
 // ── Parent: waxseal check ──────────────────────────────────────────────────
@@ -37,6 +46,12 @@ By default all checks run. Use subcommands to run individual checks:
   waxseal check gsm         Verify GSM secret versions exist
   waxseal check cluster     Compare metadata vs live cluster keys
 
+Output formats (--output):
+  text   Human-readable (default)
+  json   Findings with severity, secret, key, file and line
+  sarif  SARIF 2.1.0 for GitHub code scanning (errors and warnings)
+  junit  JUnit XML for CI test reports (errors are failures)
+
 Exit codes:
   0 - All checks passed
   1 - Errors found (expired cert, missing secrets, etc.)
@@ -56,6 +71,8 @@ Reports:
   - Days until expiry
   - Warning if expiring soon (default: 30 days)
   - Error if already expired
+  - Warning for each manifest not sealed with the current certificate of its
+    environment (see 'waxseal reseal --stale-only')
 
 Examples:
   waxseal check cert
@@ -74,6 +91,12 @@ var checkExpiryCmd = &cobra.Command{
 Reports:
   - Secrets with expired keys
   - Secrets expiring within the warning threshold
+  - Keys overdue under their rotation.interval (error)
+  - Keys due for rotation within the warning threshold, or whose last
+    rotation is unknown (warning)
+
+The last rotation is the creation time of the pinned store version when
+store credentials are available, otherwise the audit log.
 
 Examples:
   waxseal check expiry
@@ -107,7 +130,9 @@ var checkGSMCmd = &cobra.Command{
 	Short: "Verify GSM secret versions exist",
 	Long: `Verify that GSM secrets referenced in metadata actually exist.
 
-Requires GSM authentication (ADC or service account).
+Checks the configured store backend. Requires GSM authentication (ADC or
+service account), VAULT_TOKEN when store.kind is 'vault', AWS credentials
+when store.kind is 'aws', or the passphrase/identity for store.kind 'file'.
 
 Examples:
   waxseal check gsm`,
@@ -125,7 +150,8 @@ Reports:
   - Keys in metadata but missing from cluster
   - Keys in cluster but missing from metadata
 
-Requires kubectl configured with cluster access.
+Requires a kubeconfig with cluster access; bootstrap.cluster.kubeContext
+selects the context.
 
 Examples:
   waxseal check cluster`,
@@ -143,114 +169,132 @@ func init() {
 	// Shared flags on the parent (inherited by subcommands)
 	checkCmd.PersistentFlags().IntVar(&checkWarnDays, "warn-days", 30, "Days threshold for expiration warnings")
 	checkCmd.PersistentFlags().BoolVar(&checkFailOnWarning, "fail-on-warning", false, "Exit with error code 2 on warnings")
+	checkCmd.PersistentFlags().StringVarP(&checkOutput, "output", "o", checkOutputText, "Output format: text, json, sarif, junit")
+	checkCmd.PersistentPreRunE = validateCheckOutput
 
-	// Preflight: gsm subcommand needs GSM auth, cluster needs kubectl
-	addPreflightChecks(checkGSMCmd, authNeeds{gsm: true})
-	addPreflightChecks(checkClusterCmd, authNeeds{kubectl: true})
+	// Preflight: gsm subcommand needs GSM auth, cluster needs a kubeconfig
+	addPreflightChecks(checkGSMCmd, authNeeds{store: true})
+	addPreflightChecks(checkClusterCmd, authNeeds{cluster: true})
 }
 
 // ── Runners ────────────────────────────────────────────────────────────────
 
 // runCheckAll runs every check, reporting a combined summary.
 func runCheckAll(cmd *cobra.Command, args []string) error {
-	var hasErrors, hasWarnings bool
+	ctx := cmd.Context()
+	reports := []*checkReport{
+		doCheckCert(),
+		doCheckExpiry(ctx),
+		doCheckMetadata(),
+	}
 
-	// 1. Cert
-	certErr, certWarn := doCheckCert()
-	hasErrors = hasErrors || certErr
-	hasWarnings = hasWarnings || certWarn
-
-	// 2. Expiry
-	expErr, expWarn := doCheckExpiry()
-	hasErrors = hasErrors || expErr
-	hasWarnings = hasWarnings || expWarn
-
-	// 3. Metadata
-	metaErr, metaWarn := doCheckMetadata()
-	hasErrors = hasErrors || metaErr
-	hasWarnings = hasWarnings || metaWarn
-
-	// 4. GSM (best-effort — skip if auth not available)
-	if gsmAvailable() {
-		gsmErr, gsmWarn := doCheckGSM(cmd.Context())
-		hasErrors = hasErrors || gsmErr
-		hasWarnings = hasWarnings || gsmWarn
+	// GSM (best-effort — skip if auth not available)
+	if storeAvailable() {
+		reports = append(reports, doCheckGSM(ctx))
 	} else {
-		printDim("Skipping GSM check (no credentials)")
+		reports = append(reports, skippedCheck("gsm", "no credentials"))
 	}
 
-	// 5. Cluster (best-effort — skip if kubectl not available)
-	if kubectlAvailable() {
-		clErr, clWarn := doCheckCluster(cmd.Context())
-		hasErrors = hasErrors || clErr
-		hasWarnings = hasWarnings || clWarn
+	// Cluster (best-effort — skip if no kubeconfig)
+	if clusterAvailable() {
+		reports = append(reports, doCheckCluster(ctx))
 	} else {
-		printDim("Skipping cluster check (kubectl not available)")
+		reports = append(reports, skippedCheck("cluster", "no kubeconfig"))
 	}
 
-	return exitWithSummary(hasErrors, hasWarnings)
+	return finishCheck(reports...)
 }
 
 func runCheckCert(cmd *cobra.Command, args []string) error {
-	hasErrors, hasWarnings := doCheckCert()
-	return exitWithSummary(hasErrors, hasWarnings)
+	return finishCheck(doCheckCert())
 }
 
 func runCheckExpiry(cmd *cobra.Command, args []string) error {
-	hasErrors, hasWarnings := doCheckExpiry()
-	return exitWithSummary(hasErrors, hasWarnings)
+	return finishCheck(doCheckExpiry(cmd.Context()))
 }
 
 func runCheckMetadata(cmd *cobra.Command, args []string) error {
-	hasErrors, hasWarnings := doCheckMetadata()
-	return exitWithSummary(hasErrors, hasWarnings)
+	return finishCheck(doCheckMetadata())
 }
 
 func runCheckGSM(cmd *cobra.Command, args []string) error {
-	hasErrors, hasWarnings := doCheckGSM(cmd.Context())
-	return exitWithSummary(hasErrors, hasWarnings)
+	return finishCheck(doCheckGSM(cmd.Context()))
 }
 
 func runCheckCluster(cmd *cobra.Command, args []string) error {
-	hasErrors, hasWarnings := doCheckCluster(cmd.Context())
-	return exitWithSummary(hasErrors, hasWarnings)
+	return finishCheck(doCheckCluster(cmd.Context()))
 }
 
 // ── Shared logic ───────────────────────────────────────────────────────────
 
-// exitWithSummary prints the check summary and exits with the appropriate code.
+// validateCheckOutput rejects an unknown --output before any check runs.
+func validateCheckOutput(cmd *cobra.Command, args []string) error {
+	switch checkOutput {
+	case checkOutputText, checkOutputJSON, checkOutputSARIF, checkOutputJUnit:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (use: text, json, sarif, junit)", checkOutput)
+	}
+}
+
+// finishCheck writes the reports in the --output format and exits with
+// the appropriate code.
+func finishCheck(reports ...*checkReport) error {
+	if err := writeCheckReports(os.Stdout, checkOutput, reports); err != nil {
+		return fmt.Errorf("write check output: %w", err)
+	}
+
+	var hasErrors, hasWarnings bool
+	for _, r := range reports {
+		hasErrors = hasErrors || r.hasErrors()
+		hasWarnings = hasWarnings || r.hasWarnings()
+	}
+	return exitWithSummary(hasErrors, hasWarnings)
+}
+
+// exitWithSummary prints the check summary and exits with the appropriate
+// code. Structured output gets no summary line.
 func exitWithSummary(hasErrors, hasWarnings bool) error {
+	text := checkOutput == checkOutputText
 	if hasErrors {
-		fmt.Println("\nCheck failed")
+		if text {
+			fmt.Println("Check failed")
+		}
 		os.Exit(1)
 	}
 	if hasWarnings {
-		fmt.Println("\nCheck passed with warnings")
+		if text {
+			fmt.Println("Check passed with warnings")
+		}
 		if checkFailOnWarning {
 			os.Exit(2)
 		}
 		return nil
 	}
-	fmt.Println("\nAll checks passed")
+	if text {
+		fmt.Println("All checks passed")
+	}
 	return nil
 }
 
 // ── Check implementations ──────────────────────────────────────────────────
 
 // doCheckCert validates the sealing certificate.
-func doCheckCert() (hasErrors, hasWarnings bool) {
+func doCheckCert() *checkReport {
+	r := newCheckReport("cert")
 	cfg, err := resolveConfig()
 	if err != nil {
-		printError("Cannot load config: %v", err)
-		return true, false
+		r.errorf("config-invalid", configLocation(), "Cannot load config: %v", err)
+		return r
 	}
 
 	certPath := resolveCertPath(cfg)
+	certAt := location{File: repoRelative(certPath)}
 
 	sealer, err := seal.NewCertSealerFromFile(certPath)
 	if err != nil {
-		printError("Cannot load certificate: %v", err)
-		return true, false
+		r.errorf("cert-unreadable", certAt, "Cannot load certificate: %v", err)
+		return r
 	}
 
 	notBefore := sealer.GetCertNotBefore()
@@ -259,75 +303,239 @@ func doCheckCert() (hasErrors, hasWarnings bool) {
 	subject := sealer.GetSubject()
 	daysUntil := sealer.DaysUntilExpiry()
 
-	fmt.Printf("Certificate: %s\n", cfg.Cert.RepoCertPath)
-	fmt.Printf("Subject:     %s\n", subject)
-	fmt.Printf("Fingerprint: %s...\n", fingerprint[:16])
-	fmt.Printf("Valid from:  %s\n", notBefore.Format("2006-01-02"))
-	fmt.Printf("Valid until: %s\n", notAfter.Format("2006-01-02"))
-	fmt.Println()
-
-	if sealer.IsExpired() {
-		printError("Certificate EXPIRED (%d days ago)", -daysUntil)
-		fmt.Println("\nAction required:")
-		fmt.Println("  1. Rotate the SealedSecrets controller certificate")
-		fmt.Println("  2. Run 'waxseal reseal --all' to re-encrypt all secrets")
-		return true, false
+	r.detail("Certificate: %s", cfg.Cert.RepoCertPath)
+	r.detail("Subject:     %s", subject)
+	r.detail("Fingerprint: %s...", fingerprint[:16])
+	r.detail("Valid from:  %s", notBefore.Format("2006-01-02"))
+	r.detail("Valid until: %s", notAfter.Format("2006-01-02"))
+	r.detail("")
+
+	switch {
+	case sealer.IsExpired():
+		r.add(severityError, "cert-expired", certAt,
+			"Rotate the SealedSecrets controller certificate, then run 'waxseal reseal --all'",
+			"Certificate EXPIRED (%d days ago)", -daysUntil)
+	case sealer.ExpiresWithinDays(checkWarnDays):
+		r.add(severityWarning, "cert-expiring", certAt,
+			"Plan certificate rotation before expiry; after rotation, run 'waxseal reseal --all'",
+			"Certificate expiring in %d days", daysUntil)
+	default:
+		r.passf("cert-valid", certAt, "Certificate valid (%d days remaining)", daysUntil)
 	}
 
-	if sealer.ExpiresWithinDays(checkWarnDays) {
-		printWarning("Certificate expiring in %d days", daysUntil)
-		fmt.Println("\nRecommended actions:")
-		fmt.Println("  1. Plan certificate rotation before expiry")
-		fmt.Println("  2. After rotation, run 'waxseal reseal --all'")
-		return false, true
+	doCheckStaleManifests(cfg, r)
+	return r
+}
+
+// doCheckStaleManifests reports the manifests of each environment that
+// were not sealed with its current certificate.
+func doCheckStaleManifests(cfg *config.Config, r *checkReport) {
+	warnings := r.count(severityWarning)
+
+	history, err := state.LoadCertHistory(repoPath)
+	if err != nil {
+		r.warnf("cert-history", location{}, "Cannot load cert history: %v", err)
+		history = &state.CertHistory{}
 	}
 
-	printSuccess("Certificate valid (%d days remaining)", daysUntil)
-	return false, false
+	for _, env := range append([]string{""}, cfg.EnvNames()...) {
+		envCfg, err := cfg.ForEnv(env)
+		if err != nil {
+			r.warnf("config-invalid", configLocation(), "%s: %v", envLabel(env), err)
+			continue
+		}
+		certPath := resolveCertPath(envCfg)
+		sealer, err := seal.NewCertSealerFromFile(certPath)
+		if err != nil {
+			r.warnf("cert-unreadable", location{File: repoRelative(certPath)}, "%s: cannot load certificate: %v", envLabel(env), err)
+			continue
+		}
+
+		engine := reseal.NewEngine(nil, nil, repoPath, false)
+		engine.SetEnvironment(env)
+		stale, err := engine.FindStale(sealer.GetCertFingerprint())
+		if err != nil {
+			r.warnf("manifest-stale", location{}, "%s: cannot check manifests: %v", envLabel(env), err)
+			continue
+		}
+
+		fix := "Run 'waxseal reseal --stale-only' to reseal them with the current certificate"
+		if env != "" {
+			fix = fmt.Sprintf("Run 'waxseal reseal --stale-only --env %s' to reseal them with the current certificate", env)
+		}
+		for _, s := range stale {
+			name := s.ShortName
+			if env != "" {
+				name = fmt.Sprintf("%s [%s]", s.ShortName, env)
+			}
+			at := location{Secret: s.ShortName, File: repoRelative(s.ManifestPath), Line: 1}
+			switch record := history.Lookup(env, s.SealedBy); {
+			case s.Error != nil:
+				r.add(severityWarning, "manifest-stale", at, fix, "%s: %v", name, s.Error)
+			case s.SealedBy == "":
+				r.add(severityWarning, "manifest-stale", at, fix, "%s: %s records no sealing certificate", name, s.ManifestPath)
+			case record != nil && record.Superseded():
+				r.add(severityWarning, "manifest-stale", at, fix, "%s: %s sealed under superseded certificate %s... (replaced %s)",
+					name, s.ManifestPath, s.SealedBy[:min(16, len(s.SealedBy))], record.SupersededAt)
+			default:
+				r.add(severityWarning, "manifest-stale", at, fix, "%s: %s sealed under unknown certificate %s...",
+					name, s.ManifestPath, s.SealedBy[:min(16, len(s.SealedBy))])
+			}
+		}
+	}
+	if r.count(severityWarning) == warnings {
+		r.passf("manifests-current", location{}, "All manifests sealed with the current certificate")
+	}
 }
 
 // doCheckExpiry validates secret expiration and rotation dates.
-func doCheckExpiry() (hasErrors, hasWarnings bool) {
+func doCheckExpiry(ctx context.Context) *checkReport {
+	r := newCheckReport("expiry")
 	secrets, loadErrs := files.LoadAllMetadataCollectErrors(repoPath)
 	if len(secrets) == 0 && len(loadErrs) > 0 {
-		printError("Cannot load metadata: %v", loadErrs[0])
-		return true, false
+		r.errorf("metadata-invalid", location{}, "Cannot load metadata: %v", loadErrs[0])
+		return r
 	}
 	for _, err := range loadErrs {
-		printError("Metadata load: %v", err)
-		hasErrors = true
+		r.errorf("metadata-invalid", location{}, "Metadata load: %v", err)
 	}
 
-	fmt.Println()
+	// The store, when its credentials are available, provides derived
+	// expiry dates and version creation times
+	var secretStore store.Store
+	if storeAvailable() {
+		if cfg, err := resolveConfig(); err == nil {
+			if st, closeStore, err := resolveStore(ctx, cfg); err == nil {
+				defer closeStore()
+				secretStore = st
+			}
+		}
+	}
+
+	now := time.Now()
+	threshold := now.AddDate(0, 0, checkWarnDays)
 	var checked int
 	for _, m := range secrets {
 		if m.IsRetired() {
 			continue
 		}
 
-		if m.IsExpired() {
-			printError("%s: has expired keys", m.ShortName)
-			hasErrors = true
-		} else if m.ExpiresWithinDays(checkWarnDays) {
-			printWarning("%s: keys expiring within %d days", m.ShortName, checkWarnDays)
-			hasWarnings = true
+		checkDerivedExpiry(ctx, secretStore, m, r)
+
+		for _, k := range m.Keys {
+			if k.Expiry == nil {
+				continue
+			}
+			expiresAt, err := time.Parse(time.RFC3339, k.Expiry.ExpiresAt)
+			if err != nil {
+				continue
+			}
+			at := metadataLocation(m.ShortName, k.KeyName, "expiresAt")
+			switch {
+			case expiresAt.Before(now):
+				r.errorf("key-expired", at, "%s/%s: expired %s", m.ShortName, k.KeyName, expiresAt.Format("2006-01-02"))
+			case expiresAt.Before(threshold):
+				r.warnf("key-expiring", at, "%s/%s: expires %s (within %d days)",
+					m.ShortName, k.KeyName, expiresAt.Format("2006-01-02"), checkWarnDays)
+			}
 		}
 
 		checked++
 	}
 
-	if !hasErrors && !hasWarnings {
-		printSuccess("No expiring secrets (%d checked)", checked)
+	doCheckRotationDue(ctx, secretStore, secrets, r)
+
+	if !r.hasErrors() && !r.hasWarnings() {
+		r.passf("expiry-ok", location{}, "No expiring secrets or overdue rotations (%d checked)", checked)
 	} else {
-		fmt.Printf("Checked %d secrets\n", checked)
+		r.notef("checked", "Checked %d secrets", checked)
+	}
+
+	return r
+}
+
+// checkDerivedExpiry replaces the recorded expiry of keys with
+// expiry.source derived by the one read from their pinned value, so the
+// expiry checks see the real date. It warns when the recorded date is stale
+// or the value cannot be read. secretStore may be nil (no credentials); the
+// recorded dates are used then. Returns true if it warned.
+func checkDerivedExpiry(ctx context.Context, secretStore store.Store, m *core.SecretMetadata, r *checkReport) (warned bool) {
+	for i := range m.Keys {
+		k := &m.Keys[i]
+		if !k.Expiry.IsDerived() || k.GSM == nil {
+			continue
+		}
+		at := metadataLocation(m.ShortName, k.KeyName, "expiry")
+		if secretStore == nil {
+			if k.Expiry.ExpiresAt == "" {
+				r.warnf("expiry-underivable", at, "%s/%s: expiry not derived yet (no store credentials; run reseal)", m.ShortName, k.KeyName)
+				warned = true
+			}
+			continue
+		}
+
+		value, err := secretStore.AccessVersion(ctx, k.GSM.SecretResource, k.GSM.Version)
+		if err != nil {
+			r.warnf("expiry-underivable", at, "%s/%s: cannot read value to derive expiry: %v", m.ShortName, k.KeyName, err)
+			warned = true
+			continue
+		}
+		expiresAt, err := core.DeriveExpiry(value)
+		if err != nil {
+			r.warnf("expiry-underivable", at, "%s/%s: cannot derive expiry: %v", m.ShortName, k.KeyName, err)
+			warned = true
+			continue
+		}
+		derived := expiresAt.UTC().Format(time.RFC3339)
+		if k.Expiry.ExpiresAt != derived {
+			r.add(severityWarning, "expiry-stale", metadataLocation(m.ShortName, k.KeyName, "expiresAt"),
+				"Run 'waxseal reseal' to update it",
+				"%s/%s: recorded expiry %q is stale, value expires %s", m.ShortName, k.KeyName, k.Expiry.ExpiresAt, derived)
+			warned = true
+			k.Expiry.ExpiresAt = derived
+		}
+	}
+	return warned
+}
+
+// doCheckRotationDue reports keys overdue or nearly due under their
+// rotation.interval. Version creation times come from secretStore when it is
+// set, and from the audit log otherwise.
+func doCheckRotationDue(ctx context.Context, secretStore store.Store, secrets []*core.SecretMetadata, r *checkReport) {
+	entries, err := audit.Open(repoPath).Entries()
+	if err != nil {
+		r.warnf("audit-unreadable", location{}, "Cannot read audit log: %v", err)
 	}
 
-	return hasErrors, hasWarnings
+	tracker := rotation.NewTracker(secretStore, entries)
+	now := time.Now()
+	window := time.Duration(checkWarnDays) * 24 * time.Hour
+	for _, m := range secrets {
+		if m.IsRetired() {
+			continue
+		}
+		for _, st := range tracker.Statuses(ctx, m) {
+			at := metadataLocation(st.ShortName, st.KeyName, "interval")
+			hint := fmt.Sprintf("Run 'waxseal rotate %s %s'", st.ShortName, st.KeyName)
+			switch {
+			case !st.Known():
+				r.warnf("rotation-unknown", at, "%s/%s: last rotation unknown: %v", st.ShortName, st.KeyName, st.Err)
+			case st.Overdue(now):
+				r.add(severityError, "rotation-overdue", at, hint, "%s/%s: rotation overdue since %s (last rotated %s)",
+					st.ShortName, st.KeyName, st.DueAt().Format("2006-01-02"), st.LastRotated.Format("2006-01-02"))
+			case st.DueWithin(now, window):
+				r.add(severityWarning, "rotation-due", at, hint, "%s/%s: rotation due %s",
+					st.ShortName, st.KeyName, st.DueAt().Format("2006-01-02"))
+			}
+		}
+	}
 }
 
 // doCheckMetadata validates repo structure and metadata consistency.
 // This absorbs the former standalone `validate` command.
-func doCheckMetadata() (hasErrors, hasWarnings bool) {
+func doCheckMetadata() *checkReport {
+	r := newCheckReport("metadata")
+
 	// Check config exists
 	configFile := configPath
 	if !filepath.IsAbs(configFile) {
@@ -335,73 +543,91 @@ func doCheckMetadata() (hasErrors, hasWarnings bool) {
 	}
 
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "ERROR: config not found: %s\n", configFile)
-		hasErrors = true
+		r.errorf("config-invalid", configLocation(), "config not found: %s", configFile)
 	} else {
 		data, err := os.ReadFile(configFile)
 		if err != nil {
-			printError("Read config: %v", err)
-			hasErrors = true
+			r.errorf("config-invalid", configLocation(), "Read config: %v", err)
 		} else if _, err := parseConfig(data); err != nil {
-			printError("Invalid config: %v", err)
-			hasErrors = true
+			r.errorf("config-invalid", configLocation(), "Invalid config: %v", err)
 		} else {
-			printSuccess("Config valid: %s", configFile)
+			r.passf("config-valid", configLocation(), "Config valid: %s", configFile)
 		}
 	}
 
 	// Check metadata
 	secrets, loadErrs := files.LoadAllMetadataCollectErrors(repoPath)
 	if len(secrets) == 0 && len(loadErrs) > 0 {
-		fmt.Fprintf(os.Stderr, "ERROR: %v\n", loadErrs[0])
-		return true, hasWarnings
+		r.errorf("metadata-invalid", location{}, "%v", loadErrs[0])
+		return r
 	}
 	for _, err := range loadErrs {
-		printError("%v", err)
-		hasErrors = true
+		r.errorf("metadata-invalid", location{}, "%v", err)
+	}
+
+	// Store references are validated against the configured backend's naming rules
+	storeKind := ""
+	var envNames []string
+	if cfg, err := resolveConfig(); err == nil {
+		storeKind = cfg.Store.Kind
+		envNames = cfg.EnvNames()
 	}
 
 	var secretCount int
 	for _, m := range secrets {
-		// Check manifest exists
-		manifestPath := m.ManifestPath
-		if !filepath.IsAbs(manifestPath) {
-			manifestPath = filepath.Join(repoPath, manifestPath)
+		if storeKind != "" {
+			if err := m.ValidateStoreRefs(storeKind); err != nil {
+				r.errorf("store-ref-invalid", metadataLocation(m.ShortName, "", ""), "%s: %v", m.ShortName, err)
+			}
 		}
-		if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
-			printError("Manifest not found: %s (referenced by %s)", m.ManifestPath, m.ShortName)
-			hasErrors = true
+
+		// Targeted environments must be configured
+		for _, env := range m.Environments {
+			if !slices.Contains(envNames, env) {
+				r.errorf("environment-undefined", metadataLocation(m.ShortName, "", "environments"),
+					"%s: environment %q is not defined in config", m.ShortName, env)
+			}
+		}
+
+		// Check manifest exists (once per targeted environment)
+		for _, env := range m.TargetEnvs() {
+			relPath := m.ManifestPathFor(env)
+			manifestPath := relPath
+			if !filepath.IsAbs(manifestPath) {
+				manifestPath = filepath.Join(repoPath, manifestPath)
+			}
+			if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+				r.errorf("manifest-missing", metadataLocation(m.ShortName, "", "manifestPath"),
+					"Manifest not found: %s (referenced by %s)", relPath, m.ShortName)
+			}
 		}
 
 		// Validate GSM versions are numeric
 		for _, k := range m.Keys {
 			if k.GSM != nil {
 				if err := validateNumericVersion(k.GSM.Version); err != nil {
-					printError("%s/%s: %v", m.ShortName, k.KeyName, err)
-					hasErrors = true
+					r.errorf("version-not-pinned", metadataLocation(m.ShortName, k.KeyName, "version"),
+						"%s/%s: %v", m.ShortName, k.KeyName, err)
 				}
 			}
 
 			// Hygiene: operatorHints must be GSM-backed
 			if k.OperatorHints != nil {
 				if k.OperatorHints.GSM == nil {
-					printError("%s/%s: operatorHints must have gsm reference (inline hints not allowed)",
-						m.ShortName, k.KeyName)
-					hasErrors = true
+					r.errorf("operator-hints-inline", metadataLocation(m.ShortName, k.KeyName, "operatorHints"),
+						"%s/%s: operatorHints must have gsm reference (inline hints not allowed)", m.ShortName, k.KeyName)
 				} else if k.OperatorHints.Format != "json" {
-					printWarning("%s/%s: operatorHints.format should be 'json' (got %q)",
-						m.ShortName, k.KeyName, k.OperatorHints.Format)
-					hasWarnings = true
+					r.warnf("operator-hints-format", metadataLocation(m.ShortName, k.KeyName, "operatorHints"),
+						"%s/%s: operatorHints.format should be 'json' (got %q)", m.ShortName, k.KeyName, k.OperatorHints.Format)
 				}
 			}
 
 			// Hygiene: internal hostnames in computed.params
 			if k.Computed != nil && len(k.Computed.Params) > 0 {
-				for paramName, paramValue := range k.Computed.Params {
-					if containsInternalHostname(paramValue) {
-						printWarning("%s/%s: computed.params[%s] contains internal hostname pattern",
-							m.ShortName, k.KeyName, paramName)
-						hasWarnings = true
+				for _, paramName := range slices.Sorted(maps.Keys(k.Computed.Params)) {
+					if containsInternalHostname(k.Computed.Params[paramName]) {
+						r.warnf("internal-hostname", metadataLocation(m.ShortName, k.KeyName, paramName),
+							"%s/%s: computed.params[%s] contains internal hostname pattern", m.ShortName, k.KeyName, paramName)
 					}
 				}
 			}
@@ -410,30 +636,36 @@ func doCheckMetadata() (hasErrors, hasWarnings bool) {
 		secretCount++
 	}
 
-	printSuccess("Validated %d secrets", secretCount)
-	return hasErrors, hasWarnings
+	r.passf("metadata-valid", location{}, "Validated %d secrets", secretCount)
+	return r
 }
 
 // doCheckGSM verifies that GSM secrets referenced in metadata actually exist.
-func doCheckGSM(ctx context.Context) (hasErrors, hasWarnings bool) {
+func doCheckGSM(ctx context.Context) *checkReport {
+	r := newCheckReport("gsm")
 	cfg, err := resolveConfig()
 	if err != nil {
-		printError("Cannot load config: %v", err)
-		return true, false
+		r.errorf("config-invalid", configLocation(), "Cannot load config: %v", err)
+		return r
 	}
 
-	gsmStore, err := store.NewGSMStore(ctx, cfg.Store.ProjectID)
+	secretStore, closeStore, err := resolveStore(ctx, cfg)
 	if err != nil {
-		printError("Cannot create GSM store: %v", err)
-		return true, false
+		r.errorf("store-unavailable", configLocation(), "Cannot create secret store: %v", err)
+		return r
+	}
+	defer closeStore()
+
+	gsmStore, ok := secretStore.(store.VersionChecker)
+	if !ok {
+		r.errorf("store-unavailable", configLocation(), "Store kind %q does not support version checks", cfg.Store.Kind)
+		return r
 	}
-	defer gsmStore.Close()
 
 	secrets, _ := files.LoadAllMetadataCollectErrors(repoPath)
 
-	fmt.Println()
-	fmt.Println("Checking GSM secrets...")
-	fmt.Println()
+	r.detail("Checking GSM secrets...")
+	r.detail("")
 
 	for _, m := range secrets {
 		if m.IsRetired() {
@@ -443,94 +675,114 @@ func doCheckGSM(ctx context.Context) (hasErrors, hasWarnings bool) {
 		for _, km := range m.Keys {
 			if km.GSM != nil {
 				exists, _, err := gsmStore.SecretVersionExists(ctx, km.GSM.SecretResource, km.GSM.Version)
+				at := metadataLocation(m.ShortName, km.KeyName, "version")
 				if err != nil {
-					printWarning("%s/%s: cannot check GSM: %v", m.ShortName, km.KeyName, err)
-					hasWarnings = true
+					r.warnf("store-unreachable", at, "%s/%s: cannot check GSM: %v", m.ShortName, km.KeyName, err)
 				} else if !exists {
-					printError("%s/%s: GSM secret not found: %s (v%s)",
+					r.errorf("store-version-missing", at, "%s/%s: GSM secret not found: %s (v%s)",
 						m.ShortName, km.KeyName, km.GSM.SecretResource, km.GSM.Version)
-					hasErrors = true
 				}
 			}
 			if km.Computed != nil && km.Computed.GSM != nil {
 				exists, _, err := gsmStore.SecretVersionExists(ctx, km.Computed.GSM.SecretResource, km.Computed.GSM.Version)
+				at := metadataLocation(m.ShortName, km.KeyName, "computed")
 				if err != nil {
-					printWarning("%s/%s: cannot check computed GSM: %v", m.ShortName, km.KeyName, err)
-					hasWarnings = true
+					r.warnf("store-unreachable", at, "%s/%s: cannot check computed GSM: %v", m.ShortName, km.KeyName, err)
 				} else if !exists {
-					printError("%s/%s: computed GSM secret not found: %s (v%s)",
+					r.errorf("store-version-missing", at, "%s/%s: computed GSM secret not found: %s (v%s)",
 						m.ShortName, km.KeyName, km.Computed.GSM.SecretResource, km.Computed.GSM.Version)
-					hasErrors = true
 				}
 			}
 		}
 	}
 
-	return hasErrors, hasWarnings
+	return r
 }
 
 // doCheckCluster compares metadata keys against live Kubernetes cluster.
-func doCheckCluster(ctx context.Context) (hasErrors, hasWarnings bool) {
+func doCheckCluster(ctx context.Context) *checkReport {
+	r := newCheckReport("cluster")
 	secrets, _ := files.LoadAllMetadataCollectErrors(repoPath)
 
-	fmt.Println()
-	fmt.Println("Checking cluster state...")
-	fmt.Println()
+	r.detail("Checking cluster state...")
+	r.detail("")
+
+	cfg, err := resolveConfig()
+	if err != nil {
+		r.errorf("config-invalid", configLocation(), "Cannot load config: %v", err)
+		return r
+	}
+	kube, err := resolveCluster(cfg)
+	if err != nil {
+		r.errorf("cluster-unavailable", location{}, "Cannot connect to cluster: %v", err)
+		return r
+	}
 
 	for _, m := range secrets {
 		if m.IsRetired() {
 			continue
 		}
 
-		clusterData, err := readSecretFromCluster(ctx, m.SealedSecret.Namespace, m.SealedSecret.Name)
+		clusterData, err := kube.GetSecret(ctx, m.SealedSecret.Namespace, m.SealedSecret.Name)
 		if err != nil {
-			printWarning("%s: cannot read from cluster: %v", m.ShortName, err)
-			hasWarnings = true
+			r.warnf("cluster-unreadable", metadataLocation(m.ShortName, "", "sealedSecret"),
+				"%s: cannot read from cluster: %v", m.ShortName, err)
 			continue
 		}
 
-		clusterKeys := make(map[string]bool)
-		for k := range clusterData {
-			clusterKeys[k] = true
-		}
-
-		var missingInCluster []string
-		var extraInCluster []string
-
-		for _, km := range m.Keys {
-			if !clusterKeys[km.KeyName] {
-				missingInCluster = append(missingInCluster, km.KeyName)
-			}
-		}
-
 		metadataKeys := make(map[string]bool)
+		var missing int
 		for _, km := range m.Keys {
 			metadataKeys[km.KeyName] = true
+			if _, ok := clusterData[km.KeyName]; !ok {
+				r.errorf("cluster-key-missing", metadataLocation(m.ShortName, km.KeyName, ""),
+					"%s/%s: key in metadata but NOT in cluster", m.ShortName, km.KeyName)
+				missing++
+			}
 		}
+
+		var extraInCluster []string
 		for k := range clusterData {
 			if !metadataKeys[k] {
 				extraInCluster = append(extraInCluster, k)
 			}
 		}
+		slices.Sort(extraInCluster)
 
-		if len(missingInCluster) > 0 {
-			printError("%s: keys in metadata but NOT in cluster: %v", m.ShortName, missingInCluster)
-			hasErrors = true
-		}
 		if len(extraInCluster) > 0 {
-			printWarning("%s: keys in cluster but NOT in metadata: %v", m.ShortName, extraInCluster)
-			hasWarnings = true
+			r.warnf("cluster-key-extra", metadataLocation(m.ShortName, "", "keys"),
+				"%s: keys in cluster but NOT in metadata: %v", m.ShortName, extraInCluster)
 		}
-		if len(missingInCluster) == 0 && len(extraInCluster) == 0 {
-			fmt.Printf("  %s✓%s %s: cluster matches metadata (%d keys)\n", styleGreen, styleReset, m.ShortName, len(clusterData))
+		if missing == 0 && len(extraInCluster) == 0 {
+			r.passf("cluster-match", location{Secret: m.ShortName}, "%s: cluster matches metadata (%d keys)", m.ShortName, len(clusterData))
 		}
 	}
 
-	return hasErrors, hasWarnings
+	return r
 }
 
 // ── Helpers ────────────────────────────────────────────────────────────────
 
+// storeAvailable returns true if credentials for the configured secret
+// store are likely available.
+func storeAvailable() bool {
+	cfg, err := resolveConfig()
+	if err != nil {
+		return gsmAvailable()
+	}
+	switch cfg.Store.Kind {
+	case "vault":
+		return os.Getenv("VAULT_TOKEN") != ""
+	case "aws":
+		_, err := store.LoadAWSCredentials()
+		return err == nil
+	case "file":
+		return len(cfg.Store.File.Recipients) > 0 || os.Getenv("WAXSEAL_STORE_PASSPHRASE") != ""
+	default:
+		return gsmAvailable()
+	}
+}
+
 // gsmAvailable returns true if GSM credentials are likely available.
 func gsmAvailable() bool {
 	// Check for ADC or GOOGLE_APPLICATION_CREDENTIALS
@@ -547,10 +799,10 @@ func gsmAvailable() bool {
 	return err == nil
 }
 
-// kubectlAvailable returns true if kubectl is available in PATH.
-func kubectlAvailable() bool {
-	_, err := exec.LookPath("kubectl")
-	return err == nil
+// clusterAvailable returns true if a kubeconfig can be loaded for the
+// configured context.
+func clusterAvailable() bool {
+	return preflightCluster() == nil
 }
 
 // validateNumericVersion checks that a GSM version string is numeric.
@@ -0,0 +1,497 @@
+package cli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/shermanhuman/waxseal/internal/files"
+)
+
+// Check output formats (--output).
+const (
+	checkOutputText  = "text"
+	checkOutputJSON  = "json"
+	checkOutputSARIF = "sarif"
+	checkOutputJUnit = "junit"
+)
+
+// Finding severities. Errors fail a check; warnings fail it only with
+// --fail-on-warning. Notes and passes are informational.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityNote    = "note"
+	severityPass    = "pass"
+)
+
+// checkRules describes each finding rule, for the SARIF rule index.
+var checkRules = map[string]string{
+	"config-invalid":        "The waxseal config is missing or invalid",
+	"cert-unreadable":       "The sealing certificate cannot be loaded",
+	"cert-expired":          "The sealing certificate has expired",
+	"cert-expiring":         "The sealing certificate expires soon",
+	"cert-valid":            "The sealing certificate is valid",
+	"cert-history":          "The certificate history cannot be read",
+	"manifest-stale":        "A manifest is not sealed with its environment's current certificate",
+	"manifests-current":     "All manifests are sealed with the current certificate",
+	"metadata-invalid":      "A metadata file cannot be read or parsed",
+	"expiry-underivable":    "An expiry date cannot be derived from the key's value",
+	"expiry-stale":          "The recorded expiry differs from the one derived from the value",
+	"key-expired":           "A key has expired",
+	"key-expiring":          "A key expires soon",
+	"audit-unreadable":      "The audit log cannot be read",
+	"rotation-unknown":      "A key's last rotation is unknown",
+	"rotation-overdue":      "A key is overdue for rotation under its rotation.interval",
+	"rotation-due":          "A key is due for rotation soon",
+	"expiry-ok":             "No keys are expiring or overdue for rotation",
+	"checked":               "Number of secrets checked",
+	"config-valid":          "The waxseal config is valid",
+	"store-ref-invalid":     "A store reference does not match the store backend's naming rules",
+	"environment-undefined": "A secret targets an environment the config does not define",
+	"manifest-missing":      "A manifest referenced by metadata does not exist",
+	"version-not-pinned":    "A store version is not a pinned numeric version",
+	"operator-hints-inline": "Operator hints are inline instead of stored",
+	"operator-hints-format": "Operator hints are not JSON",
+	"internal-hostname":     "A computed parameter contains an internal hostname",
+	"metadata-valid":        "Metadata files are valid",
+	"store-unavailable":     "The secret store cannot be used",
+	"store-unreachable":     "A store version cannot be checked",
+	"store-version-missing": "A pinned store version does not exist",
+	"cluster-unavailable":   "The cluster cannot be reached",
+	"cluster-unreadable":    "A secret cannot be read from the cluster",
+	"cluster-key-missing":   "A metadata key is missing from the cluster secret",
+	"cluster-key-extra":     "The cluster secret has keys metadata does not list",
+	"cluster-match":         "The cluster secret matches metadata",
+}
+
+// finding is one result of a check. File is relative to the repo root.
+type finding struct {
+	Check    string `json:"check"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Secret   string `json:"secret,omitempty"`
+	Key      string `json:"key,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"` // remediation
+}
+
+// location is where a finding points.
+type location struct {
+	Secret, Key, File string
+	Line              int
+}
+
+// checkReport collects the findings of one check.
+type checkReport struct {
+	Name     string    `json:"name"`
+	Skipped  string    `json:"skipped,omitempty"` // why the check did not run
+	Findings []finding `json:"findings"`
+	details  []string  // text output only: context printed before the findings
+}
+
+func newCheckReport(name string) *checkReport {
+	return &checkReport{Name: name, Findings: []finding{}}
+}
+
+// skippedCheck reports a check that did not run.
+func skippedCheck(name, reason string) *checkReport {
+	r := newCheckReport(name)
+	r.Skipped = reason
+	return r
+}
+
+// add records a finding. hint, if not empty, is a remediation.
+func (r *checkReport) add(severity, rule string, at location, hint, format string, a ...any) {
+	r.Findings = append(r.Findings, finding{
+		Check:    r.Name,
+		Rule:     rule,
+		Severity: severity,
+		Secret:   at.Secret,
+		Key:      at.Key,
+		File:     at.File,
+		Line:     at.Line,
+		Message:  fmt.Sprintf(format, a...),
+		Hint:     hint,
+	})
+}
+
+func (r *checkReport) errorf(rule string, at location, format string, a ...any) {
+	r.add(severityError, rule, at, "", format, a...)
+}
+
+func (r *checkReport) warnf(rule string, at location, format string, a ...any) {
+	r.add(severityWarning, rule, at, "", format, a...)
+}
+
+func (r *checkReport) passf(rule string, at location, format string, a ...any) {
+	r.add(severityPass, rule, at, "", format, a...)
+}
+
+func (r *checkReport) notef(rule string, format string, a ...any) {
+	r.add(severityNote, rule, location{}, "", format, a...)
+}
+
+func (r *checkReport) detail(format string, a ...any) {
+	r.details = append(r.details, fmt.Sprintf(format, a...))
+}
+
+func (r *checkReport) count(severity string) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *checkReport) hasErrors() bool   { return r.count(severityError) > 0 }
+func (r *checkReport) hasWarnings() bool { return r.count(severityWarning) > 0 }
+
+// ── Locations ──────────────────────────────────────────────────────────────
+
+// configLocation points at the config file.
+func configLocation() location {
+	return location{File: repoRelative(configPath), Line: 1}
+}
+
+// metadataLocation points at a secret's metadata file: at field of key
+// keyName, at the key if field is empty or absent, or at the top-level
+// field if keyName is empty.
+func metadataLocation(shortName, keyName, field string) location {
+	at := location{Secret: shortName, Key: keyName, File: repoRelative(files.MetadataPath(repoPath, shortName)), Line: 1}
+	data, err := os.ReadFile(files.MetadataPath(repoPath, shortName))
+	if err != nil {
+		return at
+	}
+	if line := yamlLine(string(data), keyName, field); line > 0 {
+		at.Line = line
+	}
+	return at
+}
+
+// repoRelative returns path relative to the repo root, with forward
+// slashes, as SARIF and CI annotations expect.
+func repoRelative(path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(repoPath, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// yamlLine returns the 1-based line of field within the keys entry with
+// keyName, of the entry itself, or of a top-level field when keyName is
+// empty. It returns 0 if nothing matches. Metadata is small and
+// hand-written, so a line scan is enough.
+func yamlLine(data, keyName, field string) int {
+	lines := strings.Split(data, "\n")
+	fieldRe := func(indent string) *regexp.Regexp {
+		return regexp.MustCompile(`^` + indent + `-?\s*"?` + regexp.QuoteMeta(field) + `"?\s*:`)
+	}
+
+	if keyName == "" {
+		if field == "" {
+			return 0
+		}
+		re := fieldRe("")
+		for i, line := range lines {
+			if re.MatchString(line) {
+				return i + 1
+			}
+		}
+		return 0
+	}
+
+	keyRe := regexp.MustCompile(`^[\s-]*keyName:\s*["']?` + regexp.QuoteMeta(keyName) + `["']?\s*(#.*)?$`)
+	anyKeyRe := regexp.MustCompile(`^[\s-]*keyName:`)
+	start := slices.IndexFunc(lines, keyRe.MatchString)
+	if start < 0 {
+		return 0
+	}
+	if field != "" {
+		re := fieldRe(`\s*`)
+		for i := start + 1; i < len(lines) && !anyKeyRe.MatchString(lines[i]); i++ {
+			if re.MatchString(lines[i]) {
+				return i + 1
+			}
+		}
+	}
+	return start + 1
+}
+
+// ── Rendering ──────────────────────────────────────────────────────────────
+
+// writeCheckReports renders reports in format.
+func writeCheckReports(w io.Writer, format string, reports []*checkReport) error {
+	switch format {
+	case checkOutputJSON:
+		return writeCheckJSON(w, reports)
+	case checkOutputSARIF:
+		return writeCheckSARIF(w, reports)
+	case checkOutputJUnit:
+		return writeCheckJUnit(w, reports)
+	default:
+		writeCheckText(reports)
+		return nil
+	}
+}
+
+// writeCheckText prints reports for a terminal: errors and warnings to
+// stderr, everything else to stdout.
+func writeCheckText(reports []*checkReport) {
+	for _, r := range reports {
+		if r.Skipped != "" {
+			printDim("Skipping %s check (%s)", r.Name, r.Skipped)
+			continue
+		}
+		for _, d := range r.details {
+			fmt.Println(d)
+		}
+		lastHint := ""
+		for _, f := range r.Findings {
+			switch f.Severity {
+			case severityError:
+				printError("%s", f.Message)
+			case severityWarning:
+				printWarning("%s", f.Message)
+			case severityPass:
+				printSuccess("%s", f.Message)
+			default:
+				fmt.Println(f.Message)
+			}
+			if f.Hint != "" && f.Hint != lastHint {
+				fmt.Printf("  %s\n", f.Hint)
+			}
+			lastHint = f.Hint
+		}
+		fmt.Println()
+	}
+}
+
+func writeCheckJSON(w io.Writer, reports []*checkReport) error {
+	var errors, warnings int
+	for _, r := range reports {
+		errors += r.count(severityError)
+		warnings += r.count(severityWarning)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Version  string         `json:"version"`
+		Errors   int            `json:"errors"`
+		Warnings int            `json:"warnings"`
+		Checks   []*checkReport `json:"checks"`
+	}{Version, errors, warnings, reports})
+}
+
+// SARIF 2.1.0, the subset GitHub code scanning reads.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	} `json:"driver"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI       string `json:"uri"`
+			URIBaseID string `json:"uriBaseId"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+// writeCheckSARIF writes the errors and warnings as SARIF results. Code
+// scanning needs a location for each, so findings without a file point at
+// the config file.
+func writeCheckSARIF(w io.Writer, reports []*checkReport) error {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver.Name = "waxseal"
+	run.Tool.Driver.Version = Version
+	run.Tool.Driver.InformationURI = "https://github.com/shermanhuman/waxseal"
+	run.Tool.Driver.Rules = []sarifRule{}
+
+	ruleIDs := make(map[string]bool)
+	for _, r := range reports {
+		for _, f := range r.Findings {
+			if f.Severity != severityError && f.Severity != severityWarning {
+				continue
+			}
+			at := location{File: f.File, Line: f.Line}
+			if at.File == "" {
+				at = configLocation()
+			}
+			var loc sarifLocation
+			loc.PhysicalLocation.ArtifactLocation.URI = at.File
+			loc.PhysicalLocation.ArtifactLocation.URIBaseID = "%SRCROOT%"
+			loc.PhysicalLocation.Region.StartLine = max(at.Line, 1)
+
+			message := f.Message
+			if f.Hint != "" {
+				message += ". " + f.Hint
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    f.Rule,
+				Level:     f.Severity,
+				Message:   sarifMessage{Text: message},
+				Locations: []sarifLocation{loc},
+			})
+			ruleIDs[f.Rule] = true
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(ruleIDs)) {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               id,
+			ShortDescription: sarifMessage{Text: checkRules[id]},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// JUnit XML, as read by CI test report dashboards.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// writeCheckJUnit writes a test suite per check. Errors are failures;
+// warnings are passing cases with the warning as output, so they do not
+// fail a CI run unless --fail-on-warning sets the exit code. A check with
+// no findings is one passing case.
+func writeCheckJUnit(w io.Writer, reports []*checkReport) error {
+	doc := junitTestSuites{Name: "waxseal check"}
+	for _, r := range reports {
+		suite := junitTestSuite{Name: r.Name}
+		className := "waxseal.check." + r.Name
+		switch {
+		case r.Skipped != "":
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      r.Name,
+				ClassName: className,
+				Skipped:   &junitMessage{Message: r.Skipped},
+			})
+			suite.Skipped++
+		default:
+			for _, f := range r.Findings {
+				if f.Severity == severityNote {
+					continue
+				}
+				name := f.Rule
+				if subject := strings.Trim(f.Secret+"/"+f.Key, "/"); subject != "" {
+					name += " " + subject
+				}
+				tc := junitTestCase{Name: name, ClassName: className, File: f.File, Line: f.Line}
+				switch f.Severity {
+				case severityError:
+					text := f.Message
+					if f.Hint != "" {
+						text += "\n" + f.Hint
+					}
+					tc.Failure = &junitMessage{Message: f.Message, Type: f.Rule, Text: text}
+					suite.Failures++
+				case severityWarning:
+					tc.SystemOut = "WARNING: " + f.Message
+				}
+				suite.Cases = append(suite.Cases, tc)
+			}
+			if len(suite.Cases) == 0 {
+				suite.Cases = append(suite.Cases, junitTestCase{Name: r.Name, ClassName: className})
+			}
+		}
+		suite.Tests = len(suite.Cases)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Skipped += suite.Skipped
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
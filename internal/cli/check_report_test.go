@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestYAMLLine(t *testing.T) {
+	data := `shortName: app
+manifestPath: apps/app/sealed.yaml
+keys:
+  - keyName: username
+    gsm:
+      version: "1"
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/p/secrets/pw
+      version: "3"
+`
+	tests := []struct {
+		keyName, field string
+		want           int
+	}{
+		{"", "manifestPath", 2},
+		{"", "missing", 0},
+		{"password", "", 7},
+		{"password", "version", 12},
+		{"username", "version", 6},
+		{"password", "expiry", 7}, // absent field: the key's line
+		{"other", "version", 0},
+	}
+	for _, tt := range tests {
+		if got := yamlLine(data, tt.keyName, tt.field); got != tt.want {
+			t.Errorf("yamlLine(%q, %q) = %d, want %d", tt.keyName, tt.field, got, tt.want)
+		}
+	}
+}
+
+func sampleReports() []*checkReport {
+	expiry := newCheckReport("expiry")
+	expiry.errorf("key-expired", location{Secret: "app", Key: "api_key", File: ".waxseal/metadata/app.yaml", Line: 14}, "app/api_key: expired 2026-01-01")
+	expiry.add(severityWarning, "rotation-due", location{Secret: "db", Key: "password", File: ".waxseal/metadata/db.yaml", Line: 20},
+		"Run 'waxseal rotate db password'", "db/password: rotation due 2026-02-01")
+	expiry.notef("checked", "Checked 2 secrets")
+
+	cluster := newCheckReport("cluster")
+	cluster.errorf("cluster-unavailable", location{}, "Cannot connect to cluster: no route")
+
+	return []*checkReport{
+		expiry,
+		cluster,
+		skippedCheck("gsm", "no credentials"),
+	}
+}
+
+func TestWriteCheckJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCheckReports(&buf, checkOutputJSON, sampleReports()); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Errors, Warnings int
+		Checks           []struct {
+			Name     string
+			Skipped  string
+			Findings []finding
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Errors != 2 || doc.Warnings != 1 || len(doc.Checks) != 3 {
+		t.Fatalf("errors=%d warnings=%d checks=%d", doc.Errors, doc.Warnings, len(doc.Checks))
+	}
+	f := doc.Checks[0].Findings[0]
+	if f.Check != "expiry" || f.Severity != severityError || f.Key != "api_key" || f.File != ".waxseal/metadata/app.yaml" || f.Line != 14 {
+		t.Errorf("finding = %+v", f)
+	}
+	if doc.Checks[2].Skipped != "no credentials" {
+		t.Errorf("gsm skipped = %q", doc.Checks[2].Skipped)
+	}
+}
+
+func TestWriteCheckSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCheckReports(&buf, checkOutputSARIF, sampleReports()); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version=%q runs=%d", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	// Notes are left out; each result has a location
+	if len(run.Results) != 3 {
+		t.Fatalf("results = %d, want 3", len(run.Results))
+	}
+	loc := run.Results[1].Locations[0].PhysicalLocation
+	if run.Results[1].Level != "warning" || loc.ArtifactLocation.URI != ".waxseal/metadata/db.yaml" || loc.Region.StartLine != 20 {
+		t.Errorf("result = %+v", run.Results[1])
+	}
+	if !strings.Contains(run.Results[1].Message.Text, "waxseal rotate db password") {
+		t.Errorf("message = %q, want the hint", run.Results[1].Message.Text)
+	}
+	if uri := run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != ".waxseal/config.yaml" {
+		t.Errorf("location-less finding points at %q, want the config file", uri)
+	}
+
+	var ids []string
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.ShortDescription.Text == "" {
+			t.Errorf("rule %s has no description", rule.ID)
+		}
+		ids = append(ids, rule.ID)
+	}
+	if strings.Join(ids, ",") != "cluster-unavailable,key-expired,rotation-due" {
+		t.Errorf("rules = %v", ids)
+	}
+}
+
+func TestWriteCheckJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCheckReports(&buf, checkOutputJUnit, sampleReports()); err != nil {
+		t.Fatal(err)
+	}
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 4 || doc.Failures != 2 || doc.Skipped != 1 {
+		t.Errorf("tests=%d failures=%d skipped=%d", doc.Tests, doc.Failures, doc.Skipped)
+	}
+	expiry := doc.Suites[0]
+	if expiry.Cases[0].Name != "key-expired app/api_key" || expiry.Cases[0].Failure == nil || expiry.Cases[0].Line != 14 {
+		t.Errorf("expired case = %+v", expiry.Cases[0])
+	}
+	if expiry.Cases[1].Failure != nil || !strings.Contains(expiry.Cases[1].SystemOut, "WARNING") {
+		t.Errorf("warning case = %+v, want a passing case with output", expiry.Cases[1])
+	}
+}
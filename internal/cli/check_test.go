@@ -1,9 +1,16 @@
 package cli
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/cluster"
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/store"
 )
 
 // TestValidateCommand tests the validate command logic.
@@ -231,3 +238,175 @@ func setupValidateTest(t *testing.T, tmpDir, name, metadata, manifest string) {
 	os.WriteFile(filepath.Join(metadataDir, name+".yaml"), []byte(metadata), 0o644)
 	os.WriteFile(filepath.Join(manifestDir, "sealed.yaml"), []byte(manifest), 0o644)
 }
+
+func TestDoCheckCluster(t *testing.T) {
+	tmpDir := t.TempDir()
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = tmpDir
+
+	writeClusterTestConfig(t, tmpDir)
+	metadata := `shortName: app
+manifestPath: apps/test/sealed.yaml
+sealedSecret:
+  name: app
+  namespace: prod
+  scope: strict
+status: active
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/p/secrets/password
+      version: "1"
+`
+	setupValidateTest(t, tmpDir, "app", metadata, "")
+
+	kube := cluster.NewFake()
+	contexts := useFakeCluster(t, kube)
+
+	kube.AddSecret("prod", "app", map[string][]byte{"password": []byte("x")})
+	if r := doCheckCluster(context.Background()); r.hasErrors() || r.hasWarnings() {
+		t.Errorf("matching cluster: findings=%+v", r.Findings)
+	}
+	if len(*contexts) != 1 || (*contexts)[0] != "prod-admin" {
+		t.Errorf("kube contexts = %v, want [prod-admin]", *contexts)
+	}
+
+	kube.AddSecret("prod", "app", map[string][]byte{"token": []byte("x")})
+	r := doCheckCluster(context.Background())
+	if !r.hasErrors() || !r.hasWarnings() {
+		t.Errorf("drifted cluster: findings=%+v, want errors and warnings", r.Findings)
+	}
+	for _, f := range r.Findings {
+		if f.Rule == "cluster-key-missing" && (f.Key != "password" || f.File != ".waxseal/metadata/app.yaml" || f.Line != 9) {
+			t.Errorf("missing key finding = %+v, want app.yaml line 9", f)
+		}
+	}
+}
+
+// writeClusterTestConfig writes a config that selects the "prod-admin" kube
+// context.
+func writeClusterTestConfig(t *testing.T, dir string) {
+	t.Helper()
+	cfg := `version: "1"
+store:
+  kind: gsm
+  projectId: p
+bootstrap:
+  cluster:
+    kubeContext: prod-admin
+`
+	if err := os.MkdirAll(filepath.Join(dir, ".waxseal"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".waxseal", "config.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDoCheckExpiry_RotationDue(t *testing.T) {
+	tmpDir := t.TempDir()
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = tmpDir
+
+	// No store credentials: last rotations come from the audit log
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("HOME", tmpDir)
+	writeClusterTestConfig(t, tmpDir)
+	metadata := `shortName: app
+manifestPath: apps/test/sealed.yaml
+sealedSecret:
+  name: app
+  namespace: prod
+  scope: strict
+status: active
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/p/secrets/app-password
+      version: "2"
+    rotation:
+      mode: generated
+      generator:
+        kind: randomBase64
+        bytes: 32
+      interval: 90d
+`
+	setupValidateTest(t, tmpDir, "app", metadata, "")
+
+	logEntry := func(daysAgo int) {
+		t.Helper()
+		_, err := audit.Open(tmpDir).Append(audit.Entry{
+			Time:       time.Now().AddDate(0, 0, -daysAgo).UTC().Format(time.RFC3339),
+			Command:    "rotate",
+			ShortName:  "app",
+			KeyName:    "password",
+			NewVersion: "2",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Unknown last rotation is a warning
+	if r := doCheckExpiry(context.Background()); r.hasErrors() || !r.hasWarnings() {
+		t.Errorf("unknown rotation: findings=%+v, want warning only", r.Findings)
+	}
+
+	logEntry(100)
+	r := doCheckExpiry(context.Background())
+	if !r.hasErrors() {
+		t.Error("key rotated 100 days ago with a 90d interval should be an error")
+	}
+	for _, f := range r.Findings {
+		if f.Rule == "rotation-overdue" && f.Line != 20 {
+			t.Errorf("overdue finding at line %d, want the interval line 20", f.Line)
+		}
+	}
+
+	logEntry(10)
+	if r := doCheckExpiry(context.Background()); r.hasErrors() || r.hasWarnings() {
+		t.Errorf("recently rotated key: findings=%+v", r.Findings)
+	}
+}
+
+func TestCheckDerivedExpiry(t *testing.T) {
+	ctx := context.Background()
+	cert, err := core.Generate(&core.GeneratorConfig{Kind: "tls", CommonName: "webhook", Validity: "5d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := store.NewFakeStore()
+	fake.SetVersion("projects/p/secrets/crt", "1", cert.Outputs["cert"])
+
+	m := &core.SecretMetadata{
+		ShortName: "webhook",
+		Keys: []core.KeyMetadata{{
+			KeyName: "tls.crt",
+			Source:  core.SourceConfig{Kind: "gsm"},
+			GSM:     &core.GSMRef{SecretResource: "projects/p/secrets/crt", Version: "1"},
+			Expiry:  &core.ExpiryConfig{Source: "derived", ExpiresAt: "2099-01-01T00:00:00Z"},
+		}},
+	}
+
+	// Without store credentials the recorded date is used
+	if checkDerivedExpiry(ctx, nil, m, newCheckReport("expiry")) {
+		t.Error("recorded date without store: no warning expected")
+	}
+
+	// With the store, a stale recorded date is replaced and reported
+	if !checkDerivedExpiry(ctx, fake, m, newCheckReport("expiry")) {
+		t.Error("stale recorded date should warn")
+	}
+	if !m.ExpiresWithinDays(7) {
+		t.Errorf("expiry should come from the certificate, got %s", m.Keys[0].Expiry.ExpiresAt)
+	}
+	if checkDerivedExpiry(ctx, fake, m, newCheckReport("expiry")) {
+		t.Error("up-to-date derived date should not warn")
+	}
+}
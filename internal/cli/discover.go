@@ -1,16 +1,16 @@
 package cli
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 
+	"github.com/shermanhuman/waxseal/internal/cluster"
+	"github.com/shermanhuman/waxseal/internal/kustomize"
 	"github.com/shermanhuman/waxseal/internal/seal"
 	"github.com/shermanhuman/waxseal/internal/store"
 	tmpl "github.com/shermanhuman/waxseal/internal/template"
@@ -37,7 +37,7 @@ func init() {
 	discoverCmd.Flags().BoolVar(&discoverNonInteractive, "non-interactive", false, "Create stubs without prompts (for CI)")
 }
 
-func runDiscover(cmd *cobra.Command, args []string) error {
+func runDiscover(cmd *cobra.Command, args []string) (err error) {
 	// Ensure .waxseal directory exists
 	waxsealDir := filepath.Join(repoPath, ".waxseal")
 	metadataDir := filepath.Join(waxsealDir, "metadata")
@@ -48,13 +48,20 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 
 	// Load config to get project ID (optional — may not exist yet)
 	var projectID string
-	cfg, err := resolveConfig()
-	if err == nil && cfg.Store.ProjectID != "" {
+	cfg, cfgErr := resolveConfig()
+	if cfgErr == nil && cfg.Store.ProjectID != "" {
 		projectID = cfg.Store.ProjectID
 	}
 
+	// Index kustomizations once; every manifest is resolved against it
+	kustomizations, err := kustomize.LoadIndex(repoPath)
+	if err != nil {
+		return fmt.Errorf("read kustomizations: %w", err)
+	}
+
 	// Walk repo and find SealedSecrets
 	var found []discoveredSecret
+	var unresolved int
 	err = filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -80,22 +87,60 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 			return nil // Skip files we can't read
 		}
 
-		ss, err := seal.ParseSealedSecret(data)
+		relPath, _ := filepath.Rel(repoPath, path)
+
+		// Helm renders chart templates at install time, so their identity
+		// is not in the repo
+		if chart := helmChartFor(path); chart != "" {
+			if strings.Contains(string(data), "kind: SealedSecret") {
+				chartRel, _ := filepath.Rel(repoPath, chart)
+				printWarning("%s: SealedSecret in Helm chart %s; its identity is set at install time. Register the rendered secret with 'waxseal addkey'", relPath, chartRel)
+			}
+			return nil
+		}
+
+		// A file may bundle several documents (e.g. a SealedSecret with its
+		// Deployment); pick out every SealedSecret
+		located, err := seal.FindSealedSecrets(data)
+		if err != nil || len(located) == 0 {
+			return nil // Not valid SealedSecret YAML
+		}
+
+		// Record the identity the controller sees, after the kustomizations
+		// that build the manifest (enclosing or overlay) set the namespace or
+		// add a name prefix/suffix
+		transform, err := kustomizations.ForFile(path)
 		if err != nil {
-			return nil // Not a SealedSecret
+			printError("%s: %v", relPath, err)
+			unresolved++
+			return nil
 		}
 
-		relPath, _ := filepath.Rel(repoPath, path)
-		found = append(found, discoveredSecret{
-			path:         relPath,
-			sealedSecret: ss,
-		})
+		for _, l := range located {
+			meta := &l.SealedSecret.Metadata
+			meta.Namespace, meta.Name = transform.Apply(meta.Namespace, meta.Name)
+			if meta.Namespace == "" {
+				printWarning("%s: SealedSecret %s has no namespace in the manifest or a kustomization; skipped", relPath, meta.Name)
+				continue
+			}
+			found = append(found, discoveredSecret{
+				path:         relPath,
+				sealedSecret: l.SealedSecret,
+			})
+		}
 
 		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("walk repo: %w", err)
 	}
+	if unresolved > 0 {
+		defer func() {
+			if err == nil {
+				err = fmt.Errorf("%d manifest(s) skipped: their identity could not be resolved", unresolved)
+			}
+		}()
+	}
 
 	if len(found) == 0 {
 		fmt.Println("No SealedSecret manifests found.")
@@ -159,6 +204,13 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 	fmt.Println("  • Track expiration dates and send reminders")
 	fmt.Println()
 
+	// The wizard reads live values to suggest templates; without cluster
+	// access it still runs, just without the suggestions
+	var kube cluster.Client
+	if !discoverNonInteractive {
+		kube, _ = resolveCluster(cfg)
+	}
+
 	// Process each new secret
 	for i, ds := range newSecrets {
 		shortName := shortNames[i]
@@ -171,7 +223,7 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 			stub = generateMetadataStub(ds, shortName, projectID, nil)
 		} else {
 			// Interactive mode
-			keyConfigs, err := runInteractiveWizard(ds, shortName, projectID)
+			keyConfigs, err := runInteractiveWizard(cmd.Context(), kube, ds, shortName, projectID)
 			if err != nil {
 				return err
 			}
@@ -242,43 +294,32 @@ func deriveShortName(namespace, name string) string {
 }
 
 // fetchSecretFromCluster retrieves a secret's data from the Kubernetes cluster
-func fetchSecretFromCluster(namespace, name string) (map[string]string, error) {
-	cmd := exec.Command("kubectl", "get", "secret", name,
-		"-n", namespace, "-o", "json")
-	output, err := cmd.Output()
+func fetchSecretFromCluster(ctx context.Context, kube cluster.Client, namespace, name string) (map[string]string, error) {
+	if kube == nil {
+		return nil, fmt.Errorf("no cluster access")
+	}
+	data, err := kube.GetSecret(ctx, namespace, name)
 	if err != nil {
 		return nil, err
 	}
 
-	var secret struct {
-		Data map[string]string `json:"data"`
-	}
-	if err := json.Unmarshal(output, &secret); err != nil {
-		return nil, err
-	}
-
-	// Decode base64 values
-	result := make(map[string]string)
-	for k, v := range secret.Data {
-		decoded, err := base64.StdEncoding.DecodeString(v)
-		if err != nil {
-			continue
-		}
-		result[k] = string(decoded)
+	result := make(map[string]string, len(data))
+	for k, v := range data {
+		result[k] = string(v)
 	}
 	return result, nil
 }
 
 // detectConnectionStringTemplate analyzes a value and suggests a template if it looks like a connection string.
 // Returns: isTemplate, template string, extracted values map
-func runInteractiveWizard(ds discoveredSecret, shortName, projectID string) ([]keyConfig, error) {
+func runInteractiveWizard(ctx context.Context, kube cluster.Client, ds discoveredSecret, shortName, projectID string) ([]keyConfig, error) {
 	keys := ds.sealedSecret.GetEncryptedKeys()
 	configs := make([]keyConfig, 0, len(keys))
 
 	// Try to fetch actual secret values from cluster for template detection
 	namespace := ds.sealedSecret.Metadata.Namespace
 	name := ds.sealedSecret.Metadata.Name
-	secretData, fetchErr := fetchSecretFromCluster(namespace, name)
+	secretData, fetchErr := fetchSecretFromCluster(ctx, kube, namespace, name)
 	if fetchErr != nil {
 		// Not a fatal error - just won't have auto-detection
 		secretData = nil
@@ -578,3 +619,19 @@ keys:
 #       - keyName: password
 `, shortName, ds.path, ss.Metadata.Name, ss.Metadata.Namespace, ss.GetScope(), ss.GetSecretType(), keys.String())
 }
+
+// helmChartFor returns the chart directory if path is a template of a Helm
+// chart (<chart>/templates/... next to a Chart.yaml), or "".
+func helmChartFor(path string) string {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == "templates" {
+			chart := filepath.Dir(dir)
+			if _, err := os.Stat(filepath.Join(chart, "Chart.yaml")); err == nil {
+				return chart
+			}
+		}
+		if dir == repoPath || dir == filepath.Dir(dir) {
+			return ""
+		}
+	}
+}
@@ -76,10 +76,10 @@ func init() {
 	addMetadataCheck(editAddkeyCmd)
 	addMetadataCheck(editUpdatekeyCmd)
 	addMetadataCheck(editRetirekeyCmd)
-	addPreflightChecks(editCmd, authNeeds{gsm: true})
-	addPreflightChecks(editAddkeyCmd, authNeeds{gsm: true})
-	addPreflightChecks(editUpdatekeyCmd, authNeeds{gsm: true})
-	addPreflightChecks(editRetirekeyCmd, authNeeds{gsm: true})
+	addPreflightChecks(editCmd, authNeeds{store: true})
+	addPreflightChecks(editAddkeyCmd, authNeeds{store: true})
+	addPreflightChecks(editUpdatekeyCmd, authNeeds{store: true})
+	addPreflightChecks(editRetirekeyCmd, authNeeds{store: true})
 }
 
 // ── Shared helpers ─────────────────────────────────────────────────────────
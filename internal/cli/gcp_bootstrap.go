@@ -434,7 +434,8 @@ func CheckKubesealInstalled() error {
 	_, err := exec.LookPath("kubeseal")
 	if err != nil {
 		printWarning("'kubeseal' CLI not found in PATH.")
-		fmt.Println("  WaxSeal requires 'kubeseal' to encrypt secrets for Kubernetes.")
+		fmt.Println("  WaxSeal encrypts secrets natively, but uses 'kubeseal' to fetch the")
+		fmt.Println("  controller certificate (and to seal, if cert.sealer is 'kubeseal').")
 		fmt.Println("  Install it from: https://github.com/bitnami-labs/sealed-secrets/releases")
 		fmt.Println()
 	}
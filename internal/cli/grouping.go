@@ -13,6 +13,8 @@ func init() {
 	// Operations
 	resealCmd.GroupID = groupOps
 	checkCmd.GroupID = groupOps
+	verifyCmd.GroupID = groupOps
+	auditCmd.GroupID = groupOps
 
 	// Metadata
 	metaCmd.GroupID = groupMeta
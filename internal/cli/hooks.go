@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/config"
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/hooks"
+	"github.com/shermanhuman/waxseal/internal/state"
+)
+
+// rotationHooks returns the hooks that apply to a secret: the config-level
+// hooks, then the secret's own.
+func rotationHooks(cfg *config.Config, metadata *core.SecretMetadata) []*core.HooksConfig {
+	var sets []*core.HooksConfig
+	for _, h := range []*core.HooksConfig{cfg.Hooks, metadata.Hooks} {
+		if h != nil {
+			sets = append(sets, h)
+		}
+	}
+	return sets
+}
+
+func postRotateHookCount(sets []*core.HooksConfig) int {
+	n := 0
+	for _, h := range sets {
+		n += len(h.PostRotate)
+	}
+	return n
+}
+
+// runPreRotateHooks runs the pre-rotate hooks. A failure aborts the
+// rotation before anything is generated or stored.
+func runPreRotateHooks(ctx context.Context, sets []*core.HooksConfig, shortName string, keys []string) error {
+	event := hooks.Event{Phase: core.HookPhasePreRotate, ShortName: shortName, Keys: keys}
+	for _, set := range sets {
+		if err := runHooks(ctx, set.PreRotate, event); err != nil {
+			return fmt.Errorf("%w; nothing was rotated", err)
+		}
+	}
+	return nil
+}
+
+// runPostRotateHooks runs the post-rotate hooks for the rotated keys and
+// their new versions. On a failure it reports whether the failing hook's
+// set asks for a rollback.
+func runPostRotateHooks(ctx context.Context, sets []*core.HooksConfig, shortName string, rotated map[string]string) (rollback bool, err error) {
+	if len(rotated) == 0 {
+		return false, nil
+	}
+	event := hooks.Event{
+		Phase:     core.HookPhasePostRotate,
+		ShortName: shortName,
+		Keys:      slices.Sorted(maps.Keys(rotated)),
+		Versions:  rotated,
+	}
+	for _, set := range sets {
+		if err := runHooks(ctx, set.PostRotate, event); err != nil {
+			return set.RollbackOnFailure, err
+		}
+	}
+	return false, nil
+}
+
+// runHooks runs hooks in order, prints and records each result in state,
+// and returns an error for the first failure.
+func runHooks(ctx context.Context, list []core.HookConfig, event hooks.Event) error {
+	if len(list) == 0 {
+		return nil
+	}
+	if dryRun {
+		for _, h := range list {
+			fmt.Printf("[DRY RUN] Would run %s hook: %s\n", event.Phase, h.Label())
+		}
+		return nil
+	}
+
+	fmt.Printf("\nRunning %s hooks...\n", event.Phase)
+	runner := &hooks.Runner{Dir: repoPath, Output: os.Stdout}
+	results := runner.RunAll(ctx, list, event)
+
+	if err := withState(func(s *state.State) {
+		for _, r := range results {
+			s.AddHookRun(event.ShortName, r.Phase, r.Hook, r.Duration, r.Err)
+		}
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to update state: %v\n", err)
+	}
+
+	for _, r := range results {
+		if !r.OK() {
+			printError("%s hook %q: %v", r.Phase, r.Hook, r.Err)
+			return fmt.Errorf("%s hook %q failed: %w", r.Phase, r.Hook, r.Err)
+		}
+		printSuccess("%s hook %q", r.Phase, r.Hook)
+	}
+	return nil
+}
+
+// rollbackRotation undoes a rotation's metadata version bump after a
+// post-rotate hook failed: it restores the metadata file, reseals with the
+// previous versions and records the change. The new versions stay in the
+// store, unreferenced.
+func rollbackRotation(ctx context.Context, cfg *config.Config, shortName, metadataPath string, original []byte, previous, rotated map[string]string, cause error) error {
+	fmt.Println("\nRolling back to the previous versions...")
+	if err := os.WriteFile(metadataPath, original, 0o644); err != nil {
+		return fmt.Errorf("restore metadata: %w", err)
+	}
+	for _, keyName := range slices.Sorted(maps.Keys(rotated)) {
+		recordAudit(audit.Entry{
+			Command:    "rotate",
+			ShortName:  shortName,
+			KeyName:    keyName,
+			OldVersion: rotated[keyName],
+			NewVersion: previous[keyName],
+			Reason:     "rollback: " + cause.Error(),
+		})
+	}
+
+	metadata, err := files.LoadMetadata(repoPath, shortName)
+	if err != nil {
+		return err
+	}
+	_, resealed, failed := resealEnvs(ctx, cfg, metadata)
+	if len(resealed) > 0 {
+		if err := withState(func(s *state.State) {
+			s.AddRotation(shortName, "", "rollback", "")
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to update state: %v\n", err)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("reseal failed for %d of %d environments", len(failed), len(metadata.TargetEnvs()))
+	}
+	return nil
+}
+
+// storeVersions maps each store-backed key to its pinned version.
+func storeVersions(metadata *core.SecretMetadata) map[string]string {
+	versions := make(map[string]string)
+	for _, k := range metadata.Keys {
+		switch {
+		case k.GSM != nil:
+			versions[k.KeyName] = k.GSM.Version
+		case k.Computed != nil && k.Computed.GSM != nil:
+			versions[k.KeyName] = k.Computed.GSM.Version
+		}
+	}
+	return versions
+}
+
+// changedVersions returns the keys whose version differs from before, with
+// their new versions.
+func changedVersions(before, after map[string]string) map[string]string {
+	changed := make(map[string]string)
+	for keyName, version := range after {
+		if before[keyName] != version {
+			changed[keyName] = version
+		}
+	}
+	return changed
+}
+
+func keyNames(keys []core.KeyMetadata) []string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.KeyName
+	}
+	return names
+}
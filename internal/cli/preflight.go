@@ -3,17 +3,22 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 
+	"github.com/shermanhuman/waxseal/internal/config"
 	"github.com/shermanhuman/waxseal/internal/gcp"
+	"github.com/shermanhuman/waxseal/internal/store"
 	"github.com/spf13/cobra"
 )
 
 // authNeeds describes what external auth a command requires.
 type authNeeds struct {
-	gsm      bool // Google Secret Manager (requires gcloud + valid ADC)
-	kubeseal bool // kubeseal binary on PATH
-	kubectl  bool // kubectl binary on PATH
+	store     bool // configured secret store backend (gsm → gcloud + ADC, vault → token)
+	gsm       bool // Google Secret Manager (requires gcloud + valid ADC)
+	sealer    bool // kubeseal binary on PATH, if cert.sealer is "kubeseal"
+	cluster   bool // loadable kubeconfig for the configured context
+	reminders bool // Google APIs (gcloud + ADC), if the reminders provider uses them
 }
 
 // addPreflightChecks decorates a command's PreRunE to verify auth prerequisites
@@ -24,24 +29,32 @@ func addPreflightChecks(cmd *cobra.Command, needs authNeeds) {
 	cmd.PreRunE = func(c *cobra.Command, args []string) error {
 		ctx := c.Context()
 
+		if needs.store {
+			if err := preflightStore(ctx); err != nil {
+				return err
+			}
+		}
+
 		if needs.gsm {
 			if err := preflightGSM(ctx); err != nil {
 				return err
 			}
 		}
 
-		if needs.kubeseal {
-			if err := preflightBinary("kubeseal",
-				"WaxSeal requires 'kubeseal' to encrypt secrets for Kubernetes.",
-				"https://github.com/bitnami-labs/sealed-secrets/releases"); err != nil {
+		if needs.reminders {
+			if err := preflightReminders(ctx); err != nil {
 				return err
 			}
 		}
 
-		if needs.kubectl {
-			if err := preflightBinary("kubectl",
-				"This operation requires 'kubectl' to talk to the cluster.",
-				"https://kubernetes.io/docs/tasks/tools/"); err != nil {
+		if needs.sealer {
+			if err := preflightSealer(); err != nil {
+				return err
+			}
+		}
+
+		if needs.cluster {
+			if err := preflightCluster(); err != nil {
 				return err
 			}
 		}
@@ -53,6 +66,80 @@ func addPreflightChecks(cmd *cobra.Command, needs authNeeds) {
 	}
 }
 
+// preflightStore checks the prerequisites of whichever secret store backend
+// the repo config selects. If the config can't be loaded, GSM is assumed so
+// the command itself reports the config error.
+func preflightStore(ctx context.Context) error {
+	cfg, err := resolveConfig()
+	if err != nil || cfg.Store.Kind == "gsm" {
+		return preflightGSM(ctx)
+	}
+
+	switch cfg.Store.Kind {
+	case "vault":
+		if os.Getenv("VAULT_TOKEN") == "" {
+			return fmt.Errorf("VAULT_TOKEN is not set\n\n  The Vault store backend authenticates with a Vault token.\n  Run 'vault login' and export VAULT_TOKEN, then retry")
+		}
+		if cfg.Store.Vault == nil || cfg.Store.Vault.Address == "" {
+			return fmt.Errorf("vault address not configured — set store.vault.address or VAULT_ADDR")
+		}
+	case "aws":
+		if _, err := store.LoadAWSCredentials(); err != nil {
+			return fmt.Errorf("AWS credentials not found: %v\n\n  The AWS store backend signs requests with static credentials from the\n  environment or the shared credentials file (AWS_PROFILE selects the profile).\n  For SSO or role credentials, export them first, e.g. via\n  'eval \"$(aws configure export-credentials --format env)\"'", err)
+		}
+		if cfg.Store.AWS == nil || cfg.Store.AWS.Region == "" {
+			return fmt.Errorf("AWS region not configured — set store.aws.region or AWS_REGION")
+		}
+	case "file":
+		if len(cfg.Store.File.Recipients) == 0 && os.Getenv("WAXSEAL_STORE_PASSPHRASE") == "" {
+			return fmt.Errorf("WAXSEAL_STORE_PASSPHRASE is not set\n\n  The file store backend is passphrase-encrypted (no store.file.recipients).\n  Export WAXSEAL_STORE_PASSPHRASE, then retry")
+		}
+		if len(cfg.Store.File.Recipients) > 0 && cfg.Store.File.IdentityFile == "" {
+			printWarning("No age identity configured (store.file.identityFile or WAXSEAL_AGE_IDENTITY); the store can't be read.")
+		}
+	}
+	return nil
+}
+
+// preflightReminders checks Google access for the Google reminder
+// providers. The webhook, ics and issues providers have no external
+// prerequisites.
+func preflightReminders(ctx context.Context) error {
+	cfg, err := resolveConfig()
+	if err == nil && cfg.Reminders != nil {
+		switch cfg.Reminders.Provider {
+		case "webhook", "ics", "issues", "none":
+			return nil
+		}
+	}
+	return preflightGSM(ctx)
+}
+
+// preflightSealer checks for the kubeseal binary when the config opts into
+// it. The native sealer has no external dependencies.
+func preflightSealer() error {
+	cfg, err := resolveConfig()
+	if err != nil || cfg.Cert.Sealer != config.SealerKubeseal {
+		return nil
+	}
+	return preflightBinary("kubeseal",
+		"cert.sealer is 'kubeseal', which requires 'kubeseal' to encrypt secrets for Kubernetes.",
+		"https://github.com/bitnami-labs/sealed-secrets/releases")
+}
+
+// preflightCluster checks that a kubeconfig can be loaded for the context
+// the config selects. It does not contact the API server.
+func preflightCluster() error {
+	cfg, err := resolveConfig()
+	if err != nil {
+		cfg = nil
+	}
+	if _, err := resolveCluster(cfg); err != nil {
+		return fmt.Errorf("no usable kubeconfig: %v\n\n  This operation reads from the cluster through your kubeconfig.\n  Check $KUBECONFIG (or ~/.kube/config) and bootstrap.cluster.kubeContext", err)
+	}
+	return nil
+}
+
 // preflightGSM ensures the user can reach Google Secret Manager:
 //  1. gcloud CLI installed
 //  2. gcloud account active (offers login if not)
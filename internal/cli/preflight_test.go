@@ -46,7 +46,7 @@ func TestAddPreflightChecks_ChainsWithExistingPreRunE(t *testing.T) {
 
 func TestAuthNeeds_ZeroValueMeansNoChecks(t *testing.T) {
 	needs := authNeeds{}
-	if needs.gsm || needs.kubeseal || needs.kubectl {
+	if needs.store || needs.gsm || needs.sealer || needs.cluster {
 		t.Error("zero-value authNeeds should require nothing")
 	}
 }
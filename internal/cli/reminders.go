@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,26 +11,39 @@ import (
 	"time"
 
 	"github.com/charmbracelet/huh"
+	"github.com/shermanhuman/waxseal/internal/config"
 	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
 	"github.com/shermanhuman/waxseal/internal/reminder"
+	"github.com/shermanhuman/waxseal/internal/state"
 	"github.com/spf13/cobra"
 )
 
 var remindersCmd = &cobra.Command{
 	Use:   "reminders",
-	Short: "Manage expiry reminders in Google Calendar",
+	Short: "Manage expiry reminders in Google Calendar, Tasks, a webhook or an issue tracker",
 	Long: `Manage calendar reminders for secret expiration dates.
 
 Subcommands:
   list    List secrets with upcoming expirations
   sync    Create/update calendar events for expiring secrets
   clear   Remove all calendar events for a secret
+  export  Write an iCalendar (.ics) file of expiry dates
   setup   Configure reminder settings
 
 Requires:
   - reminders.enabled: true in config (except for list and setup)
-  - Google Calendar API access via Application Default Credentials`,
+  - Google Calendar API access via Application Default Credentials, for
+    the tasks and calendar providers
+
+The webhook provider posts a JSON notice (Slack, Teams, Mattermost style)
+when a key crosses a leadTimeDays threshold. Run sync on a schedule; each
+notice is sent once and recorded in .waxseal/state.yaml.
+
+The issues provider opens one GitHub, GitLab or Jira issue per key once it
+crosses its first leadTimeDays threshold, retitles it as later thresholds
+pass, and closes it on the first sync after a rotation moves the key's
+expiresAt.`,
 }
 
 var remindersListCmd = &cobra.Command{
@@ -47,7 +61,12 @@ var remindersSyncCmd = &cobra.Command{
 	Long: `Create or update calendar events for secrets with expiry dates.
 
 Creates reminder events at configured lead times (e.g., 30, 7, 1 days before expiry).
-Events are updated if they already exist (idempotent).`,
+Events are updated if they already exist (idempotent).
+
+With --prune, sync is a full reconciliation: waxseal-owned calendar events
+and tasks that current metadata no longer backs are deleted. That covers
+keys whose expiresAt moved, deleted keys and retired secrets. Combine
+with --dry-run to preview what would be removed.`,
 	RunE: runRemindersSync,
 }
 
@@ -59,6 +78,25 @@ var remindersClearCmd = &cobra.Command{
 	RunE:  runRemindersClear,
 }
 
+var remindersExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export expiry reminders as an iCalendar (.ics) file",
+	Long: `Write an RFC 5545 iCalendar file with one event per key with an expiry
+date, on that date, and an alarm at each of reminders.leadTimeDays.
+
+Outlook, Apple Calendar and Thunderbird can subscribe to the file; no
+Google APIs or credentials are needed. Event UIDs are stable per key, so
+re-exporting updates events instead of duplicating them.
+
+Examples:
+  # Print the calendar
+  waxseal reminders export
+
+  # Write it into the repo
+  waxseal reminders export --output docs/secret-expiry.ics`,
+	RunE: runRemindersExport,
+}
+
 var remindersSetupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Configure reminder settings",
@@ -72,19 +110,26 @@ This will guide you through:
 	RunE: runRemindersSetup,
 }
 
-var remindersListDays int
+var (
+	remindersListDays     int
+	remindersExportOutput string
+	remindersSyncPrune    bool
+)
 
 func init() {
 	rootCmd.AddCommand(remindersCmd)
 	remindersCmd.AddCommand(remindersListCmd)
 	remindersCmd.AddCommand(remindersSyncCmd)
 	remindersCmd.AddCommand(remindersClearCmd)
+	remindersCmd.AddCommand(remindersExportCmd)
 	remindersCmd.AddCommand(remindersSetupCmd)
 
 	remindersListCmd.Flags().IntVar(&remindersListDays, "days", 90, "Show expirations within this many days")
+	remindersSyncCmd.Flags().BoolVar(&remindersSyncPrune, "prune", false, "Delete waxseal reminders that metadata no longer backs (preview with --dry-run)")
+	remindersExportCmd.Flags().StringVarP(&remindersExportOutput, "output", "o", reminder.ICSStdout, "File to write, or - for stdout")
 
-	addPreflightChecks(remindersSyncCmd, authNeeds{gsm: true})
-	addPreflightChecks(remindersClearCmd, authNeeds{gsm: true})
+	addPreflightChecks(remindersSyncCmd, authNeeds{reminders: true})
+	addPreflightChecks(remindersClearCmd, authNeeds{reminders: true})
 }
 
 func runRemindersSync(cmd *cobra.Command, args []string) error {
@@ -103,7 +148,7 @@ func runRemindersSync(cmd *cobra.Command, args []string) error {
 		fmt.Print(`
 reminders:
   enabled: true
-  provider: tasks  # tasks (default), calendar, both, none
+  provider: tasks  # tasks (default), calendar, both, webhook, ics, issues, none
   # tasklistId: "@default"  # Optional, defaults to user's primary task list
   # calendarId: primary     # Only needed if provider is calendar or both
   leadTimeDays: [30, 7, 1]
@@ -132,7 +177,9 @@ reminders:
 		}
 	}
 
-	if len(expiringSecrets) == 0 {
+	// Without expiring secrets there is nothing to sync, but pruning, and
+	// the issues provider, may still close out keys that no longer expire
+	if len(expiringSecrets) == 0 && !remindersSyncPrune && cfg.Reminders.Provider != "issues" {
 		fmt.Println("No secrets with expiry dates found.")
 		return nil
 	}
@@ -148,50 +195,40 @@ reminders:
 			}
 		}
 		fmt.Println("\n[DRY RUN] Would sync reminders to calendar")
-		return nil
-	}
-
-	// Create providers based on config
-	var providers []reminder.Provider
-
-	switch cfg.Reminders.Provider {
-	case "tasks", "": // Tasks is default
-		p, err := reminder.NewGoogleTasksProvider(ctx, cfg.Reminders.TasklistID, cfg.Reminders.LeadTimeDays)
-		if err != nil {
-			return fmt.Errorf("create tasks provider: %w", err)
+		if !remindersSyncPrune {
+			return nil
 		}
-		fmt.Println("Using Google Tasks (tasks appear in Calendar)")
-		providers = append(providers, p)
+	}
 
-	case "calendar":
-		p, err := reminder.NewGoogleCalendarProvider(ctx, cfg.Reminders.CalendarID, cfg.Reminders.LeadTimeDays)
-		if err != nil {
-			return fmt.Errorf("create calendar provider: %w", err)
-		}
-		fmt.Println("Using Google Calendar events")
-		providers = append(providers, p)
+	// Webhook notices already sent are tracked in state
+	st, err := state.Load(repoPath)
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
 
-	case "both":
-		tp, err := reminder.NewGoogleTasksProvider(ctx, cfg.Reminders.TasklistID, cfg.Reminders.LeadTimeDays)
-		if err != nil {
-			return fmt.Errorf("create tasks provider: %w", err)
-		}
-		cp, err := reminder.NewGoogleCalendarProvider(ctx, cfg.Reminders.CalendarID, cfg.Reminders.LeadTimeDays)
-		if err != nil {
-			return fmt.Errorf("create calendar provider: %w", err)
-		}
-		fmt.Println("Using both Google Tasks and Calendar events")
-		providers = append(providers, tp, cp)
+	providers, err := reminderProviders(ctx, cfg, st)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Using %s\n", reminderProviderLabels[cfg.Reminders.Provider])
 
-	default:
-		return fmt.Errorf("unknown provider: %s (use: tasks, calendar, both, none)", cfg.Reminders.Provider)
+	if dryRun {
+		_, errs := pruneReminders(ctx, providers, expiringSecrets)
+		printReminderErrors(errs)
+		return nil
 	}
 
 	// Sync to all providers
-	var totalCreated, totalUpdated, totalSkipped int
+	var totalCreated, totalUpdated, totalSkipped, totalClosed, totalPruned int
 	var allErrors []error
 
 	for _, provider := range providers {
+		if remindersSyncPrune {
+			pruned, errs := pruneReminders(ctx, []reminder.Provider{provider}, expiringSecrets)
+			totalPruned += pruned
+			allErrors = append(allErrors, errs...)
+		}
+
 		result, err := provider.SyncReminders(ctx, expiringSecrets)
 		if err != nil {
 			allErrors = append(allErrors, fmt.Errorf("sync reminders: %w", err))
@@ -200,20 +237,76 @@ reminders:
 		totalCreated += result.Created
 		totalUpdated += result.Updated
 		totalSkipped += result.Skipped
+		totalClosed += result.Closed
 		allErrors = append(allErrors, result.Errors...)
 	}
 
-	printSuccess("Created: %d, Updated: %d, Skipped: %d",
-		totalCreated, totalUpdated, totalSkipped)
+	if err := st.Save(repoPath); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to update state: %v\n", err)
+	}
+
+	switch {
+	case remindersSyncPrune:
+		printSuccess("Created: %d, Updated: %d, Skipped: %d, Pruned: %d",
+			totalCreated, totalUpdated, totalSkipped, totalPruned)
+	case cfg.Reminders.Provider == "issues":
+		printSuccess("Opened: %d, Updated: %d, Skipped: %d, Closed: %d",
+			totalCreated, totalUpdated, totalSkipped, totalClosed)
+	default:
+		printSuccess("Created: %d, Updated: %d, Skipped: %d",
+			totalCreated, totalUpdated, totalSkipped)
+	}
+
+	printReminderErrors(allErrors)
 
-	if len(allErrors) > 0 {
+	return nil
+}
+
+// pruneReminders deletes, or with --dry-run lists, the reminders each
+// provider owns that metadata no longer backs. Providers that cannot list
+// their reminders are skipped: webhook notices cannot be recalled, and the
+// ics file is rewritten in full on every sync.
+func pruneReminders(ctx context.Context, providers []reminder.Provider, secrets []*core.SecretMetadata) (pruned int, errs []error) {
+	for _, provider := range providers {
+		r, ok := provider.(reminder.Reconciler)
+		if !ok {
+			continue
+		}
+		orphans, err := reminder.Orphans(ctx, r, secrets)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list reminders: %w", err))
+			continue
+		}
+		if len(orphans) == 0 {
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("\n[DRY RUN] Would remove %d stale reminders:\n", len(orphans))
+		} else {
+			fmt.Printf("\nRemoving %d stale reminders:\n", len(orphans))
+		}
+		for _, o := range orphans {
+			fmt.Printf("  - %s (%s)\n", o.Title, o.Due)
+		}
+		if dryRun {
+			continue
+		}
+
+		failed := reminder.Prune(ctx, r, orphans)
+		pruned += len(orphans) - len(failed)
+		errs = append(errs, failed...)
+	}
+	return pruned, errs
+}
+
+func printReminderErrors(errs []error) {
+	if len(errs) > 0 {
 		fmt.Fprintf(os.Stderr, "\nErrors:\n")
-		for _, e := range allErrors {
+		for _, e := range errs {
 			fmt.Fprintf(os.Stderr, "  - %v\n", e)
 		}
 	}
-
-	return nil
 }
 
 func runRemindersClear(cmd *cobra.Command, args []string) error {
@@ -235,50 +328,178 @@ func runRemindersClear(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Create providers based on config
+	if cfg.Reminders.Provider == "none" {
+		fmt.Println("Reminders provider is set to 'none'. Nothing to clear.")
+		return nil
+	}
+
+	providers, err := reminderProviders(ctx, cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, provider := range providers {
+		if err := provider.DeleteReminders(ctx, shortName); err != nil {
+			return fmt.Errorf("delete reminders: %w", err)
+		}
+	}
+
+	printSuccess("Cleared reminders for %s", shortName)
+	return nil
+}
+
+// reminderProviderLabels describes each reminders.provider for output.
+var reminderProviderLabels = map[string]string{
+	"":         "Google Tasks (tasks appear in Calendar)",
+	"tasks":    "Google Tasks (tasks appear in Calendar)",
+	"calendar": "Google Calendar events",
+	"both":     "both Google Tasks and Calendar events",
+	"webhook":  "webhook notices",
+	"ics":      "an iCalendar file",
+	"issues":   "issue tracker issues",
+}
+
+// reminderProviders creates the providers the config selects. notices
+// tracks the webhook notices already sent.
+func reminderProviders(ctx context.Context, cfg *config.Config, notices reminder.NoticeLog) ([]reminder.Provider, error) {
+	r := cfg.Reminders
 	var providers []reminder.Provider
 
-	switch cfg.Reminders.Provider {
-	case "tasks", "": // Tasks is default
-		p, err := reminder.NewGoogleTasksProvider(ctx, cfg.Reminders.TasklistID, cfg.Reminders.LeadTimeDays)
+	if r.Provider == "tasks" || r.Provider == "both" || r.Provider == "" { // Tasks is default
+		p, err := reminder.NewGoogleTasksProvider(ctx, r.TasklistID, r.LeadTimeDays)
 		if err != nil {
-			return fmt.Errorf("create tasks provider: %w", err)
+			return nil, fmt.Errorf("create tasks provider: %w", err)
 		}
 		providers = append(providers, p)
+	}
 
-	case "calendar":
-		p, err := reminder.NewGoogleCalendarProvider(ctx, cfg.Reminders.CalendarID, cfg.Reminders.LeadTimeDays)
+	if r.Provider == "calendar" || r.Provider == "both" {
+		p, err := reminder.NewGoogleCalendarProvider(ctx, r.CalendarID, r.LeadTimeDays)
 		if err != nil {
-			return fmt.Errorf("create calendar provider: %w", err)
+			return nil, fmt.Errorf("create calendar provider: %w", err)
 		}
 		providers = append(providers, p)
+	}
+
+	if r.Provider == "ics" {
+		providers = append(providers, reminder.NewICSProvider(icsPath(r), r.LeadTimeDays))
+	}
 
-	case "both":
-		tp, err := reminder.NewGoogleTasksProvider(ctx, cfg.Reminders.TasklistID, cfg.Reminders.LeadTimeDays)
+	if r.Provider == "webhook" {
+		p, err := newWebhookProvider(r, notices)
 		if err != nil {
-			return fmt.Errorf("create tasks provider: %w", err)
+			return nil, fmt.Errorf("create webhook provider: %w", err)
 		}
-		cp, err := reminder.NewGoogleCalendarProvider(ctx, cfg.Reminders.CalendarID, cfg.Reminders.LeadTimeDays)
+		providers = append(providers, p)
+	}
+
+	if r.Provider == "issues" {
+		tracker, err := newIssueTracker(r)
 		if err != nil {
-			return fmt.Errorf("create calendar provider: %w", err)
+			return nil, fmt.Errorf("create issues provider: %w", err)
 		}
-		providers = append(providers, tp, cp)
+		providers = append(providers, reminder.NewIssuesProvider(tracker, r.LeadTimeDays))
+	}
 
-	case "none":
-		fmt.Println("Reminders provider is set to 'none'. Nothing to clear.")
-		return nil
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("unknown provider: %s (use: tasks, calendar, both, webhook, ics, issues, none)", r.Provider)
+	}
+	return providers, nil
+}
 
-	default:
-		return fmt.Errorf("unknown provider: %s", cfg.Reminders.Provider)
+// icsPath resolves the ics provider's path against the repo root.
+func icsPath(r *config.RemindersConfig) string {
+	path := ".waxseal/reminders.ics"
+	if r.ICS != nil && r.ICS.Path != "" {
+		path = r.ICS.Path
+	}
+	if path != reminder.ICSStdout && !filepath.IsAbs(path) {
+		path = filepath.Join(repoPath, path)
 	}
+	return path
+}
 
-	for _, provider := range providers {
-		if err := provider.DeleteReminders(ctx, shortName); err != nil {
-			return fmt.Errorf("delete reminders: %w", err)
+// newWebhookProvider creates the webhook provider, reading the URL and the
+// signing secret from the environment when the config names variables.
+func newWebhookProvider(r *config.RemindersConfig, notices reminder.NoticeLog) (*reminder.WebhookProvider, error) {
+	w := r.Webhook
+	if w == nil {
+		return nil, fmt.Errorf("reminders.webhook is not configured")
+	}
+	url := w.URL
+	if w.URLEnv != "" {
+		url = os.Getenv(w.URLEnv)
+		if url == "" {
+			return nil, fmt.Errorf("%s is not set", w.URLEnv)
+		}
+	}
+	var signingSecret []byte
+	if w.SigningSecretEnv != "" {
+		signingSecret = []byte(os.Getenv(w.SigningSecretEnv))
+		if len(signingSecret) == 0 {
+			return nil, fmt.Errorf("%s is not set", w.SigningSecretEnv)
 		}
 	}
+	return reminder.NewWebhookProvider(url, w.BodyTemplate, signingSecret, r.LeadTimeDays, notices)
+}
 
-	printSuccess("Cleared reminders for %s", shortName)
+// newIssueTracker creates the configured issue tracker client, reading its
+// credentials from the environment.
+func newIssueTracker(r *config.RemindersConfig) (reminder.IssueTracker, error) {
+	i := r.Issues
+	if i == nil {
+		return nil, fmt.Errorf("reminders.issues is not configured")
+	}
+	token := os.Getenv(i.TokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("%s is not set", i.TokenEnv)
+	}
+
+	switch i.Tracker {
+	case "github":
+		return reminder.NewGitHubTracker(i.URL, i.Project, token, i.Labels), nil
+	case "gitlab":
+		return reminder.NewGitLabTracker(i.URL, i.Project, token, i.Labels), nil
+	case "jira":
+		var email string
+		if i.EmailEnv != "" {
+			email = os.Getenv(i.EmailEnv)
+			if email == "" {
+				return nil, fmt.Errorf("%s is not set", i.EmailEnv)
+			}
+		}
+		return reminder.NewJiraTracker(i.URL, i.Project, email, token, i.IssueType, i.Labels), nil
+	default:
+		return nil, fmt.Errorf("unknown tracker: %s (use: github, gitlab, jira)", i.Tracker)
+	}
+}
+
+func runRemindersExport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Lead times come from the config when there is one
+	var leadTimeDays []int
+	if cfg, err := resolveConfig(); err == nil && cfg.Reminders != nil {
+		leadTimeDays = cfg.Reminders.LeadTimeDays
+	}
+
+	secrets, err := loadAllMetadata()
+	if err != nil {
+		return err
+	}
+
+	path := remindersExportOutput
+	if path != reminder.ICSStdout && !filepath.IsAbs(path) {
+		path = filepath.Join(repoPath, path)
+	}
+	result, err := reminder.NewICSProvider(path, leadTimeDays).SyncReminders(ctx, secrets)
+	if err != nil {
+		return err
+	}
+
+	if path != reminder.ICSStdout {
+		printSuccess("Wrote %d events to %s", result.Created, remindersExportOutput)
+	}
 	return nil
 }
 
@@ -3,13 +3,18 @@ package cli
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
-	"os/exec"
+	"slices"
+	"time"
 
+	"github.com/shermanhuman/waxseal/internal/config"
+	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
 	"github.com/shermanhuman/waxseal/internal/reseal"
 	"github.com/shermanhuman/waxseal/internal/seal"
 	"github.com/shermanhuman/waxseal/internal/state"
+	"github.com/shermanhuman/waxseal/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +28,10 @@ encrypts using the controller's public certificate, and writes the manifest.
 
 By default all active secrets are resealed. Specify a shortName to reseal
 just one. Before resealing, the cluster's certificate is checked for rotation
-and updated automatically if needed.
+and updated automatically if needed. Every certificate is kept in
+.waxseal/certs, and each manifest records the fingerprint of the certificate
+that sealed it, so --stale-only can refresh just the manifests sealed under
+an older one.
 
 Examples:
   # Reseal all active secrets (default)
@@ -38,6 +46,19 @@ Examples:
   # Dry run to see what would be done
   waxseal reseal --dry-run
 
+  # Fetch and seal up to 8 secrets in parallel
+  waxseal reseal --concurrency 8
+
+  # Reseal for one environment, or for all of them
+  waxseal reseal --env prod
+  waxseal reseal --all-envs
+
+  # Reseal only manifests still sealed under a superseded certificate
+  waxseal reseal --stale-only
+
+  # Create the digest key that keeps unchanged ciphertext stable
+  waxseal reseal --create-digest-key
+
 Exit codes:
   0 - Success
   1 - Partial failure (some secrets failed)
@@ -46,72 +67,203 @@ Exit codes:
 	RunE: runReseal,
 }
 
-var resealSkipCertCheck bool
+var (
+	resealSkipCertCheck bool
+	resealConcurrency   int
+	resealEnv           string
+	resealAllEnvs       bool
+	resealStaleOnly     bool
+	resealCreateKey     bool
+)
 
 func init() {
 	rootCmd.AddCommand(resealCmd)
 	resealCmd.Flags().BoolVar(&resealSkipCertCheck, "skip-cert-check", false, "Skip cluster cert rotation check (offline/CI)")
-	addPreflightChecks(resealCmd, authNeeds{gsm: true, kubeseal: true})
+	resealCmd.Flags().IntVar(&resealConcurrency, "concurrency", 1, "Number of secrets to fetch and seal in parallel")
+	resealCmd.Flags().StringVar(&resealEnv, "env", "", "Reseal for one configured environment")
+	resealCmd.Flags().BoolVar(&resealAllEnvs, "all-envs", false, "Reseal for the top-level profile and every configured environment")
+	resealCmd.Flags().BoolVar(&resealStaleOnly, "stale-only", false, "Reseal only secrets not sealed with the current certificate")
+	resealCmd.Flags().BoolVar(&resealCreateKey, "create-digest-key", false, "Create a new digest key in the store for stable ciphertext")
+	resealCmd.MarkFlagsMutuallyExclusive("env", "all-envs")
+	addPreflightChecks(resealCmd, authNeeds{store: true, sealer: true})
 	addMetadataCheck(resealCmd)
 }
 
 func runReseal(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	if resealConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if resealStaleOnly && len(args) == 1 {
+		return fmt.Errorf("--stale-only selects secrets itself; omit the shortName")
+	}
+
 	// Load config
 	cfg, err := resolveConfig()
 	if err != nil {
 		return err
 	}
 
+	envs := []string{resealEnv}
+	if resealAllEnvs {
+		envs = append([]string{""}, cfg.EnvNames()...)
+	}
+
+	// With --all-envs, a single secret is resealed for each env it targets
+	var metadata *core.SecretMetadata
+	if len(args) == 1 && resealAllEnvs {
+		if metadata, err = files.LoadMetadata(repoPath, args[0]); err != nil {
+			return err
+		}
+	}
+
+	var results []*reseal.Result
+	for _, env := range envs {
+		if metadata != nil && !metadata.TargetsEnv(env) {
+			continue
+		}
+
+		envCfg, err := cfg.ForEnv(env)
+		if err != nil {
+			return err
+		}
+		if len(envs) > 1 {
+			fmt.Printf("\n%s\n", envLabel(env))
+		}
+
+		envResults, err := resealForEnv(ctx, envCfg, args)
+		if err != nil {
+			return err
+		}
+		results = append(results, envResults...)
+	}
+
+	applyDerivedExpiry(results)
+
+	if len(args) == 1 {
+		return reportResealOne(results)
+	}
+	return reportResealAll(results)
+}
+
+// createDigestKeyForEnv creates a digest key in the environment's store for
+// --create-digest-key and tells the user which version to pin.
+func createDigestKeyForEnv(ctx context.Context, envCfg *config.Config, secretStore store.Store) ([]byte, error) {
+	field := "store.digestKeyVersion"
+	if envCfg.Env != "" {
+		field = "environments." + envCfg.Env + ".digestKeyVersion"
+	}
+	if dryRun {
+		fmt.Printf("Would create a new version of %s\n", digestKeySecretID)
+		return nil, nil
+	}
+	version, key, err := createDigestKey(ctx, envCfg, secretStore)
+	if err != nil {
+		return nil, err
+	}
+	printSuccess("Created %s version %s", digestKeySecretID, version)
+	fmt.Printf("Pin it in .waxseal/config.yaml: %s: %q\n", field, version)
+	return key, nil
+}
+
+// resealForEnv reseals one secret (args[0]) or all secrets targeting the
+// environment envCfg was derived for, using that environment's cert and store.
+func resealForEnv(ctx context.Context, envCfg *config.Config, args []string) ([]*reseal.Result, error) {
 	// Create store
-	secretStore, closeStore, err := resolveStore(ctx, cfg)
+	secretStore, closeStore, err := resolveStore(ctx, envCfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer closeStore()
 
-	// Create sealer using kubeseal binary for guaranteed controller compatibility
-	sealer := resolveSealer(cfg)
-	certPath := resolveCertPath(cfg)
-
-	fmt.Printf("Using certificate: %s (kubeseal binary)\n", cfg.Cert.RepoCertPath)
+	// Create sealer (native by default, kubeseal if configured)
+	certPath := resolveCertPath(envCfg)
+	fmt.Printf("Using certificate: %s (%s sealer)\n", envCfg.Cert.RepoCertPath, envCfg.Cert.Sealer)
 
 	// Always check cert rotation unless skipped
 	if !resealSkipCertCheck {
-		certUpdated, err := checkAndUpdateCert(ctx, certPath)
-		if err != nil {
-			return err
+		if _, err := checkAndUpdateCert(ctx, envCfg, certPath); err != nil {
+			return nil, err
 		}
-		if certUpdated {
-			// Re-create sealer with new cert
-			sealer = resolveSealer(cfg)
+	}
+	if !dryRun {
+		if err := recordRepoCert(envCfg.Env, certPath); err != nil {
+			printWarning("Could not record the certificate in %s: %v", state.CertsDir, err)
 		}
 	}
 
+	// Create the sealer after any cert update so it uses the current cert
+	sealer, err := resolveSealer(envCfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create engine
 	engine := reseal.NewEngine(secretStore, sealer, repoPath, dryRun)
+	engine.SetConcurrency(resealConcurrency)
+	engine.SetEnvironment(envCfg.Env)
+	engine.SetStaleOnly(resealStaleOnly)
+
+	// Keep ciphertext stable for unchanged values (best effort)
+	var digestKey []byte
+	if resealCreateKey {
+		digestKey, err = createDigestKeyForEnv(ctx, envCfg, secretStore)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		digestKey, err = resolveDigestKey(ctx, envCfg, secretStore)
+		if err != nil {
+			printWarning("Stable ciphertext disabled, all keys will be re-encrypted: %v", err)
+		}
+	}
+	engine.SetDigestKey(digestKey)
 
 	// No args = reseal all, one arg = reseal one
 	if len(args) == 1 {
-		return runResealOne(ctx, engine, args[0])
+		result, err := engine.ResealOne(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*reseal.Result{result}, nil
 	}
 
-	return runResealAll(ctx, engine)
+	return engine.ResealAll(ctx)
 }
 
-func runResealOne(ctx context.Context, engine *reseal.Engine, shortName string) error {
-	result, err := engine.ResealOne(ctx, shortName)
-	if err != nil {
-		return err
+// envLabel names an environment for display.
+func envLabel(env string) string {
+	if env == "" {
+		return "Environment: (default)"
 	}
+	return "Environment: " + env
+}
 
-	if result.DryRun {
-		printSuccess("%s: would reseal %d keys [DRY RUN]", result.ShortName, result.KeysResealed)
-	} else {
-		printSuccess("%s: resealed %d keys", result.ShortName, result.KeysResealed)
-		// Record in state
-		if err := recordResealState(result.ShortName); err != nil {
+// resultName labels a result with its environment, if any.
+func resultName(r *reseal.Result) string {
+	if r.Env == "" {
+		return r.ShortName
+	}
+	return fmt.Sprintf("%s [%s]", r.ShortName, r.Env)
+}
+
+func reportResealOne(results []*reseal.Result) error {
+	var changed []string
+	for _, result := range results {
+		if result.Unchanged {
+			printSuccess("%s: unchanged", resultName(result))
+		} else if result.DryRun {
+			printSuccess("%s: would reseal %d keys [DRY RUN]", resultName(result), result.KeysResealed)
+		} else {
+			printSuccess("%s: resealed %d keys", resultName(result), result.KeysResealed)
+			changed = append(changed, result.ShortName)
+		}
+	}
+
+	// Record in state
+	if len(changed) > 0 {
+		if err := recordResealStateAll(changed); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to update state: %v\n", err)
 		}
 	}
@@ -119,23 +271,21 @@ func runResealOne(ctx context.Context, engine *reseal.Engine, shortName string)
 	return nil
 }
 
-func runResealAll(ctx context.Context, engine *reseal.Engine) error {
-	results, err := engine.ResealAll(ctx)
-	if err != nil {
-		return err
-	}
-
+func reportResealAll(results []*reseal.Result) error {
 	var successCount, failCount int
 	var successNames []string
 	for _, r := range results {
 		if r.Error != nil {
-			printError("%s: %v", r.ShortName, r.Error)
+			printError("%s: %v", resultName(r), r.Error)
 			failCount++
+		} else if r.Unchanged {
+			printSuccess("%s: unchanged", resultName(r))
+			successCount++
 		} else if r.DryRun {
-			printSuccess("%s: would reseal %d keys [DRY RUN]", r.ShortName, r.KeysResealed)
+			printSuccess("%s: would reseal %d keys [DRY RUN]", resultName(r), r.KeysResealed)
 			successCount++
 		} else {
-			printSuccess("%s: resealed %d keys", r.ShortName, r.KeysResealed)
+			printSuccess("%s: resealed %d keys", resultName(r), r.KeysResealed)
 			successCount++
 			successNames = append(successNames, r.ShortName)
 		}
@@ -164,11 +314,62 @@ func runResealAll(ctx context.Context, engine *reseal.Engine) error {
 	return nil
 }
 
-// recordResealState adds a reseal record to state.yaml.
-func recordResealState(shortName string) error {
-	return withState(func(s *state.State) {
-		s.AddRotation(shortName, "", "reseal", "")
-	})
+// applyDerivedExpiry writes the expiry the reseal engine read from the
+// values of keys with expiry.source derived into their metadata, so
+// reminders and check expiry follow the real expiry. When a secret is
+// resealed for several environments, the earliest expiry wins.
+func applyDerivedExpiry(results []*reseal.Result) {
+	derived := make(map[string]map[string]time.Time)
+	for _, r := range results {
+		if r.Error != nil {
+			continue
+		}
+		for keyName, err := range r.ExpiryErrors {
+			printWarning("%s/%s: cannot derive expiry: %v", resultName(r), keyName, err)
+		}
+		for keyName, expiresAt := range r.DerivedExpiry {
+			if derived[r.ShortName] == nil {
+				derived[r.ShortName] = make(map[string]time.Time)
+			}
+			if cur, ok := derived[r.ShortName][keyName]; !ok || expiresAt.Before(cur) {
+				derived[r.ShortName][keyName] = expiresAt
+			}
+		}
+	}
+
+	for _, shortName := range slices.Sorted(maps.Keys(derived)) {
+		metadata, err := files.LoadMetadata(repoPath, shortName)
+		if err != nil {
+			printWarning("%s: cannot update derived expiry: %v", shortName, err)
+			continue
+		}
+		changed := false
+		for i := range metadata.Keys {
+			k := &metadata.Keys[i]
+			expiresAt, ok := derived[shortName][k.KeyName]
+			if !ok || !k.Expiry.IsDerived() {
+				continue
+			}
+			value := expiresAt.UTC().Format(time.RFC3339)
+			if k.Expiry.ExpiresAt == value {
+				continue
+			}
+			if dryRun {
+				fmt.Printf("  %s/%s: would set expiry to %s [DRY RUN]\n", shortName, k.KeyName, value)
+			} else {
+				fmt.Printf("  %s/%s: expiry set to %s (derived from value)\n", shortName, k.KeyName, value)
+			}
+			k.Expiry.ExpiresAt = value
+			changed = true
+		}
+		if !changed || dryRun {
+			continue
+		}
+		writer := files.NewAtomicWriter()
+		if err := writer.Write(files.MetadataPath(repoPath, shortName), []byte(serializeMetadata(metadata))); err != nil {
+			printWarning("%s: cannot write derived expiry: %v", shortName, err)
+		}
+	}
 }
 
 // recordResealStateAll records multiple reseals in a single state update.
@@ -182,7 +383,7 @@ func recordResealStateAll(shortNames []string) error {
 
 // checkAndUpdateCert checks if the cluster's sealing certificate has rotated
 // and updates the repo cert if needed. Returns true if the cert was updated.
-func checkAndUpdateCert(ctx context.Context, certPath string) (bool, error) {
+func checkAndUpdateCert(ctx context.Context, cfg *config.Config, certPath string) (bool, error) {
 	// Load current certificate fingerprint
 	currentSealer, err := seal.NewCertSealerFromFile(certPath)
 	if err != nil {
@@ -195,7 +396,7 @@ func checkAndUpdateCert(ctx context.Context, certPath string) (bool, error) {
 	// Fetch certificate from the cluster
 	var newCertData []byte
 	err = withSpinner("Checking cluster certificate...", func() error {
-		newCertData, err = fetchCertFromCluster(ctx)
+		newCertData, err = fetchCertFromCluster(ctx, cfg)
 		return err
 	})
 	if err != nil {
@@ -226,7 +427,7 @@ func checkAndUpdateCert(ctx context.Context, certPath string) (bool, error) {
 	allSecrets, _ := files.LoadAllMetadataCollectErrors(repoPath)
 	var activeCount int
 	for _, m := range allSecrets {
-		if !m.IsRetired() {
+		if !m.IsRetired() && m.TargetsEnv(cfg.Env) {
 			activeCount++
 		}
 	}
@@ -248,6 +449,12 @@ func checkAndUpdateCert(ctx context.Context, certPath string) (bool, error) {
 		}
 	}
 
+	// Keep the outgoing cert in the history before replacing it, so the
+	// manifests it sealed can be found later
+	if err := recordRepoCert(cfg.Env, certPath); err != nil {
+		printWarning("Could not record the outgoing certificate: %v", err)
+	}
+
 	// Write new certificate
 	if err := os.WriteFile(certPath, newCertData, 0o644); err != nil {
 		return false, fmt.Errorf("write certificate: %w", err)
@@ -256,18 +463,43 @@ func checkAndUpdateCert(ctx context.Context, certPath string) (bool, error) {
 	return true, nil
 }
 
-// fetchCertFromCluster fetches the sealing certificate from the cluster.
-// This is a variable to allow test injection.
-var fetchCertFromCluster = defaultFetchCertFromCluster
+// recordRepoCert records the cert at certPath as the current cert of env in
+// the repo's cert history (.waxseal/certs), superseding the previous one.
+func recordRepoCert(env, certPath string) error {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	return recordCertHistory(env, certPEM)
+}
+
+// recordCertHistory records certPEM as the current cert of env.
+func recordCertHistory(env string, certPEM []byte) error {
+	history, err := state.LoadCertHistory(repoPath)
+	if err != nil {
+		return err
+	}
+	record, changed, err := history.Record(repoPath, env, certPEM)
+	if err != nil || !changed {
+		return err
+	}
+	if err := history.Save(repoPath); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded certificate %s... in %s\n", record.Fingerprint[:16], state.CertsDir)
+	return nil
+}
 
-func defaultFetchCertFromCluster(ctx context.Context) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "kubeseal", "--fetch-cert")
-	output, err := cmd.Output()
+// fetchCertFromCluster fetches the sealing certificate from the controller
+// in the cluster cfg selects.
+func fetchCertFromCluster(ctx context.Context, cfg *config.Config) ([]byte, error) {
+	kube, err := resolveCluster(cfg)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("kubeseal --fetch-cert: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("kubeseal --fetch-cert: %w", err)
+		return nil, err
+	}
+	cert, err := kube.FetchCert(ctx, cfg.Controller.Namespace, cfg.Controller.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("fetch controller certificate: %w", err)
 	}
-	return output, nil
+	return cert, nil
 }
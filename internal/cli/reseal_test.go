@@ -1,9 +1,22 @@
 package cli
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/cluster"
+	"github.com/shermanhuman/waxseal/internal/config"
+	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/reseal"
 )
 
 // TestResealCommand_MetadataLoading tests metadata loading for reseal.
@@ -254,3 +267,101 @@ keys:
 	// Create keys directory
 	os.MkdirAll(filepath.Join(tmpDir, "keys"), 0o755)
 }
+
+func TestCheckAndUpdateCert_FetchesFromController(t *testing.T) {
+	tmpDir := t.TempDir()
+	origRepoPath, origYes, origDryRun := repoPath, yes, dryRun
+	defer func() { repoPath, yes, dryRun = origRepoPath, origYes, origDryRun }()
+	repoPath, yes, dryRun = tmpDir, true, false
+
+	certPath := filepath.Join(tmpDir, "pub-cert.pem")
+	if err := os.WriteFile(certPath, testCertPEM(t), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rotated := testCertPEM(t)
+
+	kube := cluster.NewFake()
+	kube.SetCert("sealed", "sealed-secrets-controller", rotated)
+	contexts := useFakeCluster(t, kube)
+
+	cfg := &config.Config{
+		Controller: config.ControllerConfig{Namespace: "sealed", ServiceName: "sealed-secrets-controller"},
+		Bootstrap:  config.BootstrapConfig{Cluster: config.ClusterConfig{KubeContext: "staging"}},
+	}
+	updated, err := checkAndUpdateCert(context.Background(), cfg, certPath)
+	if err != nil {
+		t.Fatalf("checkAndUpdateCert failed: %v", err)
+	}
+	if !updated {
+		t.Error("expected the rotated certificate to be written")
+	}
+	if got, _ := os.ReadFile(certPath); string(got) != string(rotated) {
+		t.Error("repo cert was not replaced with the controller's")
+	}
+	if len(*contexts) != 1 || (*contexts)[0] != "staging" {
+		t.Errorf("kube contexts = %v, want [staging]", *contexts)
+	}
+}
+
+// testCertPEM returns a self-signed certificate for a fresh RSA key.
+func testCertPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestApplyDerivedExpiry(t *testing.T) {
+	tmpDir := t.TempDir()
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = tmpDir
+
+	metadata := `shortName: webhook
+manifestPath: apps/test/{env}/sealed.yaml
+environments: [prod, staging]
+sealedSecret:
+  name: webhook
+  namespace: prod
+  scope: strict
+keys:
+  - keyName: tls.crt
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/p/secrets/crt
+      version: "1"
+    rotation:
+      mode: external
+    expiry:
+      source: derived
+`
+	setupValidateTest(t, tmpDir, "webhook", metadata, "")
+
+	early := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	applyDerivedExpiry([]*reseal.Result{
+		{ShortName: "webhook", Env: "prod", DerivedExpiry: map[string]time.Time{"tls.crt": late}},
+		{ShortName: "webhook", Env: "staging", DerivedExpiry: map[string]time.Time{"tls.crt": early}},
+	})
+
+	m, err := files.LoadMetadata(tmpDir, "webhook")
+	if err != nil {
+		t.Fatalf("LoadMetadata failed: %v", err)
+	}
+	if got := m.Keys[0].Expiry; got.ExpiresAt != "2027-03-01T00:00:00Z" || !got.IsDerived() {
+		t.Errorf("expiry = %+v, want the earliest derived date and source derived", got)
+	}
+}
@@ -2,9 +2,13 @@ package cli
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"os"
 	"path/filepath"
 
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/cluster"
 	"github.com/shermanhuman/waxseal/internal/config"
 	"github.com/shermanhuman/waxseal/internal/seal"
 	"github.com/shermanhuman/waxseal/internal/state"
@@ -28,24 +32,130 @@ func resolveConfig() (*config.Config, error) {
 // resolveStore creates a secret store from config.
 // Returns the store, a cleanup function (must be deferred), and any error.
 func resolveStore(ctx context.Context, cfg *config.Config) (store.Store, func(), error) {
-	if cfg.Store.Kind != "gsm" {
+	switch cfg.Store.Kind {
+	case "gsm":
+		gsmStore, err := store.NewGSMStore(ctx, cfg.Store.ProjectID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create GSM store: %w", err)
+		}
+		return gsmStore, func() { gsmStore.Close() }, nil
+	case "vault":
+		opts := store.VaultOptions{Token: os.Getenv("VAULT_TOKEN")}
+		if cfg.Store.Vault != nil {
+			opts.Address = cfg.Store.Vault.Address
+			opts.Mount = cfg.Store.Vault.Mount
+			opts.Namespace = cfg.Store.Vault.Namespace
+		}
+		vaultStore, err := store.NewVaultStore(opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create Vault store: %w", err)
+		}
+		return vaultStore, func() {}, nil
+	case "aws":
+		creds, err := store.LoadAWSCredentials()
+		if err != nil {
+			return nil, nil, fmt.Errorf("create AWS store: %w", err)
+		}
+		opts := store.AWSOptions{Credentials: creds}
+		if cfg.Store.AWS != nil {
+			opts.Region = cfg.Store.AWS.Region
+			opts.Endpoint = cfg.Store.AWS.Endpoint
+		}
+		awsStore, err := store.NewAWSStore(opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create AWS store: %w", err)
+		}
+		return awsStore, func() {}, nil
+	case "file":
+		opts := store.FileStoreOptions{
+			Path:         cfg.Store.File.Path,
+			Recipients:   cfg.Store.File.Recipients,
+			IdentityFile: cfg.Store.File.IdentityFile,
+		}
+		if !filepath.IsAbs(opts.Path) {
+			opts.Path = filepath.Join(repoPath, opts.Path)
+		}
+		if opts.IdentityFile != "" && !filepath.IsAbs(opts.IdentityFile) {
+			opts.IdentityFile = filepath.Join(repoPath, opts.IdentityFile)
+		}
+		if len(opts.Recipients) == 0 {
+			opts.Passphrase = os.Getenv("WAXSEAL_STORE_PASSPHRASE")
+		}
+		fileStore, err := store.NewFileStore(opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create file store: %w", err)
+		}
+		return fileStore, func() {}, nil
+	default:
 		return nil, nil, fmt.Errorf("unsupported store kind: %s", cfg.Store.Kind)
 	}
-	gsmStore, err := store.NewGSMStore(ctx, cfg.Store.ProjectID)
+}
+
+// resolveSealer creates the sealer selected by cert.sealer, resolving the
+// cert path relative to repoPath if necessary. The native sealer is the
+// default; it produces the same format as the controller without kubeseal.
+func resolveSealer(cfg *config.Config) (seal.Sealer, error) {
+	certPath := resolveCertPath(cfg)
+	if cfg.Cert.Sealer == config.SealerKubeseal {
+		return seal.NewKubesealSealer(certPath), nil
+	}
+	sealer, err := seal.NewCertSealerFromFile(certPath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("create GSM store: %w", err)
+		return nil, fmt.Errorf("load certificate: %w", err)
 	}
-	return gsmStore, func() { gsmStore.Close() }, nil
+	return sealer, nil
 }
 
-// resolveSealer creates a KubesealSealer from config, resolving the cert
-// path relative to repoPath if necessary.
-func resolveSealer(cfg *config.Config) *seal.KubesealSealer {
-	certPath := cfg.Cert.RepoCertPath
-	if !filepath.IsAbs(certPath) {
-		certPath = filepath.Join(repoPath, certPath)
+// digestKeySecretID is the store secret holding the HMAC key for plaintext
+// digests (see reseal.Engine.SetDigestKey).
+const digestKeySecretID = "waxseal-digest-key"
+
+// resolveDigestKey loads the digest key version pinned by
+// store.digestKeyVersion. Returns nil (stable ciphertext disabled) when no
+// version is pinned. It never creates the key; see createDigestKey.
+func resolveDigestKey(ctx context.Context, cfg *config.Config, s store.Store) ([]byte, error) {
+	version := cfg.Store.DigestKeyVersion
+	if version == "" {
+		return nil, nil
+	}
+	resource := store.SecretResource(cfg.Store.ProjectID, digestKeySecretID)
+	key, err := s.AccessVersion(ctx, resource, version)
+	if err != nil {
+		return nil, fmt.Errorf("read digest key version %s: %w", version, err)
+	}
+	return key, nil
+}
+
+// createDigestKey generates a digest key and adds it as a new version of the
+// digest key secret, creating the secret if needed. Returns the version to
+// pin in store.digestKeyVersion.
+func createDigestKey(ctx context.Context, cfg *config.Config, s store.Store) (version string, key []byte, err error) {
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, fmt.Errorf("generate digest key: %w", err)
+	}
+	resource := store.SecretResource(cfg.Store.ProjectID, digestKeySecretID)
+	version, err = s.CreateSecretVersion(ctx, resource, key)
+	if err != nil {
+		return "", nil, fmt.Errorf("create digest key: %w", err)
 	}
-	return seal.NewKubesealSealer(certPath)
+	return version, key, nil
+}
+
+// resolveCluster connects to the Kubernetes cluster: the kubeconfig from
+// --kubeconfig (bootstrap) or the default loading rules, and the context from
+// bootstrap.cluster.kubeContext. A nil cfg uses the current context.
+// This is a variable to allow test injection.
+var resolveCluster = func(cfg *config.Config) (cluster.Client, error) {
+	opts := cluster.Options{Kubeconfig: bootstrapKubeconfig}
+	if cfg != nil {
+		opts.Context = cfg.Bootstrap.Cluster.KubeContext
+	}
+	kube, err := cluster.New(opts)
+	if err != nil {
+		return nil, err
+	}
+	return kube, nil
 }
 
 // resolveCertPath returns the absolute certificate path from config.
@@ -67,3 +177,12 @@ func withState(mutate func(*state.State)) error {
 	mutate(s)
 	return s.Save(repoPath)
 }
+
+// recordAudit appends an entry, attributed to the current actor, to the
+// audit log. A failure is a warning: the change itself has already happened.
+func recordAudit(e audit.Entry) {
+	e.Actor = audit.Actor(repoPath)
+	if _, err := audit.Open(repoPath).Append(e); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write audit log: %v\n", err)
+	}
+}
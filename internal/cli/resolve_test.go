@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/shermanhuman/waxseal/internal/cluster"
+	"github.com/shermanhuman/waxseal/internal/config"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+// useFakeCluster makes resolveCluster return kube for the rest of the test.
+// The returned slice records the kube context of each resolveCluster call.
+func useFakeCluster(t *testing.T, kube cluster.Client) *[]string {
+	t.Helper()
+	var contexts []string
+	orig := resolveCluster
+	t.Cleanup(func() { resolveCluster = orig })
+	resolveCluster = func(cfg *config.Config) (cluster.Client, error) {
+		kubeContext := ""
+		if cfg != nil {
+			kubeContext = cfg.Bootstrap.Cluster.KubeContext
+		}
+		contexts = append(contexts, kubeContext)
+		return kube, nil
+	}
+	return &contexts
+}
+
+func TestResolveStore_FileIdentityRelativeToRepo(t *testing.T) {
+	dir := t.TempDir()
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = dir
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "keys"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "keys", "age.txt"), []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run from elsewhere so a cwd-relative identity path would not resolve
+	origWD, _ := os.Getwd()
+	defer os.Chdir(origWD)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Store: config.StoreConfig{
+		Kind: "file",
+		File: &config.FileStoreConfig{
+			Path:         ".waxseal/vault.age",
+			Recipients:   []string{identity.Recipient().String()},
+			IdentityFile: "keys/age.txt",
+		},
+	}}
+
+	ctx := context.Background()
+	s, closeStore, err := resolveStore(ctx, cfg)
+	if err != nil {
+		t.Fatalf("resolveStore failed: %v", err)
+	}
+	defer closeStore()
+
+	if _, err := s.CreateSecret(ctx, "projects/test/secrets/a", []byte("value")); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+	got, err := s.AccessVersion(ctx, "projects/test/secrets/a", "1")
+	if err != nil || string(got) != "value" {
+		t.Errorf("AccessVersion = %q, %v", got, err)
+	}
+}
+
+func TestResolveDigestKey(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewFakeStore()
+	cfg := &config.Config{Store: config.StoreConfig{ProjectID: "p"}}
+	resource := store.SecretResource("p", digestKeySecretID)
+
+	// Without a pinned version the key is neither read nor created
+	key, err := resolveDigestKey(ctx, cfg, s)
+	if err != nil || key != nil {
+		t.Fatalf("unpinned key = %v, %v; want nil", key, err)
+	}
+	if exists, _ := s.SecretExists(ctx, resource); exists {
+		t.Fatal("resolveDigestKey must not create the digest key")
+	}
+
+	first, _, err := createDigestKey(ctx, cfg, s)
+	if err != nil {
+		t.Fatalf("createDigestKey failed: %v", err)
+	}
+	second, secondKey, err := createDigestKey(ctx, cfg, s)
+	if err != nil {
+		t.Fatalf("createDigestKey failed: %v", err)
+	}
+	if first == second {
+		t.Fatalf("both keys are version %s", first)
+	}
+
+	// The pinned version is read, not the first one
+	cfg.Store.DigestKeyVersion = second
+	key, err = resolveDigestKey(ctx, cfg, s)
+	if err != nil {
+		t.Fatalf("resolveDigestKey failed: %v", err)
+	}
+	if string(key) != string(secondKey) {
+		t.Error("resolveDigestKey did not read the pinned version")
+	}
+
+	cfg.Store.DigestKeyVersion = "9"
+	if _, err := resolveDigestKey(ctx, cfg, s); err == nil {
+		t.Error("expected an error for a missing pinned version")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/shermanhuman/waxseal/internal/audit"
 	"github.com/shermanhuman/waxseal/internal/files"
 	"github.com/shermanhuman/waxseal/internal/state"
 	"github.com/spf13/cobra"
@@ -89,7 +90,9 @@ func runRetire(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  ReplacedBy: %s\n", retireReplacedBy)
 		}
 		if retireDeleteManifest {
-			fmt.Printf("  Would delete manifest: %s\n", metadata.ManifestPath)
+			for _, env := range metadata.TargetEnvs() {
+				fmt.Printf("  Would delete manifest: %s\n", metadata.ManifestPathFor(env))
+			}
 		}
 		if retireClearReminders {
 			fmt.Printf("  Would clear reminders\n")
@@ -109,21 +112,29 @@ func runRetire(cmd *cobra.Command, args []string) error {
 	if err := recordRetireState(shortName, retireReason, retireReplacedBy); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to update state: %v\n", err)
 	}
+	recordAudit(audit.Entry{
+		Command:   "retirekey",
+		ShortName: shortName,
+		Reason:    retireReason,
+	})
 
 	// Delete manifest if requested
 	if retireDeleteManifest {
-		manifestPath := metadata.ManifestPath
-		if !filepath.IsAbs(manifestPath) {
-			manifestPath = filepath.Join(repoPath, manifestPath)
-		}
+		for _, env := range metadata.TargetEnvs() {
+			relPath := metadata.ManifestPathFor(env)
+			manifestPath := relPath
+			if !filepath.IsAbs(manifestPath) {
+				manifestPath = filepath.Join(repoPath, manifestPath)
+			}
 
-		if _, err := os.Stat(manifestPath); err == nil {
-			if err := os.Remove(manifestPath); err != nil {
-				return fmt.Errorf("delete manifest: %w", err)
+			if _, err := os.Stat(manifestPath); err == nil {
+				if err := os.Remove(manifestPath); err != nil {
+					return fmt.Errorf("delete manifest: %w", err)
+				}
+				printSuccess("Deleted manifest: %s", relPath)
+			} else if os.IsNotExist(err) {
+				fmt.Printf("  Manifest already deleted: %s\n", relPath)
 			}
-			printSuccess("Deleted manifest: %s", metadata.ManifestPath)
-		} else if os.IsNotExist(err) {
-			fmt.Printf("  Manifest already deleted: %s\n", metadata.ManifestPath)
 		}
 	}
 
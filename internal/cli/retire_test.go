@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/shermanhuman/waxseal/internal/audit"
 	"github.com/shermanhuman/waxseal/internal/core"
 )
 
@@ -148,3 +149,103 @@ func TestSerializeMetadata_IncludesRetirement(t *testing.T) {
 		t.Error("YAML should contain 'replacedBy:'")
 	}
 }
+
+func TestRetire_DeleteManifestPerEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = dir
+
+	metadataDir := filepath.Join(dir, ".waxseal", "metadata")
+	os.MkdirAll(metadataDir, 0o755)
+	metadata := `shortName: shared
+manifestPath: apps/shared/{env}/sealed-secret.yaml
+environments: [prod, staging]
+sealedSecret:
+  name: shared
+  namespace: test
+  scope: strict
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+`
+	if err := os.WriteFile(filepath.Join(metadataDir, "shared.yaml"), []byte(metadata), 0o644); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+	for _, env := range []string{"prod", "staging"} {
+		envDir := filepath.Join(dir, "apps", "shared", env)
+		os.MkdirAll(envDir, 0o755)
+		if err := os.WriteFile(filepath.Join(envDir, "sealed-secret.yaml"), []byte("kind: SealedSecret"), 0o644); err != nil {
+			t.Fatalf("write manifest: %v", err)
+		}
+	}
+
+	retireDeleteManifest = true
+	defer func() { retireDeleteManifest = false }()
+	if err := runRetire(retireCmd, []string{"shared"}); err != nil {
+		t.Fatalf("runRetire failed: %v", err)
+	}
+
+	for _, env := range []string{"prod", "staging"} {
+		if _, err := os.Stat(filepath.Join(dir, "apps", "shared", env, "sealed-secret.yaml")); !os.IsNotExist(err) {
+			t.Errorf("%s manifest should be deleted", env)
+		}
+	}
+}
+
+func TestRetire_RecordsAuditEntry(t *testing.T) {
+	dir := t.TempDir()
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = dir
+
+	metadataDir := filepath.Join(dir, ".waxseal", "metadata")
+	os.MkdirAll(metadataDir, 0o755)
+	metadata := `shortName: old-api
+manifestPath: apps/old/sealed-secret.yaml
+sealedSecret:
+  name: old-api
+  namespace: test
+  scope: strict
+keys:
+  - keyName: token
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/token
+      version: "3"
+    rotation:
+      mode: static
+`
+	if err := os.WriteFile(filepath.Join(metadataDir, "old-api.yaml"), []byte(metadata), 0o644); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+
+	retireReason = "vendor contract ended"
+	defer func() { retireReason = "" }()
+	if err := runRetire(retireCmd, []string{"old-api"}); err != nil {
+		t.Fatalf("runRetire failed: %v", err)
+	}
+
+	log := audit.Open(dir)
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Command != "retirekey" || e.ShortName != "old-api" || e.Reason != "vendor contract ended" || e.Actor == "" {
+		t.Errorf("unexpected audit entry: %+v", e)
+	}
+	if problems, err := log.Verify(); err != nil || len(problems) != 0 {
+		t.Errorf("Verify = %v, %v", problems, err)
+	}
+}
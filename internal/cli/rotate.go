@@ -1,20 +1,28 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/config"
 	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
 	"github.com/shermanhuman/waxseal/internal/reseal"
+	"github.com/shermanhuman/waxseal/internal/rotation"
 	"github.com/shermanhuman/waxseal/internal/state"
+	"github.com/shermanhuman/waxseal/internal/store"
 	"github.com/shermanhuman/waxseal/internal/template"
 	"github.com/spf13/cobra"
 )
 
 var rotateCmd = &cobra.Command{
-	Use:   "rotate <shortName> [keyName]",
+	Use:   "rotate [shortName] [keyName]",
 	Short: "Rotate secret values and reseal",
 	Long: `Rotate secret values by generating new versions in GSM, then reseal.
 
@@ -36,6 +44,14 @@ For keys with rotation.mode=static:
   - Adds a new version to GSM
   - Reseals the manifest
 
+Hooks in config or metadata run before (preRotate) and after (postRotate)
+the rotation; see the README. A failing postRotate hook can roll the
+metadata back to the previous versions.
+
+Keys with a rotation.interval (e.g. 90d) are due once that long has passed
+since their pinned version was created. --due rotates every overdue generated
+key; 'waxseal check expiry' reports overdue keys of every mode.
+
 Examples:
   # Rotate a specific key
   waxseal rotate my-app-secrets password
@@ -46,28 +62,42 @@ Examples:
   # Manually update a static key (e.g., one-time password change)
   waxseal rotate my-app-secrets admin_password
 
+  # Record why the key was rotated in the audit log
+  waxseal rotate my-app-secrets password --reason "leaked in CI logs"
+
+  # Rotate every generated key past its rotation.interval
+  waxseal rotate --due
+
 Exit codes:
   0 - Success
   2 - Failed`,
-	Args: cobra.RangeArgs(1, 2),
+	Args: cobra.RangeArgs(0, 2),
 	RunE: runRotate,
 }
 
-var rotateGenerated bool
+var (
+	rotateGenerated bool
+	rotateDue       bool
+	rotateReason    string
+)
 
 func init() {
 	rootCmd.AddCommand(rotateCmd)
 	rotateCmd.Flags().BoolVar(&rotateGenerated, "generated", false, "Rotate all keys with mode=generated")
-	addPreflightChecks(rotateCmd, authNeeds{gsm: true, kubeseal: true})
+	rotateCmd.Flags().BoolVar(&rotateDue, "due", false, "Rotate every generated key overdue under its rotation.interval (all secrets, or one shortName)")
+	rotateCmd.Flags().StringVar(&rotateReason, "reason", "", "Reason for the rotation, recorded in the audit log")
+	addPreflightChecks(rotateCmd, authNeeds{store: true, sealer: true})
 }
 
 func runRotate(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
-	shortName := args[0]
 
-	var keyName string
-	if len(args) > 1 {
-		keyName = args[1]
+	if rotateDue {
+		if rotateGenerated || len(args) > 1 {
+			return fmt.Errorf("--due selects keys itself; it cannot be combined with --generated or a keyName")
+		}
+	} else if len(args) == 0 {
+		return fmt.Errorf("requires a shortName (or --due)")
 	}
 
 	// Load config
@@ -76,6 +106,102 @@ func runRotate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Create store
+	secretStore, closeStore, err := resolveStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if rotateDue {
+		return runRotateDue(ctx, cfg, secretStore, args)
+	}
+
+	var keyName string
+	if len(args) > 1 {
+		keyName = args[1]
+	}
+	return rotateSecret(ctx, cfg, secretStore, args[0], keyName, nil)
+}
+
+// runRotateDue rotates every generated key that is overdue under its
+// rotation.interval, in all active secrets or the one named in args.
+func runRotateDue(ctx context.Context, cfg *config.Config, secretStore store.Store, args []string) error {
+	var secrets []*core.SecretMetadata
+	if len(args) > 0 {
+		m, err := files.LoadMetadata(repoPath, args[0])
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, m)
+	} else {
+		all, err := files.LoadAllMetadata(repoPath)
+		if err != nil {
+			return err
+		}
+		secrets = all
+	}
+
+	entries, err := audit.Open(repoPath).Entries()
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+	tracker := rotation.NewTracker(secretStore, entries)
+	now := time.Now()
+
+	// Keys to rotate per secret, in metadata order
+	var names []string
+	due := make(map[string]map[string]bool)
+	for _, m := range secrets {
+		if m.IsRetired() {
+			continue
+		}
+		for _, st := range tracker.Statuses(ctx, m) {
+			switch {
+			case !st.Known():
+				printWarning("%s/%s: last rotation unknown, skipping: %v", st.ShortName, st.KeyName, st.Err)
+			case !st.Overdue(now):
+				continue
+			case st.Mode != "generated":
+				printWarning("%s/%s: overdue since %s; mode %s must be rotated by hand",
+					st.ShortName, st.KeyName, st.DueAt().Format("2006-01-02"), st.Mode)
+			default:
+				if due[m.ShortName] == nil {
+					due[m.ShortName] = make(map[string]bool)
+					names = append(names, m.ShortName)
+				}
+				due[m.ShortName][st.KeyName] = true
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No generated keys are due for rotation.")
+		return nil
+	}
+
+	if rotateReason == "" {
+		rotateReason = "rotation interval elapsed"
+	}
+
+	var failed []string
+	for _, shortName := range names {
+		if err := rotateSecret(ctx, cfg, secretStore, shortName, "", due[shortName]); err != nil {
+			printError("%s: %v", shortName, err)
+			failed = append(failed, shortName)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("rotation failed for %d of %d secrets: %s",
+			len(failed), len(names), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// rotateSecret rotates keys of one secret and reseals it. It rotates
+// keyName if set, the keys in dueKeys if set, and otherwise every generated
+// key when --generated is given.
+func rotateSecret(ctx context.Context, cfg *config.Config, secretStore store.Store, shortName, keyName string, dueKeys map[string]bool) error {
 	// Load metadata
 	metadata, err := files.LoadMetadata(repoPath, shortName)
 	if err != nil {
@@ -87,12 +213,11 @@ func runRotate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("cannot rotate retired secret %q", shortName)
 	}
 
-	// Create store
-	secretStore, closeStore, err := resolveStore(ctx, cfg)
-	if err != nil {
-		return err
+	// A key written by another key's generator rotates with its bundle
+	if owner, output := metadata.GeneratorOwner(keyName); keyName != "" && owner != "" {
+		fmt.Printf("Key %q is the %s output of %q; rotating %q\n", keyName, output, owner, owner)
+		keyName = owner
 	}
-	defer closeStore()
 
 	// Find keys to rotate
 	var keysToRotate []core.KeyMetadata
@@ -109,6 +234,13 @@ func runRotate(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		if dueKeys != nil {
+			if dueKeys[k.KeyName] {
+				keysToRotate = append(keysToRotate, k)
+			}
+			continue
+		}
+
 		if keyName != "" && k.KeyName == keyName {
 			keysToRotate = append(keysToRotate, k)
 			break
@@ -127,6 +259,18 @@ func runRotate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// The metadata as it was, for a rollback after a failed post-rotate hook
+	original, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return fmt.Errorf("read metadata: %w", err)
+	}
+	previous := storeVersions(metadata)
+
+	hookSets := rotationHooks(cfg, metadata)
+	if err := runPreRotateHooks(ctx, hookSets, shortName, keyNames(keysToRotate)); err != nil {
+		return err
+	}
+
 	// Rotate each key
 	metadataUpdated := false
 	for _, key := range keysToRotate {
@@ -138,7 +282,8 @@ func runRotate(cmd *cobra.Command, args []string) error {
 		}
 
 		var newValue []byte
-		var err error
+		var outputs []keyVersion // other keys of a generator bundle
+		var notAfter time.Time   // expiry of a generated certificate
 
 		switch key.Rotation.Mode {
 		case "generated":
@@ -196,6 +341,7 @@ func runRotate(cmd *cobra.Command, args []string) error {
 					for j := range metadata.Keys {
 						if metadata.Keys[j].KeyName == key.KeyName {
 							if metadata.Keys[j].Computed != nil && metadata.Keys[j].Computed.GSM != nil {
+								recordRotateAudit(shortName, key.KeyName, metadata.Keys[j].Computed.GSM.Version, newVersion)
 								metadata.Keys[j].Computed.GSM.Version = newVersion
 							}
 							break
@@ -209,11 +355,22 @@ func runRotate(cmd *cobra.Command, args []string) error {
 			}
 
 			// Regular GSM key generation
-			newValue, err = core.GenerateValue(key.Rotation.Generator)
+			generated, err := generateBundle(ctx, secretStore, key.Rotation.Generator)
 			if err != nil {
 				return fmt.Errorf("generate value for %s: %w", key.KeyName, err)
 			}
+			newValue = generated.Value
+			notAfter = generated.NotAfter
 			fmt.Printf("  Generated new value (%d bytes)\n", len(newValue))
+			outputKeys := key.Rotation.Generator.OutputKeys()
+			for _, output := range slices.Sorted(maps.Keys(outputKeys)) {
+				value, ok := generated.Outputs[output]
+				if !ok {
+					return fmt.Errorf("generator for %s produced no %q output", key.KeyName, output)
+				}
+				outputs = append(outputs, keyVersion{keyName: outputKeys[output], value: value})
+				fmt.Printf("  Generated %s for %s (%d bytes)\n", output, outputKeys[output], len(value))
+			}
 
 		case "external":
 			fmt.Printf("  Mode: %s\n", key.Rotation.Mode)
@@ -283,6 +440,7 @@ func runRotate(cmd *cobra.Command, args []string) error {
 					for j := range metadata.Keys {
 						if metadata.Keys[j].KeyName == key.KeyName {
 							if metadata.Keys[j].Computed != nil && metadata.Keys[j].Computed.GSM != nil {
+								recordRotateAudit(shortName, key.KeyName, metadata.Keys[j].Computed.GSM.Version, newVersion)
 								metadata.Keys[j].Computed.GSM.Version = newVersion
 							}
 							break
@@ -353,22 +511,21 @@ func runRotate(cmd *cobra.Command, args []string) error {
 
 		if dryRun {
 			fmt.Printf("  [DRY RUN] Would add new version to GSM\n")
+			for _, o := range outputs {
+				fmt.Printf("  [DRY RUN] Would add new version of %s to GSM\n", o.keyName)
+			}
 			continue
 		}
 
-		// Add new version to GSM (for regular GSM keys)
-		newVersion, err := secretStore.AddVersion(ctx, key.GSM.SecretResource, newValue)
-		if err != nil {
-			return fmt.Errorf("add GSM version for %s: %w", key.KeyName, err)
+		// Add new versions to GSM (for regular GSM keys): the key itself and
+		// every key of its generator bundle, together or not at all
+		writes := append([]keyVersion{{keyName: key.KeyName, value: newValue}}, outputs...)
+		if err := addKeyVersions(ctx, secretStore, metadata, writes); err != nil {
+			return err
 		}
-		fmt.Printf("  Added GSM version: %s\n", newVersion)
-
-		// Update metadata with new version
-		for j := range metadata.Keys {
-			if metadata.Keys[j].KeyName == key.KeyName {
-				metadata.Keys[j].GSM.Version = newVersion
-				break
-			}
+		if !notAfter.IsZero() {
+			setKeyExpiry(metadata, key.KeyName, notAfter)
+			fmt.Printf("  Certificate expires: %s\n", notAfter.UTC().Format(time.RFC3339))
 		}
 		metadataUpdated = true
 	}
@@ -391,28 +548,177 @@ func runRotate(cmd *cobra.Command, args []string) error {
 	// Reseal
 	fmt.Println("\nResealing...")
 
-	// Use kubeseal binary for encryption (guarantees controller compatibility)
-	sealer := resolveSealer(cfg)
+	results, resealed, failed := resealEnvs(ctx, cfg, metadata)
 
-	engine := reseal.NewEngine(secretStore, sealer, repoPath, dryRun)
-	result, err := engine.ResealOne(ctx, shortName)
-	if err != nil {
-		return fmt.Errorf("reseal: %w", err)
-	}
+	// Keys with a derived expiry now expire with their new values
+	applyDerivedExpiry(results)
 
-	if result.DryRun {
-		printSuccess("Would reseal %d keys [DRY RUN]", result.KeysResealed)
-	} else {
-		printSuccess("Resealed %d keys", result.KeysResealed)
-		// Record rotation in state
+	// Record rotation in state
+	if len(resealed) > 0 {
 		if err := recordRotateState(shortName, keyName); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to update state: %v\n", err)
 		}
 	}
 
+	if len(failed) > 0 {
+		if postRotateHookCount(hookSets) > 0 {
+			printWarning("Post-rotate hooks skipped: reseal failed")
+		}
+		return fmt.Errorf("reseal failed for %d of %d environments; run 'waxseal reseal %s --all-envs' to retry",
+			len(failed), len(metadata.TargetEnvs()), shortName)
+	}
+
+	rotated := changedVersions(previous, storeVersions(metadata))
+	rollback, err := runPostRotateHooks(ctx, hookSets, shortName, rotated)
+	if err != nil {
+		if !rollback {
+			return fmt.Errorf("%w; the new versions are kept", err)
+		}
+		if rbErr := rollbackRotation(ctx, cfg, shortName, metadataPath, original, previous, rotated, err); rbErr != nil {
+			return fmt.Errorf("%w; rollback failed: %v", err, rbErr)
+		}
+		return fmt.Errorf("%w; rolled back to the previous versions", err)
+	}
+
 	return nil
 }
 
+// resealEnvs reseals a secret for every environment it targets. One
+// environment failing does not stop the others; the new values are already
+// stored.
+func resealEnvs(ctx context.Context, cfg *config.Config, metadata *core.SecretMetadata) (results []*reseal.Result, resealed, failed []string) {
+	for _, env := range metadata.TargetEnvs() {
+		result, err := resealRotated(ctx, cfg, env, metadata.ShortName)
+		if err != nil {
+			printError("%s: %v", envLabel(env), err)
+			failed = append(failed, env)
+			continue
+		}
+		results = append(results, result)
+
+		if result.DryRun {
+			printSuccess("%s: would reseal %d keys [DRY RUN]", resultName(result), result.KeysResealed)
+		} else {
+			printSuccess("%s: resealed %d keys", resultName(result), result.KeysResealed)
+			resealed = append(resealed, env)
+		}
+	}
+	return results, resealed, failed
+}
+
+// generateBundle runs a generator. A tls generator with an issuer reads the
+// CA certificate and key from the store.
+func generateBundle(ctx context.Context, secretStore store.Store, gen *core.GeneratorConfig) (*core.Generated, error) {
+	if gen == nil || gen.Kind != "tls" || gen.Issuer == nil {
+		return core.Generate(gen)
+	}
+	certPEM, err := secretStore.AccessVersion(ctx, gen.Issuer.Cert.SecretResource, gen.Issuer.Cert.Version)
+	if err != nil {
+		return nil, fmt.Errorf("read issuer certificate: %w", err)
+	}
+	keyPEM, err := secretStore.AccessVersion(ctx, gen.Issuer.Key.SecretResource, gen.Issuer.Key.Version)
+	if err != nil {
+		return nil, fmt.Errorf("read issuer key: %w", err)
+	}
+	issuer, err := core.ParseIssuer(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return core.IssueCertificate(gen, issuer, time.Now())
+}
+
+// setKeyExpiry sets a key's expiry date, e.g. from a generated certificate.
+func setKeyExpiry(metadata *core.SecretMetadata, keyName string, expiresAt time.Time) {
+	for j := range metadata.Keys {
+		if metadata.Keys[j].KeyName == keyName {
+			if metadata.Keys[j].Expiry == nil {
+				metadata.Keys[j].Expiry = &core.ExpiryConfig{}
+			}
+			metadata.Keys[j].Expiry.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+			return
+		}
+	}
+}
+
+// keyVersion is a new value for one store key of a secret.
+type keyVersion struct {
+	keyName string
+	value   []byte
+}
+
+// addKeyVersions adds a new store version for each key and, only when all
+// of them succeed, points the metadata at the new versions. If one fails,
+// the metadata keeps the old versions for every key, so the keys are never
+// resealed with a mix of old and new values; versions already added stay
+// unreferenced in the store.
+func addKeyVersions(ctx context.Context, secretStore store.Store, metadata *core.SecretMetadata, writes []keyVersion) error {
+	targets := make([]*core.KeyMetadata, len(writes))
+	for i, w := range writes {
+		for j := range metadata.Keys {
+			if metadata.Keys[j].KeyName == w.keyName {
+				targets[i] = &metadata.Keys[j]
+				break
+			}
+		}
+		if targets[i] == nil || targets[i].GSM == nil {
+			return fmt.Errorf("key %q has no GSM reference", w.keyName)
+		}
+	}
+
+	newVersions := make([]string, len(writes))
+	for i, w := range writes {
+		version, err := secretStore.AddVersion(ctx, targets[i].GSM.SecretResource, w.value)
+		if err != nil {
+			if i > 0 {
+				fmt.Fprintf(os.Stderr, "warning: %d new versions left unreferenced; metadata keeps the previous versions\n", i)
+			}
+			return fmt.Errorf("add GSM version for %s: %w", w.keyName, err)
+		}
+		newVersions[i] = version
+	}
+
+	for i, w := range writes {
+		fmt.Printf("  Added GSM version for %s: %s\n", w.keyName, newVersions[i])
+		recordRotateAudit(metadata.ShortName, w.keyName, targets[i].GSM.Version, newVersions[i])
+		targets[i].GSM.Version = newVersions[i]
+	}
+	return nil
+}
+
+// resealRotated reseals one secret for an environment with that
+// environment's store and sealer, as reseal --env does.
+func resealRotated(ctx context.Context, cfg *config.Config, env, shortName string) (*reseal.Result, error) {
+	envCfg, err := cfg.ForEnv(env)
+	if err != nil {
+		return nil, err
+	}
+
+	secretStore, closeStore, err := resolveStore(ctx, envCfg)
+	if err != nil {
+		return nil, err
+	}
+	defer closeStore()
+
+	sealer, err := resolveSealer(envCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := reseal.NewEngine(secretStore, sealer, repoPath, dryRun)
+	engine.SetEnvironment(env)
+	digestKey, err := resolveDigestKey(ctx, envCfg, secretStore)
+	if err != nil {
+		printWarning("Stable ciphertext disabled, all keys will be re-encrypted: %v", err)
+	}
+	engine.SetDigestKey(digestKey)
+
+	result, err := engine.ResealOne(ctx, shortName)
+	if err != nil {
+		return nil, fmt.Errorf("reseal: %w", err)
+	}
+	return result, nil
+}
+
 // recordRotateState adds a rotation record to state.yaml.
 func recordRotateState(shortName, keyName string) error {
 	return withState(func(s *state.State) {
@@ -420,6 +726,19 @@ func recordRotateState(shortName, keyName string) error {
 	})
 }
 
+// recordRotateAudit records a new store version of a rotated key in the
+// audit log.
+func recordRotateAudit(shortName, keyName, oldVersion, newVersion string) {
+	recordAudit(audit.Entry{
+		Command:    "rotate",
+		ShortName:  shortName,
+		KeyName:    keyName,
+		OldVersion: oldVersion,
+		NewVersion: newVersion,
+		Reason:     rotateReason,
+	})
+}
+
 // truncateStr shortens a string to maxLen characters, adding "..." if truncated.
 // displayOperatorHints prints operator hints for manual rotation.
 // Per plan: hints content is stored in GSM, metadata only has the reference.
@@ -446,6 +765,12 @@ func serializeMetadata(m *core.SecretMetadata) string {
 
 	sb.WriteString(fmt.Sprintf("shortName: %s\n", m.ShortName))
 	sb.WriteString(fmt.Sprintf("manifestPath: %s\n", m.ManifestPath))
+	if len(m.Environments) > 0 {
+		sb.WriteString("environments:\n")
+		for _, env := range m.Environments {
+			sb.WriteString(fmt.Sprintf("  - %s\n", env))
+		}
+	}
 	sb.WriteString("sealedSecret:\n")
 	sb.WriteString(fmt.Sprintf("  name: %s\n", m.SealedSecret.Name))
 	sb.WriteString(fmt.Sprintf("  namespace: %s\n", m.SealedSecret.Namespace))
@@ -465,6 +790,14 @@ func serializeMetadata(m *core.SecretMetadata) string {
 	if m.ReplacedBy != "" {
 		sb.WriteString(fmt.Sprintf("replacedBy: %s\n", m.ReplacedBy))
 	}
+	if m.Hooks != nil {
+		sb.WriteString("hooks:\n")
+		writeHooks(&sb, "preRotate", m.Hooks.PreRotate)
+		writeHooks(&sb, "postRotate", m.Hooks.PostRotate)
+		if m.Hooks.RollbackOnFailure {
+			sb.WriteString("  rollbackOnFailure: true\n")
+		}
+	}
 
 	sb.WriteString("keys:\n")
 	for _, k := range m.Keys {
@@ -481,18 +814,83 @@ func serializeMetadata(m *core.SecretMetadata) string {
 		if k.Rotation != nil {
 			sb.WriteString("    rotation:\n")
 			sb.WriteString(fmt.Sprintf("      mode: %s\n", k.Rotation.Mode))
-			if k.Rotation.Generator != nil {
+			if g := k.Rotation.Generator; g != nil {
 				sb.WriteString("      generator:\n")
-				sb.WriteString(fmt.Sprintf("        kind: %s\n", k.Rotation.Generator.Kind))
-				if k.Rotation.Generator.Bytes > 0 {
-					sb.WriteString(fmt.Sprintf("        bytes: %d\n", k.Rotation.Generator.Bytes))
+				sb.WriteString(fmt.Sprintf("        kind: %s\n", g.Kind))
+				if g.Bytes > 0 {
+					sb.WriteString(fmt.Sprintf("        bytes: %d\n", g.Bytes))
+				}
+				if g.Length > 0 {
+					sb.WriteString(fmt.Sprintf("        length: %d\n", g.Length))
+				}
+				if len(g.Charset) > 0 {
+					sb.WriteString(fmt.Sprintf("        charset: [%s]\n", strings.Join(g.Charset, ", ")))
+				}
+				if g.Exclude != "" {
+					sb.WriteString(fmt.Sprintf("        exclude: %q\n", g.Exclude))
+				}
+				if g.Words > 0 {
+					sb.WriteString(fmt.Sprintf("        words: %d\n", g.Words))
+				}
+				if g.Separator != "" {
+					sb.WriteString(fmt.Sprintf("        separator: %q\n", g.Separator))
+				}
+				if g.Cost > 0 {
+					sb.WriteString(fmt.Sprintf("        cost: %d\n", g.Cost))
+				}
+				if g.Username != "" {
+					sb.WriteString(fmt.Sprintf("        username: %s\n", g.Username))
+				}
+				if g.Bits > 0 {
+					sb.WriteString(fmt.Sprintf("        bits: %d\n", g.Bits))
+				}
+				if g.CommonName != "" {
+					sb.WriteString(fmt.Sprintf("        commonName: %q\n", g.CommonName))
+				}
+				if len(g.SANs) > 0 {
+					sb.WriteString("        sans:\n")
+					for _, san := range g.SANs {
+						sb.WriteString(fmt.Sprintf("          - %q\n", san))
+					}
+				}
+				if g.Validity != "" {
+					sb.WriteString(fmt.Sprintf("        validity: %s\n", g.Validity))
+				}
+				if g.KeyType != "" {
+					sb.WriteString(fmt.Sprintf("        keyType: %s\n", g.KeyType))
+				}
+				if g.Issuer != nil {
+					sb.WriteString("        issuer:\n")
+					sb.WriteString("          cert:\n")
+					sb.WriteString(fmt.Sprintf("            secretResource: %s\n", g.Issuer.Cert.SecretResource))
+					sb.WriteString(fmt.Sprintf("            version: \"%s\"\n", g.Issuer.Cert.Version))
+					sb.WriteString("          key:\n")
+					sb.WriteString(fmt.Sprintf("            secretResource: %s\n", g.Issuer.Key.SecretResource))
+					sb.WriteString(fmt.Sprintf("            version: \"%s\"\n", g.Issuer.Key.Version))
+				}
+				if g.PairedKey != "" {
+					sb.WriteString(fmt.Sprintf("        pairedKey: %s\n", g.PairedKey))
+				}
+				if len(g.Outputs) > 0 {
+					sb.WriteString("        outputs:\n")
+					for _, output := range slices.Sorted(maps.Keys(g.Outputs)) {
+						sb.WriteString(fmt.Sprintf("          %s: %s\n", output, g.Outputs[output]))
+					}
 				}
 			}
+			if k.Rotation.Interval != "" {
+				sb.WriteString(fmt.Sprintf("      interval: %s\n", k.Rotation.Interval))
+			}
 		}
 
 		if k.Expiry != nil {
 			sb.WriteString("    expiry:\n")
-			sb.WriteString(fmt.Sprintf("      expiresAt: \"%s\"\n", k.Expiry.ExpiresAt))
+			if k.Expiry.ExpiresAt != "" {
+				sb.WriteString(fmt.Sprintf("      expiresAt: \"%s\"\n", k.Expiry.ExpiresAt))
+			}
+			if k.Expiry.Source != "" {
+				sb.WriteString(fmt.Sprintf("      source: %s\n", k.Expiry.Source))
+			}
 		}
 
 		if k.OperatorHints != nil && k.OperatorHints.GSM != nil {
@@ -528,3 +926,29 @@ func serializeMetadata(m *core.SecretMetadata) string {
 
 	return sb.String()
 }
+
+// writeHooks writes one phase of a metadata hooks block.
+func writeHooks(sb *strings.Builder, phase string, list []core.HookConfig) {
+	if len(list) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("  %s:\n", phase))
+	for _, h := range list {
+		prefix := "    - "
+		if h.Name != "" {
+			sb.WriteString(fmt.Sprintf("%sname: %q\n", prefix, h.Name))
+			prefix = "      "
+		}
+		if len(h.Command) > 0 {
+			sb.WriteString(fmt.Sprintf("%scommand:\n", prefix))
+			for _, arg := range h.Command {
+				sb.WriteString(fmt.Sprintf("        - %q\n", arg))
+			}
+		} else {
+			sb.WriteString(fmt.Sprintf("%surl: %q\n", prefix, h.URL))
+		}
+		if h.Timeout != "" {
+			sb.WriteString(fmt.Sprintf("      timeout: %s\n", h.Timeout))
+		}
+	}
+}
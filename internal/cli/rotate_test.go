@@ -0,0 +1,316 @@
+package cli
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/state"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+func pairedMetadata() *core.SecretMetadata {
+	return &core.SecretMetadata{
+		ShortName:    "app",
+		ManifestPath: "apps/app/sealed-secret.yaml",
+		SealedSecret: core.SealedSecretRef{Name: "app", Namespace: "default", Scope: "strict"},
+		Keys: []core.KeyMetadata{
+			{
+				KeyName: "admin_password",
+				Source:  core.SourceConfig{Kind: "gsm"},
+				GSM:     &core.GSMRef{SecretResource: "projects/p/secrets/app-admin_password", Version: "1"},
+				Rotation: &core.RotationConfig{
+					Mode: "generated",
+					Generator: &core.GeneratorConfig{
+						Kind:      "htpasswd",
+						Length:    24,
+						Charset:   []string{"lower", "upper", "digits"},
+						Exclude:   "0O1lI",
+						Cost:      4,
+						Username:  "admin",
+						PairedKey: "auth",
+					},
+				},
+			},
+			{
+				KeyName:  "auth",
+				Source:   core.SourceConfig{Kind: "gsm"},
+				GSM:      &core.GSMRef{SecretResource: "projects/p/secrets/app-auth", Version: "1"},
+				Rotation: &core.RotationConfig{Mode: "static"},
+			},
+		},
+	}
+}
+
+func TestSerializeMetadata_GeneratorRoundTrip(t *testing.T) {
+	m := pairedMetadata()
+	m.Keys[1].Rotation.Generator = nil
+
+	parsed, err := core.ParseMetadata([]byte(serializeMetadata(m)))
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	got := parsed.Keys[0].Rotation.Generator
+	if !reflect.DeepEqual(got, m.Keys[0].Rotation.Generator) {
+		t.Errorf("generator = %+v, want %+v", got, m.Keys[0].Rotation.Generator)
+	}
+
+	passphrase := &core.GeneratorConfig{Kind: "passphrase", Words: 5, Separator: " "}
+	m.Keys[0].Rotation.Generator = passphrase
+	parsed, err = core.ParseMetadata([]byte(serializeMetadata(m)))
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	if got := parsed.Keys[0].Rotation.Generator; !reflect.DeepEqual(got, passphrase) {
+		t.Errorf("generator = %+v, want %+v", got, passphrase)
+	}
+}
+
+func TestAddKeyVersions(t *testing.T) {
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = t.TempDir()
+
+	ctx := context.Background()
+	m := pairedMetadata()
+	fake := store.NewFakeStore()
+	fake.SetVersion("projects/p/secrets/app-admin_password", "1", []byte("old"))
+	fake.SetVersion("projects/p/secrets/app-auth", "1", []byte("admin:old"))
+
+	writes := []keyVersion{
+		{keyName: "admin_password", value: []byte("new")},
+		{keyName: "auth", value: []byte("admin:new")},
+	}
+	if err := addKeyVersions(ctx, fake, m, writes); err != nil {
+		t.Fatalf("addKeyVersions failed: %v", err)
+	}
+	for _, k := range m.Keys {
+		if k.GSM.Version != "2" {
+			t.Errorf("%s version = %q, want 2", k.KeyName, k.GSM.Version)
+		}
+	}
+	data, err := fake.AccessVersion(ctx, "projects/p/secrets/app-auth", "2")
+	if err != nil || string(data) != "admin:new" {
+		t.Errorf("stored value = %q, %v; want admin:new", data, err)
+	}
+}
+
+func TestAddKeyVersions_AllOrNothing(t *testing.T) {
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = t.TempDir()
+
+	ctx := context.Background()
+	m := pairedMetadata()
+	fake := store.NewFakeStore()
+	fake.SetVersion("projects/p/secrets/app-admin_password", "1", []byte("old"))
+	// app-auth does not exist in the store, so its AddVersion fails
+
+	writes := []keyVersion{
+		{keyName: "admin_password", value: []byte("new")},
+		{keyName: "auth", value: []byte("admin:new")},
+	}
+	if err := addKeyVersions(ctx, fake, m, writes); err == nil {
+		t.Fatal("expected error when one key fails")
+	}
+	for _, k := range m.Keys {
+		if k.GSM.Version != "1" {
+			t.Errorf("%s version = %q, want 1 (unchanged)", k.KeyName, k.GSM.Version)
+		}
+	}
+}
+
+func TestGeneratorOwner(t *testing.T) {
+	m := pairedMetadata()
+	owner, output := m.GeneratorOwner("auth")
+	if owner != "admin_password" || output != "htpasswd" {
+		t.Errorf("GeneratorOwner(auth) = %q, %q; want admin_password, htpasswd", owner, output)
+	}
+	if owner, _ := m.GeneratorOwner("admin_password"); owner != "" {
+		t.Errorf("GeneratorOwner(admin_password) = %q, want none", owner)
+	}
+}
+
+func TestSerializeMetadata_TLSGeneratorRoundTrip(t *testing.T) {
+	m := pairedMetadata()
+	m.Keys[0].KeyName = "tls.key"
+	m.Keys[0].Rotation.Generator = &core.GeneratorConfig{
+		Kind:       "tls",
+		CommonName: "*.infra.svc",
+		SANs:       []string{"*.infra.svc", "10.0.0.1"},
+		Validity:   "30d",
+		KeyType:    "rsa",
+		Bits:       2048,
+		Issuer: &core.IssuerRef{
+			Cert: &core.StoreRef{SecretResource: "projects/p/secrets/ca-crt", Version: "1"},
+			Key:  &core.StoreRef{SecretResource: "projects/p/secrets/ca-key", Version: "2"},
+		},
+		Outputs: map[string]string{"cert": "auth"},
+	}
+
+	parsed, err := core.ParseMetadata([]byte(serializeMetadata(m)))
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	if got := parsed.Keys[0].Rotation.Generator; !reflect.DeepEqual(got, m.Keys[0].Rotation.Generator) {
+		t.Errorf("generator = %+v, want %+v", got, m.Keys[0].Rotation.Generator)
+	}
+}
+
+func TestGenerateBundle_IssuerFromStore(t *testing.T) {
+	ctx := context.Background()
+	fake := store.NewFakeStore()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "internal-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKeyDER, err := x509.MarshalECPrivateKey(caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	fake.SetVersion("projects/p/secrets/ca-crt", "1", caCertPEM)
+	fake.SetVersion("projects/p/secrets/ca-key", "1", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: caKeyDER}))
+
+	gen := &core.GeneratorConfig{
+		Kind: "tls",
+		SANs: []string{"api.internal"},
+		Issuer: &core.IssuerRef{
+			Cert: &core.StoreRef{SecretResource: "projects/p/secrets/ca-crt", Version: "1"},
+			Key:  &core.StoreRef{SecretResource: "projects/p/secrets/ca-key", Version: "1"},
+		},
+	}
+	g, err := generateBundle(ctx, fake, gen)
+	if err != nil {
+		t.Fatalf("generateBundle failed: %v", err)
+	}
+	if string(g.Outputs["ca"]) != string(caCertPEM) {
+		t.Error("ca output should be the issuer certificate")
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caCertPEM)
+	block, _ := pem.Decode(g.Outputs["cert"])
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "api.internal", Roots: roots}); err != nil {
+		t.Errorf("certificate does not verify against the issuer: %v", err)
+	}
+
+	gen.Issuer.Key.Version = "2"
+	if _, err := generateBundle(ctx, fake, gen); err == nil {
+		t.Fatal("expected error for a missing issuer key version")
+	}
+}
+
+func TestSetKeyExpiry(t *testing.T) {
+	m := pairedMetadata()
+	g, err := core.Generate(&core.GeneratorConfig{Kind: "tls", SANs: []string{"a"}, Validity: "10d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(g.Outputs["cert"])
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setKeyExpiry(m, "admin_password", g.NotAfter)
+	if m.Keys[0].Expiry == nil || m.Keys[0].Expiry.ExpiresAt != cert.NotAfter.UTC().Format(time.RFC3339) {
+		t.Errorf("expiry = %+v, want certificate NotAfter %v", m.Keys[0].Expiry, cert.NotAfter)
+	}
+}
+
+func TestSerializeMetadata_HooksRoundTrip(t *testing.T) {
+	m := pairedMetadata()
+	m.Hooks = &core.HooksConfig{
+		PreRotate: []core.HookConfig{{Command: []string{"./scripts/check.sh", "--quiet"}}},
+		PostRotate: []core.HookConfig{
+			{Name: "reload", URL: "http://127.0.0.1:8080/reload", Timeout: "5s"},
+			{Command: []string{"kubectl", "rollout", "restart", "deploy/app"}},
+		},
+		RollbackOnFailure: true,
+	}
+
+	parsed, err := core.ParseMetadata([]byte(serializeMetadata(m)))
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Hooks, m.Hooks) {
+		t.Errorf("hooks = %+v, want %+v", parsed.Hooks, m.Hooks)
+	}
+}
+
+func TestRunPostRotateHooks(t *testing.T) {
+	origRepoPath := repoPath
+	defer func() { repoPath = origRepoPath }()
+	repoPath = t.TempDir()
+
+	sets := []*core.HooksConfig{
+		{PostRotate: []core.HookConfig{{Name: "env", Command: []string{"sh", "-c", `test "$WAXSEAL_KEYS" = "admin_password,auth"`}}}},
+		{PostRotate: []core.HookConfig{{Name: "verify", Command: []string{"false"}}}, RollbackOnFailure: true},
+	}
+	rotated := map[string]string{"admin_password": "2", "auth": "2"}
+
+	rollback, err := runPostRotateHooks(context.Background(), sets, "app", rotated)
+	if err == nil {
+		t.Fatal("expected the failing hook to fail the rotation")
+	}
+	if !rollback {
+		t.Error("expected a rollback: the failing hook's set has rollbackOnFailure")
+	}
+
+	s, err := state.Load(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.HookRuns) != 2 {
+		t.Fatalf("hook runs = %+v, want 2", s.HookRuns)
+	}
+	if !s.HookRuns[0].OK || s.HookRuns[0].Hook != "env" {
+		t.Errorf("first run = %+v, want env OK", s.HookRuns[0])
+	}
+	if s.HookRuns[1].OK || s.HookRuns[1].Phase != core.HookPhasePostRotate {
+		t.Errorf("second run = %+v, want a failed postRotate run", s.HookRuns[1])
+	}
+
+	// Nothing rotated, nothing to report
+	if _, err := runPostRotateHooks(context.Background(), sets, "app", map[string]string{}); err != nil {
+		t.Errorf("no rotated keys: %v", err)
+	}
+}
+
+func TestChangedVersions(t *testing.T) {
+	m := pairedMetadata()
+	before := storeVersions(m)
+	m.Keys[1].GSM.Version = "3"
+
+	got := changedVersions(before, storeVersions(m))
+	if !reflect.DeepEqual(got, map[string]string{"auth": "3"}) {
+		t.Errorf("changed = %v, want auth=3", got)
+	}
+}
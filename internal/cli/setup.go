@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,8 +10,10 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/huh"
+	"github.com/shermanhuman/waxseal/internal/cluster"
 	"github.com/shermanhuman/waxseal/internal/files"
 	"github.com/shermanhuman/waxseal/internal/gcp"
+	"github.com/shermanhuman/waxseal/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -435,6 +438,9 @@ func runSetup(cmd *cobra.Command, args []string) error {
 	controllerNS := "kube-system"
 	controllerName := "sealed-secrets"
 
+	// No config exists yet, so setup uses the kubeconfig's current context
+	kube, kubeErr := resolveCluster(nil)
+
 	// Interactive prompts for controller
 	{
 		fmt.Println()
@@ -445,7 +451,11 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		var discoveredNS, discoveredName string
 		var discoverErr error
 		err := withSpinner("Scanning cluster for SealedSecrets controller...", func() error {
-			discoveredNS, discoveredName, discoverErr = discoverController()
+			if kubeErr != nil {
+				discoverErr = kubeErr
+				return nil
+			}
+			discoveredNS, discoveredName, discoverErr = cluster.FindController(cmd.Context(), kube)
 			return nil
 		})
 		if err != nil {
@@ -457,7 +467,7 @@ func runSetup(cmd *cobra.Command, args []string) error {
 			controllerName = discoveredName
 		} else {
 			// Fallback to selection if not automatically found
-			namespaces, err := getNamespaces()
+			namespaces, err := listNamespaces(cmd.Context(), kube)
 			if err == nil && len(namespaces) > 0 {
 				var nsOption string
 				err = huh.NewSelect[string]().
@@ -535,11 +545,11 @@ func runSetup(cmd *cobra.Command, args []string) error {
 	var certOutput []byte
 	var certErr error
 	if err := withSpinner("Fetching certificate from controller...", func() error {
-		c := exec.Command("kubeseal",
-			"--controller-name="+controllerName,
-			"--controller-namespace="+controllerNS,
-			"--fetch-cert")
-		certOutput, certErr = c.Output()
+		if kubeErr != nil {
+			certErr = kubeErr
+			return nil
+		}
+		certOutput, certErr = kube.FetchCert(cmd.Context(), controllerNS, controllerName)
 		return nil
 	}); err != nil {
 		return err
@@ -548,8 +558,7 @@ func runSetup(cmd *cobra.Command, args []string) error {
 	if certErr != nil {
 		printWarning("Could not fetch certificate from cluster.")
 		fmt.Println("   This might mean:")
-		fmt.Println("   - kubeseal is not installed")
-		fmt.Println("   - kubectl is not configured")
+		fmt.Println("   - No kubeconfig, or its current context can't reach the cluster")
 		fmt.Println("   - The Sealed Secrets controller is not running")
 		fmt.Println()
 		fmt.Println("   You can fetch it manually later:")
@@ -565,6 +574,9 @@ func runSetup(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("write cert: %w", err)
 		}
 		printSuccess("Saved certificate to %s", certPath)
+		if err := recordCertHistory("", certOutput); err != nil {
+			printWarning("Could not record the certificate in %s: %v", state.CertsDir, err)
+		}
 	}
 
 	// Run discover
@@ -824,46 +836,12 @@ func confirmOverwrite() bool {
 	return overwrite
 }
 
-// discoverController tries to find the SealedSecrets controller in the cluster
-func discoverController() (string, string, error) {
-	// 1. Try to find pod by label
-	// Standard helm chart uses app.kubernetes.io/name=sealed-secrets
-	cmd := exec.Command("kubectl", "get", "pods", "-A",
-		"-l", "app.kubernetes.io/name=sealed-secrets",
-		"-o", "jsonpath={.items[0].metadata.namespace}/{.items[0].metadata.name}")
-	output, err := cmd.Output()
-	if err == nil && len(output) > 0 {
-		parts := strings.Split(string(output), "/")
-		if len(parts) >= 1 {
-			// Found namespace, now guess service name
-			// Usually: sealed-secrets-controller, sealed-secrets, or similar
-			ns := parts[0]
-			svcName := "sealed-secrets-controller" // standard default
-
-			// Verify service exists
-			check := exec.Command("kubectl", "get", "svc", "-n", ns, svcName)
-			if err := check.Run(); err != nil {
-				// Try alternate name
-				svcName = "sealed-secrets"
-				check = exec.Command("kubectl", "get", "svc", "-n", ns, svcName)
-				if err := check.Run(); err != nil {
-					return "", "", fmt.Errorf("service not found")
-				}
-			}
-			return ns, svcName, nil
-		}
-	}
-	return "", "", fmt.Errorf("controller not found")
-}
-
-// getNamespaces lists all namespaces in the cluster
-func getNamespaces() ([]string, error) {
-	cmd := exec.Command("kubectl", "get", "namespaces", "-o", "jsonpath={.items[*].metadata.name}")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+// listNamespaces lists all namespaces in the cluster.
+func listNamespaces(ctx context.Context, kube cluster.Client) ([]string, error) {
+	if kube == nil {
+		return nil, fmt.Errorf("no cluster access")
 	}
-	return strings.Split(string(output), " "), nil
+	return kube.ListNamespaces(ctx)
 }
 
 // parseReminderIntList parses a comma-separated list of integers.
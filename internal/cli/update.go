@@ -8,8 +8,12 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/huh"
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/config"
 	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/kustomize"
+	"github.com/shermanhuman/waxseal/internal/reseal"
 	"github.com/shermanhuman/waxseal/internal/seal"
 	"github.com/shermanhuman/waxseal/internal/store"
 	"github.com/spf13/cobra"
@@ -46,6 +50,7 @@ var (
 	updateGenerateRandom bool
 	updateRandomLength   int
 	updateCreateKey      bool
+	updateReason         string
 )
 
 func init() {
@@ -54,7 +59,8 @@ func init() {
 	updateCmd.Flags().BoolVar(&updateGenerateRandom, "generate-random", false, "Generate a random value")
 	updateCmd.Flags().IntVar(&updateRandomLength, "random-length", 32, "Length of generated random value (bytes)")
 	updateCmd.Flags().BoolVar(&updateCreateKey, "create", false, "Create the key if it doesn't exist")
-	addPreflightChecks(updateCmd, authNeeds{gsm: true, kubeseal: true})
+	updateCmd.Flags().StringVar(&updateReason, "reason", "", "Reason for the update, recorded in the audit log")
+	addPreflightChecks(updateCmd, authNeeds{store: true, sealer: true})
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
@@ -256,6 +262,13 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Load every targeted manifest and its sealer up front, so a missing
+	// manifest or cert fails before the store and metadata are changed
+	targets, err := loadUpdateTargets(cfg, metadata)
+	if err != nil {
+		return err
+	}
+
 	// Create new GSM version
 	gsmStore, closeStore, err := resolveStore(ctx, cfg)
 	if err != nil {
@@ -269,6 +282,18 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	}
 	printSuccess("Created new GSM version: %s", newVersion)
 
+	auditEntry := audit.Entry{
+		Command:    "updatekey",
+		ShortName:  shortName,
+		KeyName:    keyName,
+		NewVersion: newVersion,
+		Reason:     updateReason,
+	}
+	if !createNewKey {
+		auditEntry.OldVersion = keyMeta.GSM.Version
+	}
+	recordAudit(auditEntry)
+
 	// Update or create metadata for this key
 	if createNewKey {
 		// Add new key to metadata
@@ -300,56 +325,124 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("write metadata: %w", err)
 	}
 
-	// Reseal the SealedSecret
-	manifestPath := filepath.Join(repoPath, metadata.ManifestPath)
-
-	// Read existing manifest
-	existingManifest, err := os.ReadFile(manifestPath)
-	if err != nil {
-		return fmt.Errorf("read manifest: %w", err)
+	// Reseal the SealedSecret in every targeted manifest
+	var failed int
+	for _, target := range targets {
+		if err := target.seal(metadata, keyName, newValue); err != nil {
+			printError("%s: %v", target.relPath, err)
+			failed++
+			continue
+		}
+		printSuccess("Updated manifest: %s", target.relPath)
 	}
-
-	existingSS, err := seal.ParseSealedSecret(existingManifest)
-	if err != nil {
-		return fmt.Errorf("parse manifest: %w", err)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d manifests not updated; run 'waxseal reseal %s' to retry", failed, len(targets), shortName)
 	}
 
-	// Use kubeseal binary for encryption (guarantees controller compatibility)
-	sealer := resolveSealer(cfg)
+	fmt.Println()
+	printSuccess("Key %s/%s updated successfully!", shortName, keyName)
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Println("  1. Commit the updated files")
+	fmt.Println("  2. Apply to cluster or let GitOps sync")
+
+	return nil
+}
+
+// updateTarget is one manifest that updatekey reseals: the secret's manifest
+// for a single environment, with the sealer for that environment's cert.
+type updateTarget struct {
+	relPath  string
+	absPath  string
+	manifest []byte
+	located  *seal.LocatedSealedSecret
+	sealer   seal.Sealer
+}
 
-	// Seal the new value
-	scope := existingSS.GetScope()
-	encrypted, err := sealer.Seal(
+// loadUpdateTargets reads the manifest of every environment the secret
+// targets and resolves the matching sealer.
+func loadUpdateTargets(cfg *config.Config, metadata *core.SecretMetadata) ([]*updateTarget, error) {
+	var targets []*updateTarget
+	for _, env := range metadata.TargetEnvs() {
+		envCfg, err := cfg.ForEnv(env)
+		if err != nil {
+			return nil, err
+		}
+
+		relPath := metadata.ManifestPathFor(env)
+		absPath := relPath
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(repoPath, absPath)
+		}
+
+		manifest, err := os.ReadFile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("read manifest: %w", err)
+		}
+
+		transform, err := kustomize.ForFile(repoPath, absPath)
+		if err != nil {
+			return nil, fmt.Errorf("resolve kustomization: %w", err)
+		}
+		fileNamespace, fileName := transform.Unapply(metadata.SealedSecret.Namespace, metadata.SealedSecret.Name)
+		located, err := seal.FindSealedSecret(manifest, fileNamespace, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+		if located == nil {
+			return nil, fmt.Errorf("parse manifest: no SealedSecret %s/%s in %s",
+				metadata.SealedSecret.Namespace, metadata.SealedSecret.Name, relPath)
+		}
+
+		sealer, err := resolveSealer(envCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, &updateTarget{
+			relPath:  relPath,
+			absPath:  absPath,
+			manifest: manifest,
+			located:  located,
+			sealer:   sealer,
+		})
+	}
+	return targets, nil
+}
+
+// seal encrypts the new value into the target manifest, leaving any other
+// documents in the file intact.
+func (t *updateTarget) seal(metadata *core.SecretMetadata, keyName string, value []byte) error {
+	existingSS := t.located.SealedSecret
+	encrypted, err := t.sealer.Seal(
 		metadata.SealedSecret.Name,
 		metadata.SealedSecret.Namespace,
 		keyName,
-		newValue,
-		scope,
+		value,
+		existingSS.GetScope(),
 	)
 	if err != nil {
 		return fmt.Errorf("seal value: %w", err)
 	}
-
-	// Update the encrypted data
 	existingSS.Spec.EncryptedData[keyName] = encrypted
 
-	// Write updated manifest
+	// The recorded digest describes the old value; reseal must not reuse
+	// the new ciphertext on the strength of it
+	var fingerprint string
+	if fp, ok := t.sealer.(seal.Fingerprinter); ok {
+		fingerprint = fp.GetCertFingerprint()
+	}
+	reseal.ForgetDigest(existingSS, keyName, fingerprint)
+
 	updatedYAML, err := existingSS.ToYAML()
 	if err != nil {
 		return fmt.Errorf("serialize manifest: %w", err)
 	}
+	updatedYAML = files.ReplaceYAMLDocument(t.manifest, t.located.Document, updatedYAML)
 
-	if err := writer.Write(manifestPath, updatedYAML); err != nil {
+	manifestWriter := files.NewAtomicWriter(files.YAMLKindValidator("SealedSecret"))
+	if err := manifestWriter.Write(t.absPath, updatedYAML); err != nil {
 		return fmt.Errorf("write manifest: %w", err)
 	}
-	printSuccess("Updated manifest: %s", metadata.ManifestPath)
-
-	fmt.Println()
-	printSuccess("Key %s/%s updated successfully!", shortName, keyName)
-	fmt.Println()
-	fmt.Println("Next steps:")
-	fmt.Println("  1. Commit the updated files")
-	fmt.Println("  2. Apply to cluster or let GitOps sync")
-
 	return nil
 }
@@ -1,9 +1,17 @@
 package cli
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/reseal"
+	"github.com/shermanhuman/waxseal/internal/seal"
+	"github.com/shermanhuman/waxseal/internal/store"
 )
 
 // TestUpdateCommand_RandomGeneration tests random value generation.
@@ -204,3 +212,72 @@ func TestUpdateCommand_InputSources(t *testing.T) {
 		}
 	})
 }
+
+// TestUpdateTarget_ForgetsStaleDigest checks that a reseal after updatekey
+// does not keep updatekey's ciphertext on the strength of the old digest.
+func TestUpdateTarget_ForgetsStaleDigest(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	setupResealTestRepo(t, tmpDir)
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test-project/secrets/api-key", "1", []byte("v1"))
+	sealer, err := seal.NewCertSealerFromPEM(testCertPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resealOnce := func() *reseal.Result {
+		t.Helper()
+		engine := reseal.NewEngine(fakeStore, sealer, tmpDir, false)
+		engine.SetDigestKey([]byte("0123456789abcdef0123456789abcdef"))
+		result, err := engine.ResealOne(ctx, "test-secret")
+		if err != nil {
+			t.Fatalf("ResealOne failed: %v", err)
+		}
+		return result
+	}
+	resealOnce()
+
+	// updatekey seals a new value into the manifest
+	manifestPath := filepath.Join(tmpDir, "apps", "test", "sealed.yaml")
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	located, err := seal.FindSealedSecret(manifest, "default", "test-secret")
+	if err != nil || located == nil {
+		t.Fatalf("find SealedSecret: %v", err)
+	}
+	metadataData, err := os.ReadFile(filepath.Join(tmpDir, ".waxseal", "metadata", "test-secret.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := core.ParseMetadata(metadataData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := &updateTarget{absPath: manifestPath, manifest: manifest, located: located, sealer: sealer}
+	if err := target.seal(metadata, "api_key", []byte("v2")); err != nil {
+		t.Fatalf("seal failed: %v", err)
+	}
+	updated, _ := os.ReadFile(manifestPath)
+	updatedSS, err := seal.ParseSealedSecret(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updatedCiphertext := updatedSS.Spec.EncryptedData["api_key"]
+
+	// The store still resolves to v1: the manifest must be sealed again
+	result := resealOnce()
+	if result.Unchanged || result.KeysResealed != 1 {
+		t.Errorf("reseal after updatekey = %+v, want api_key resealed", result)
+	}
+	resealed, _ := os.ReadFile(manifestPath)
+	resealedSS, err := seal.ParseSealedSecret(resealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resealedSS.Spec.EncryptedData["api_key"] == updatedCiphertext {
+		t.Error("reseal reused updatekey's ciphertext for a different value")
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shermanhuman/waxseal/internal/reseal"
+	"github.com/shermanhuman/waxseal/internal/seal"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [shortName]",
+	Short: "Verify manifests decrypt to the values in GSM",
+	Long: `Verify decrypts committed SealedSecret manifests offline and checks them
+against the GSM versions pinned in metadata. No cluster is needed.
+
+Decryption uses the controller's private key from a local backup, either PEM
+or the YAML from:
+
+  kubectl get secret -n kube-system -l sealedsecrets.bitnami.com/sealed-secrets-key -o yaml
+
+Only SHA-256 digests of the values are compared; plaintext is never printed.
+Keep the backup out of the repo.
+
+Examples:
+  # Verify all active secrets
+  waxseal verify --private-key ~/backups/sealed-secrets-key.yaml
+
+  # Verify one secret
+  waxseal verify my-app-secrets --private-key key.pem
+
+  # Verify an environment with its controller's key
+  waxseal verify --env prod --private-key prod-key.yaml
+
+Exit codes:
+  0 - Every manifest matches
+  1 - Some secrets failed verification
+  2 - Every secret failed verification`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runVerify,
+}
+
+var (
+	verifyPrivateKey string
+	verifyEnv        string
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyPrivateKey, "private-key", os.Getenv("WAXSEAL_SEALING_KEY"), "Controller private key backup (PEM or Secret YAML); default $WAXSEAL_SEALING_KEY")
+	verifyCmd.Flags().StringVar(&verifyEnv, "env", "", "Verify one configured environment")
+	addPreflightChecks(verifyCmd, authNeeds{store: true})
+	addMetadataCheck(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if verifyPrivateKey == "" {
+		return fmt.Errorf("--private-key is required (or set WAXSEAL_SEALING_KEY)")
+	}
+	unsealer, err := seal.NewUnsealerFromFile(verifyPrivateKey)
+	if err != nil {
+		return fmt.Errorf("load private key: %w", err)
+	}
+
+	cfg, err := resolveConfig()
+	if err != nil {
+		return err
+	}
+	envCfg, err := cfg.ForEnv(verifyEnv)
+	if err != nil {
+		return err
+	}
+
+	secretStore, closeStore, err := resolveStore(ctx, envCfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	verifier := reseal.NewVerifier(secretStore, unsealer, repoPath)
+	verifier.SetEnvironment(envCfg.Env)
+
+	results, err := verifyResults(ctx, verifier, args)
+	if err != nil {
+		return err
+	}
+	return reportVerify(results)
+}
+
+// verifyResults verifies one secret (args[0]) or all of them.
+func verifyResults(ctx context.Context, verifier *reseal.Verifier, args []string) ([]*reseal.VerifyResult, error) {
+	if len(args) == 1 {
+		result, err := verifier.VerifyOne(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*reseal.VerifyResult{result}, nil
+	}
+	return verifier.VerifyAll(ctx)
+}
+
+func reportVerify(results []*reseal.VerifyResult) error {
+	var okCount, failCount int
+	for _, r := range results {
+		name := r.ShortName
+		if r.Env != "" {
+			name = fmt.Sprintf("%s [%s]", r.ShortName, r.Env)
+		}
+
+		if r.Error != nil {
+			printError("%s: %v", name, r.Error)
+			failCount++
+			continue
+		}
+		if r.OK() {
+			printSuccess("%s: %d keys match", name, len(r.Keys))
+			okCount++
+			continue
+		}
+
+		printError("%s: drift detected", name)
+		for _, k := range r.Keys {
+			if k.Status != reseal.KeyMatch {
+				fmt.Printf("    %s: %s\n", k.KeyName, k.Status)
+			}
+		}
+		failCount++
+	}
+
+	fmt.Printf("\nVerified %d secrets", okCount)
+	if failCount > 0 {
+		fmt.Printf(", %d failed", failCount)
+	}
+	fmt.Println()
+
+	if failCount > 0 {
+		if okCount > 0 {
+			os.Exit(1) // Partial failure
+		}
+		os.Exit(2) // Complete failure
+	}
+	return nil
+}
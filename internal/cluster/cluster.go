@@ -0,0 +1,74 @@
+// Package cluster reads what waxseal needs from a Kubernetes cluster:
+// Secrets for bootstrap and drift checks, the Sealed Secrets controller's
+// public certificate, and the pods and services used to locate the
+// controller.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Client is the interface for Kubernetes cluster access.
+// Implementations include a client-go backed client (see New) and an
+// in-memory fake for testing.
+type Client interface {
+	// GetSecret returns the decoded data of a Secret.
+	// Returns ErrNotFound if the Secret doesn't exist.
+	GetSecret(ctx context.Context, namespace, name string) (map[string][]byte, error)
+
+	// FetchCert fetches the controller's PEM certificate through the API
+	// server's service proxy, as kubeseal --fetch-cert does.
+	FetchCert(ctx context.Context, namespace, serviceName string) ([]byte, error)
+
+	// ListPods lists pods matching a label selector. An empty namespace
+	// lists across all namespaces.
+	ListPods(ctx context.Context, namespace, labelSelector string) ([]Object, error)
+
+	// ListServices lists services matching a label selector. An empty
+	// namespace lists across all namespaces.
+	ListServices(ctx context.Context, namespace, labelSelector string) ([]Object, error)
+
+	// ListNamespaces returns the names of all namespaces.
+	ListNamespaces(ctx context.Context) ([]string, error)
+}
+
+// Object identifies a namespaced Kubernetes object.
+type Object struct {
+	Namespace string
+	Name      string
+}
+
+// ControllerLabel selects Sealed Secrets controller pods installed by the
+// upstream Helm chart and manifests.
+const ControllerLabel = "app.kubernetes.io/name=sealed-secrets"
+
+// controllerServiceNames are the service names the controller is commonly
+// exposed under, in order of preference.
+var controllerServiceNames = []string{"sealed-secrets-controller", "sealed-secrets"}
+
+// FindController locates the Sealed Secrets controller: the namespace of the
+// first pod carrying ControllerLabel, and the service in that namespace that
+// fronts it.
+func FindController(ctx context.Context, c Client) (namespace, serviceName string, err error) {
+	pods, err := c.ListPods(ctx, "", ControllerLabel)
+	if err != nil {
+		return "", "", fmt.Errorf("list controller pods: %w", err)
+	}
+	if len(pods) == 0 {
+		return "", "", fmt.Errorf("controller not found: no pods labelled %s", ControllerLabel)
+	}
+	namespace = pods[0].Namespace
+
+	services, err := c.ListServices(ctx, namespace, "")
+	if err != nil {
+		return "", "", fmt.Errorf("list services in %s: %w", namespace, err)
+	}
+	for _, name := range controllerServiceNames {
+		if slices.ContainsFunc(services, func(o Object) bool { return o.Name == name }) {
+			return namespace, name, nil
+		}
+	}
+	return "", "", fmt.Errorf("controller service not found in namespace %s", namespace)
+}
@@ -0,0 +1,127 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFindController(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		services []string
+		wantSvc  string
+		wantErr  bool
+	}{
+		{name: "standard name", services: []string{"other", "sealed-secrets-controller"}, wantSvc: "sealed-secrets-controller"},
+		{name: "short name", services: []string{"sealed-secrets"}, wantSvc: "sealed-secrets"},
+		{name: "no service", services: []string{"other"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewFake()
+			c.AddPod("sealed", "sealed-secrets-abc", map[string]string{"app.kubernetes.io/name": "sealed-secrets"})
+			c.AddPod("default", "web", map[string]string{"app.kubernetes.io/name": "web"})
+			for _, svc := range tt.services {
+				c.AddService("sealed", svc, nil)
+			}
+			c.AddService("default", "sealed-secrets", nil) // wrong namespace
+
+			ns, svc, err := FindController(ctx, c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s/%s", ns, svc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindController failed: %v", err)
+			}
+			if ns != "sealed" || svc != tt.wantSvc {
+				t.Errorf("FindController = %s/%s, want sealed/%s", ns, svc, tt.wantSvc)
+			}
+		})
+	}
+}
+
+func TestFindController_NoPods(t *testing.T) {
+	if _, _, err := FindController(context.Background(), NewFake()); err == nil {
+		t.Error("expected error when no controller pod exists")
+	}
+}
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	c := NewFake()
+	c.AddSecret("prod", "db", map[string][]byte{"password": []byte("hunter2")})
+	c.SetCert("kube-system", "sealed-secrets", []byte("PEM"))
+	c.AddNamespace("empty")
+
+	data, err := c.GetSecret(ctx, "prod", "db")
+	if err != nil || string(data["password"]) != "hunter2" {
+		t.Errorf("GetSecret = %v, %v", data, err)
+	}
+	if _, err := c.GetSecret(ctx, "prod", "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	cert, err := c.FetchCert(ctx, "kube-system", "sealed-secrets")
+	if err != nil || string(cert) != "PEM" {
+		t.Errorf("FetchCert = %q, %v", cert, err)
+	}
+	if _, err := c.FetchCert(ctx, "kube-system", "other"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	namespaces, _ := c.ListNamespaces(ctx)
+	want := []string{"empty", "kube-system", "prod"}
+	if len(namespaces) != len(want) {
+		t.Fatalf("ListNamespaces = %v, want %v", namespaces, want)
+	}
+	for i := range want {
+		if namespaces[i] != want[i] {
+			t.Errorf("ListNamespaces = %v, want %v", namespaces, want)
+		}
+	}
+}
+
+func TestKubeClient(t *testing.T) {
+	ctx := context.Background()
+	k := &KubeClient{clientset: fake.NewClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "db"},
+			Data:       map[string][]byte{"password": []byte("hunter2")},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-system", Name: "sealed-secrets-0",
+			Labels: map[string]string{"app.kubernetes.io/name": "sealed-secrets"},
+		}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "sealed-secrets-controller"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+	)}
+
+	data, err := k.GetSecret(ctx, "prod", "db")
+	if err != nil || string(data["password"]) != "hunter2" {
+		t.Errorf("GetSecret = %v, %v", data, err)
+	}
+	if _, err := k.GetSecret(ctx, "prod", "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	ns, svc, err := FindController(ctx, k)
+	if err != nil || ns != "kube-system" || svc != "sealed-secrets-controller" {
+		t.Errorf("FindController = %s/%s, %v", ns, svc, err)
+	}
+
+	namespaces, err := k.ListNamespaces(ctx)
+	if err != nil || len(namespaces) != 1 || namespaces[0] != "prod" {
+		t.Errorf("ListNamespaces = %v, %v", namespaces, err)
+	}
+}
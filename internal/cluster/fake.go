@@ -0,0 +1,176 @@
+package cluster
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// Fake is an in-memory implementation of Client for testing.
+type Fake struct {
+	mu       sync.RWMutex
+	secrets  map[Object]map[string][]byte
+	certs    map[Object][]byte
+	pods     map[Object]map[string]string // labels
+	services map[Object]map[string]string // labels
+	extraNS  []string
+}
+
+// NewFake creates an empty fake cluster.
+func NewFake() *Fake {
+	return &Fake{
+		secrets:  make(map[Object]map[string][]byte),
+		certs:    make(map[Object][]byte),
+		pods:     make(map[Object]map[string]string),
+		services: make(map[Object]map[string]string),
+	}
+}
+
+// AddSecret adds a Secret with the given data.
+func (f *Fake) AddSecret(namespace, name string, data map[string][]byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secrets[Object{namespace, name}] = copyData(data)
+}
+
+// AddPod adds a pod with the given labels.
+func (f *Fake) AddPod(namespace, name string, labels map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pods[Object{namespace, name}] = labels
+}
+
+// AddService adds a service with the given labels.
+func (f *Fake) AddService(namespace, name string, labels map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.services[Object{namespace, name}] = labels
+}
+
+// SetCert sets the certificate served by a controller service, adding the
+// service if it doesn't exist.
+func (f *Fake) SetCert(namespace, serviceName string, cert []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj := Object{namespace, serviceName}
+	if _, ok := f.services[obj]; !ok {
+		f.services[obj] = nil
+	}
+	f.certs[obj] = append([]byte(nil), cert...)
+}
+
+// AddNamespace adds a namespace with no objects in it.
+func (f *Fake) AddNamespace(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.extraNS = append(f.extraNS, name)
+}
+
+// GetSecret returns the data of a Secret.
+func (f *Fake) GetSecret(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	data, ok := f.secrets[Object{namespace, name}]
+	if !ok {
+		return nil, core.WrapNotFound("secret "+namespace+"/"+name, nil)
+	}
+	return copyData(data), nil
+}
+
+// FetchCert returns the certificate set with SetCert.
+func (f *Fake) FetchCert(ctx context.Context, namespace, serviceName string) ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	cert, ok := f.certs[Object{namespace, serviceName}]
+	if !ok {
+		return nil, core.WrapNotFound("service "+namespace+"/"+serviceName, nil)
+	}
+	return append([]byte(nil), cert...), nil
+}
+
+// ListPods lists pods matching an equality-based label selector.
+func (f *Fake) ListPods(ctx context.Context, namespace, labelSelector string) ([]Object, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return listMatching(f.pods, namespace, labelSelector), nil
+}
+
+// ListServices lists services matching an equality-based label selector.
+func (f *Fake) ListServices(ctx context.Context, namespace, labelSelector string) ([]Object, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return listMatching(f.services, namespace, labelSelector), nil
+}
+
+// ListNamespaces returns every namespace that holds an object or was added
+// with AddNamespace, sorted.
+func (f *Fake) ListNamespaces(ctx context.Context) ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	seen := make(map[string]bool)
+	for _, ns := range f.extraNS {
+		seen[ns] = true
+	}
+	for obj := range f.secrets {
+		seen[obj.Namespace] = true
+	}
+	for obj := range f.pods {
+		seen[obj.Namespace] = true
+	}
+	for obj := range f.services {
+		seen[obj.Namespace] = true
+	}
+	names := make([]string, 0, len(seen))
+	for ns := range seen {
+		names = append(names, ns)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// listMatching returns the objects in namespace ("" for all) whose labels
+// match every key=value term of selector, sorted.
+func listMatching(objects map[Object]map[string]string, namespace, selector string) []Object {
+	var matched []Object
+	for obj, labels := range objects {
+		if namespace != "" && obj.Namespace != namespace {
+			continue
+		}
+		if matchesSelector(labels, selector) {
+			matched = append(matched, obj)
+		}
+	}
+	slices.SortFunc(matched, func(a, b Object) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	return matched
+}
+
+func matchesSelector(labels map[string]string, selector string) bool {
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(term, "=")
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func copyData(data map[string][]byte) map[string][]byte {
+	result := make(map[string][]byte, len(data))
+	for k, v := range data {
+		result[k] = append([]byte(nil), v...)
+	}
+	return result
+}
+
+// Compile-time check that Fake implements Client.
+var _ Client = (*Fake)(nil)
@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Options selects the kubeconfig and context to connect with.
+type Options struct {
+	// Kubeconfig is the kubeconfig file path.
+	// Default: $KUBECONFIG, then ~/.kube/config.
+	Kubeconfig string
+
+	// Context is the kubeconfig context. Default: the current context.
+	Context string
+}
+
+// KubeClient implements Client with client-go.
+type KubeClient struct {
+	clientset kubernetes.Interface
+}
+
+// New creates a client from kubeconfig, following kubectl's loading rules.
+func New(opts Options) (*KubeClient, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if opts.Kubeconfig != "" {
+		rules.ExplicitPath = opts.Kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.Context}
+
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes client: %w", err)
+	}
+	return &KubeClient{clientset: clientset}, nil
+}
+
+// GetSecret returns the decoded data of a Secret.
+func (k *KubeClient) GetSecret(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, mapError("secret "+namespace+"/"+name, err)
+	}
+	return secret.Data, nil
+}
+
+// FetchCert fetches the controller's PEM certificate through the service
+// proxy at /v1/cert.pem on the service's "http" port.
+func (k *KubeClient) FetchCert(ctx context.Context, namespace, serviceName string) ([]byte, error) {
+	cert, err := k.clientset.CoreV1().Services(namespace).
+		ProxyGet("http", serviceName, "", "/v1/cert.pem", nil).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, mapError("service "+namespace+"/"+serviceName, err)
+	}
+	return cert, nil
+}
+
+// ListPods lists pods matching a label selector.
+func (k *KubeClient) ListPods(ctx context.Context, namespace, labelSelector string) ([]Object, error) {
+	list, err := k.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, mapError("pods", err)
+	}
+	objects := make([]Object, 0, len(list.Items))
+	for _, pod := range list.Items {
+		objects = append(objects, Object{Namespace: pod.Namespace, Name: pod.Name})
+	}
+	return objects, nil
+}
+
+// ListServices lists services matching a label selector.
+func (k *KubeClient) ListServices(ctx context.Context, namespace, labelSelector string) ([]Object, error) {
+	list, err := k.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, mapError("services", err)
+	}
+	objects := make([]Object, 0, len(list.Items))
+	for _, svc := range list.Items {
+		objects = append(objects, Object{Namespace: svc.Namespace, Name: svc.Name})
+	}
+	return objects, nil
+}
+
+// ListNamespaces returns the names of all namespaces.
+func (k *KubeClient) ListNamespaces(ctx context.Context) ([]string, error) {
+	list, err := k.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, mapError("namespaces", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		names = append(names, ns.Name)
+	}
+	return names, nil
+}
+
+// mapError maps Kubernetes API errors to waxseal error types.
+func mapError(resource string, err error) error {
+	switch {
+	case apierrors.IsNotFound(err):
+		return core.WrapNotFound(resource, err)
+	case apierrors.IsForbidden(err):
+		return core.WrapPermissionDenied(resource, err)
+	case apierrors.IsUnauthorized(err):
+		return core.WrapUnauthenticated(resource, err)
+	}
+	return fmt.Errorf("%s: %w", resource, err)
+}
+
+// Compile-time check that KubeClient implements Client.
+var _ Client = (*KubeClient)(nil)
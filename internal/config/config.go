@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 
 	"github.com/shermanhuman/waxseal/internal/core"
 	"sigs.k8s.io/yaml"
@@ -19,14 +21,55 @@ type Config struct {
 	Discovery  DiscoveryConfig  `json:"discovery,omitempty"`
 	Bootstrap  BootstrapConfig  `json:"bootstrap,omitempty"`
 	Reminders  *RemindersConfig `json:"reminders,omitempty"`
+
+	// Hooks run around every rotation, before a secret's own hooks.
+	Hooks *core.HooksConfig `json:"hooks,omitempty"`
+
+	// Environments are named clusters, each with its own sealing key.
+	// See ForEnv.
+	Environments map[string]*EnvironmentConfig `json:"environments,omitempty"`
+
+	// Env is the environment this config was derived for by ForEnv
+	// ("" for the top-level profile). Not read from YAML.
+	Env string `json:"-"`
+}
+
+// EnvironmentConfig overrides the cluster-specific settings for one named
+// environment. Unset fields inherit the top-level values, except the cert
+// path, which defaults to "keys/<env>/pub-cert.pem" so environments never
+// share a sealing key by accident.
+type EnvironmentConfig struct {
+	KubeContext string            `json:"kubeContext,omitempty"`
+	ProjectID   string            `json:"projectId,omitempty"` // store project
+	Controller  *ControllerConfig `json:"controller,omitempty"`
+	Cert        *CertConfig       `json:"cert,omitempty"`
+
+	// DigestKeyVersion pins the digest key version in this environment's
+	// store. It is not inherited when the environment has its own project.
+	DigestKeyVersion string `json:"digestKeyVersion,omitempty"`
 }
 
 // StoreConfig configures the secret store backend.
 type StoreConfig struct {
-	Kind               string            `json:"kind"` // "gsm" for v1
+	Kind               string            `json:"kind"` // "gsm", "vault", "aws" or "file"
 	ProjectID          string            `json:"projectId"`
 	DefaultReplication string            `json:"defaultReplication,omitempty"` // "automatic" or "user-managed"
 	Labels             map[string]string `json:"labels,omitempty"`
+	Vault              *VaultConfig      `json:"vault,omitempty"`
+	AWS                *AWSConfig        `json:"aws,omitempty"`
+	File               *FileStoreConfig  `json:"file,omitempty"`
+
+	// DigestKeyVersion pins the version of the waxseal-digest-key store
+	// secret used for stable ciphertext. Unset disables stable ciphertext.
+	DigestKeyVersion string `json:"digestKeyVersion,omitempty"`
+}
+
+// VaultConfig configures the HashiCorp Vault KV v2 backend.
+// The token is never stored in config; it is read from $VAULT_TOKEN.
+type VaultConfig struct {
+	Address   string `json:"address,omitempty"`   // default: $VAULT_ADDR
+	Mount     string `json:"mount,omitempty"`     // KV v2 mount, default: "secret"
+	Namespace string `json:"namespace,omitempty"` // Vault Enterprise namespace
 }
 
 // ControllerConfig configures Sealed Secrets controller discovery.
@@ -40,8 +83,15 @@ type ControllerConfig struct {
 type CertConfig struct {
 	RepoCertPath         string `json:"repoCertPath,omitempty"`         // default: "keys/pub-cert.pem"
 	VerifyAgainstCluster bool   `json:"verifyAgainstCluster,omitempty"` // default: true
+	Sealer               string `json:"sealer,omitempty"`               // "native" (default) or "kubeseal"
 }
 
+// Sealer implementations selectable via cert.sealer.
+const (
+	SealerNative   = "native"   // built-in, controller-compatible encryption
+	SealerKubeseal = "kubeseal" // shell out to the kubeseal binary
+)
+
 // DiscoveryConfig configures manifest discovery.
 type DiscoveryConfig struct {
 	IncludeGlobs []string `json:"includeGlobs,omitempty"` // default: ["apps/**/*.yaml"]
@@ -62,13 +112,40 @@ type ClusterConfig struct {
 
 // RemindersConfig configures expiration reminders.
 type RemindersConfig struct {
-	Enabled            bool        `json:"enabled"`
-	Provider           string      `json:"provider,omitempty"`           // "tasks" (default), "calendar", "both", "none"
-	CalendarID         string      `json:"calendarId,omitempty"`         // For calendar provider, default: "primary"
-	TasklistID         string      `json:"tasklistId,omitempty"`         // For tasks provider, default: "@default"
-	LeadTimeDays       []int       `json:"leadTimeDays,omitempty"`       // default: [30, 7, 1]
-	EventTitleTemplate string      `json:"eventTitleTemplate,omitempty"` // default template
-	Auth               *AuthConfig `json:"auth,omitempty"`
+	Enabled            bool           `json:"enabled"`
+	Provider           string         `json:"provider,omitempty"`           // "tasks" (default), "calendar", "both", "webhook", "ics", "issues", "none"
+	CalendarID         string         `json:"calendarId,omitempty"`         // For calendar provider, default: "primary"
+	TasklistID         string         `json:"tasklistId,omitempty"`         // For tasks provider, default: "@default"
+	LeadTimeDays       []int          `json:"leadTimeDays,omitempty"`       // default: [30, 7, 1]
+	EventTitleTemplate string         `json:"eventTitleTemplate,omitempty"` // default template
+	Auth               *AuthConfig    `json:"auth,omitempty"`
+	Webhook            *WebhookConfig `json:"webhook,omitempty"` // For webhook provider
+	ICS                *ICSConfig     `json:"ics,omitempty"`     // For ics provider
+	Issues             *IssuesConfig  `json:"issues,omitempty"`  // For issues provider
+}
+
+// IssuesConfig configures the issues reminder provider.
+type IssuesConfig struct {
+	Tracker   string   `json:"tracker"`             // "github", "gitlab" or "jira"
+	Project   string   `json:"project"`             // GitHub owner/name, GitLab ID or path, Jira project key
+	URL       string   `json:"url,omitempty"`       // API base URL; required for jira
+	TokenEnv  string   `json:"tokenEnv,omitempty"`  // env var holding the API token; default per tracker
+	EmailEnv  string   `json:"emailEnv,omitempty"`  // jira: env var holding the account email, for Jira Cloud basic auth
+	IssueType string   `json:"issueType,omitempty"` // jira: default "Task"
+	Labels    []string `json:"labels,omitempty"`    // added to the "waxseal" label
+}
+
+// ICSConfig configures the ics reminder provider.
+type ICSConfig struct {
+	Path string `json:"path,omitempty"` // relative to the repo root, or "-" for stdout; default: ".waxseal/reminders.ics"
+}
+
+// WebhookConfig configures the webhook reminder provider.
+type WebhookConfig struct {
+	URL              string `json:"url,omitempty"`
+	URLEnv           string `json:"urlEnv,omitempty"`           // env var holding the URL, for URLs that embed a token
+	BodyTemplate     string `json:"bodyTemplate,omitempty"`     // Go template rendering JSON; default {"text": ...}
+	SigningSecretEnv string `json:"signingSecretEnv,omitempty"` // env var holding the HMAC signing secret
 }
 
 // AuthConfig configures authentication for reminder providers.
@@ -76,6 +153,23 @@ type AuthConfig struct {
 	Kind string `json:"kind"` // "adc" for v1
 }
 
+// AWSConfig configures the AWS Secrets Manager backend.
+// Credentials are never stored in config; they are read from the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables.
+type AWSConfig struct {
+	Region   string `json:"region,omitempty"`   // default: $AWS_REGION, then $AWS_DEFAULT_REGION
+	Endpoint string `json:"endpoint,omitempty"` // override for VPC endpoints or local stand-ins
+}
+
+// FileStoreConfig configures the encrypted local-file backend.
+// Without recipients the file is passphrase-encrypted; the passphrase is
+// read from $WAXSEAL_STORE_PASSPHRASE and never stored in config.
+type FileStoreConfig struct {
+	Path         string   `json:"path,omitempty"`         // default: ".waxseal/vault.age"
+	Recipients   []string `json:"recipients,omitempty"`   // age X25519 recipients ("age1...")
+	IdentityFile string   `json:"identityFile,omitempty"` // default: $WAXSEAL_AGE_IDENTITY; relative to the repo
+}
+
 // Load reads and parses a config file, applying defaults.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -116,8 +210,10 @@ func (c *Config) Validate() error {
 		return core.NewValidationError("store.kind", "required")
 	}
 
-	if c.Store.Kind != "gsm" {
-		return core.NewValidationError("store.kind", "must be 'gsm' (only supported backend in v1)")
+	switch c.Store.Kind {
+	case core.StoreKindGSM, core.StoreKindVault, core.StoreKindAWS, core.StoreKindFile:
+	default:
+		return core.NewValidationError("store.kind", "must be 'gsm', 'vault', 'aws' or 'file'")
 	}
 
 	if c.Store.ProjectID == "" {
@@ -130,19 +226,223 @@ func (c *Config) Validate() error {
 		return core.NewValidationError("store.defaultReplication", "must be 'automatic' or 'user-managed'")
 	}
 
+	if c.Store.Vault != nil && c.Store.Kind != core.StoreKindVault {
+		return core.NewValidationError("store.vault", "only allowed when store.kind is 'vault'")
+	}
+
+	if c.Store.AWS != nil && c.Store.Kind != core.StoreKindAWS {
+		return core.NewValidationError("store.aws", "only allowed when store.kind is 'aws'")
+	}
+
+	if c.Store.File != nil && c.Store.Kind != core.StoreKindFile {
+		return core.NewValidationError("store.file", "only allowed when store.kind is 'file'")
+	}
+
+	if err := validateSealer("cert.sealer", c.Cert.Sealer); err != nil {
+		return err
+	}
+
+	if err := validateVersion("store.digestKeyVersion", c.Store.DigestKeyVersion); err != nil {
+		return err
+	}
+
+	for name, env := range c.Environments {
+		field := "environments." + name
+		if !envNamePattern.MatchString(name) {
+			return core.NewValidationError(field, "name must be lowercase alphanumeric with dashes")
+		}
+		if env == nil {
+			return core.NewValidationError(field, "must not be empty")
+		}
+		if env.Cert != nil {
+			if err := validateSealer(field+".cert.sealer", env.Cert.Sealer); err != nil {
+				return err
+			}
+		}
+		if err := validateVersion(field+".digestKeyVersion", env.DigestKeyVersion); err != nil {
+			return err
+		}
+	}
+
 	if c.Reminders != nil && c.Reminders.Enabled {
-		if c.Reminders.Auth == nil {
+		if err := c.Reminders.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Hooks.Validate(); err != nil {
+		return core.WrapValidation("hooks", err)
+	}
+
+	return nil
+}
+
+// validate checks enabled reminders: the Google providers authenticate
+// with ADC, the webhook provider needs a URL and the issues provider a
+// tracker and project.
+func (r *RemindersConfig) validate() error {
+	switch r.Provider {
+	case "", "tasks", "calendar", "both":
+		if r.Auth == nil {
 			return core.NewValidationError("reminders.auth", "required when reminders enabled")
 		}
-		if c.Reminders.Auth.Kind != "adc" {
+		if r.Auth.Kind != "adc" {
 			return core.NewValidationError("reminders.auth.kind", "must be 'adc' (only supported in v1)")
 		}
+	case "webhook":
+		if r.Webhook == nil {
+			return core.NewValidationError("reminders.webhook", "required for the webhook provider")
+		}
+		if (r.Webhook.URL == "") == (r.Webhook.URLEnv == "") {
+			return core.NewValidationError("reminders.webhook", "exactly one of url and urlEnv required")
+		}
+	case "issues":
+		if r.Issues == nil {
+			return core.NewValidationError("reminders.issues", "required for the issues provider")
+		}
+		switch r.Issues.Tracker {
+		case "github", "gitlab":
+		case "jira":
+			if r.Issues.URL == "" {
+				return core.NewValidationError("reminders.issues.url", "required for jira")
+			}
+		default:
+			return core.NewValidationError("reminders.issues.tracker", "must be 'github', 'gitlab' or 'jira'")
+		}
+		if r.Issues.Project == "" {
+			return core.NewValidationError("reminders.issues.project", "required")
+		}
+	case "ics", "none":
+	default:
+		return core.NewValidationError("reminders.provider", "must be 'tasks', 'calendar', 'both', 'webhook', 'ics', 'issues' or 'none'")
+	}
+	return nil
+}
+
+// envNamePattern restricts environment names, which are used in file paths.
+var envNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// numericPattern matches a pinned store version.
+var numericPattern = regexp.MustCompile(`^[0-9]+$`)
+
+func validateSealer(field, sealer string) error {
+	switch sealer {
+	case "", SealerNative, SealerKubeseal:
+		return nil
+	default:
+		return core.NewValidationError(field, "must be 'native' or 'kubeseal'")
 	}
+}
 
+// validateVersion checks that an optional store version is pinned: numeric,
+// never an alias like "latest".
+func validateVersion(field, version string) error {
+	if version != "" && !numericPattern.MatchString(version) {
+		return core.NewValidationError(field, "must be a numeric version")
+	}
 	return nil
 }
 
+// EnvNames returns the configured environment names, sorted.
+func (c *Config) EnvNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForEnv returns the config for a named environment: a copy of c with the
+// environment's overrides applied. An empty name returns c itself.
+func (c *Config) ForEnv(name string) (*Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	env, ok := c.Environments[name]
+	if !ok {
+		return nil, core.NewValidationError("env", fmt.Sprintf("unknown environment %q (configured: %v)", name, c.EnvNames()))
+	}
+
+	derived := *c
+	derived.Env = name
+	derived.Environments = nil
+
+	if env.ProjectID != "" {
+		derived.Store.ProjectID = env.ProjectID
+		derived.Store.DigestKeyVersion = ""
+	}
+	if env.DigestKeyVersion != "" {
+		derived.Store.DigestKeyVersion = env.DigestKeyVersion
+	}
+	if env.KubeContext != "" {
+		derived.Bootstrap.Cluster.KubeContext = env.KubeContext
+	}
+
+	if env.Controller != nil {
+		if env.Controller.Namespace != "" {
+			derived.Controller.Namespace = env.Controller.Namespace
+		}
+		if env.Controller.ServiceName != "" {
+			derived.Controller.ServiceName = env.Controller.ServiceName
+		}
+		if env.Controller.KeySecretLabel != "" {
+			derived.Controller.KeySecretLabel = env.Controller.KeySecretLabel
+		}
+	}
+
+	derived.Cert.RepoCertPath = "keys/" + name + "/pub-cert.pem"
+	if env.Cert != nil {
+		if env.Cert.RepoCertPath != "" {
+			derived.Cert.RepoCertPath = env.Cert.RepoCertPath
+		}
+		if env.Cert.Sealer != "" {
+			derived.Cert.Sealer = env.Cert.Sealer
+		}
+		if env.Cert.VerifyAgainstCluster {
+			derived.Cert.VerifyAgainstCluster = true
+		}
+	}
+
+	return &derived, nil
+}
+
 func (c *Config) applyDefaults() {
+	// Store defaults
+	if c.Store.Kind == core.StoreKindVault {
+		if c.Store.Vault == nil {
+			c.Store.Vault = &VaultConfig{}
+		}
+		if c.Store.Vault.Address == "" {
+			c.Store.Vault.Address = os.Getenv("VAULT_ADDR")
+		}
+		if c.Store.Vault.Mount == "" {
+			c.Store.Vault.Mount = "secret"
+		}
+	}
+	if c.Store.Kind == core.StoreKindAWS {
+		if c.Store.AWS == nil {
+			c.Store.AWS = &AWSConfig{}
+		}
+		if c.Store.AWS.Region == "" {
+			c.Store.AWS.Region = os.Getenv("AWS_REGION")
+		}
+		if c.Store.AWS.Region == "" {
+			c.Store.AWS.Region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+	}
+	if c.Store.Kind == core.StoreKindFile {
+		if c.Store.File == nil {
+			c.Store.File = &FileStoreConfig{}
+		}
+		if c.Store.File.Path == "" {
+			c.Store.File.Path = ".waxseal/vault.age"
+		}
+		if c.Store.File.IdentityFile == "" {
+			c.Store.File.IdentityFile = os.Getenv("WAXSEAL_AGE_IDENTITY")
+		}
+	}
+
 	// Controller defaults
 	if c.Controller.Namespace == "" {
 		c.Controller.Namespace = "kube-system"
@@ -158,6 +458,9 @@ func (c *Config) applyDefaults() {
 	if c.Cert.RepoCertPath == "" {
 		c.Cert.RepoCertPath = "keys/pub-cert.pem"
 	}
+	if c.Cert.Sealer == "" {
+		c.Cert.Sealer = SealerNative
+	}
 	// Note: VerifyAgainstCluster defaults to false (Go zero value)
 	// The plan says default true, but we handle that at usage time
 
@@ -177,6 +480,24 @@ func (c *Config) applyDefaults() {
 		if c.Reminders.TasklistID == "" {
 			c.Reminders.TasklistID = "@default" // User's primary task list
 		}
+		if c.Reminders.Provider == "ics" {
+			if c.Reminders.ICS == nil {
+				c.Reminders.ICS = &ICSConfig{}
+			}
+			if c.Reminders.ICS.Path == "" {
+				c.Reminders.ICS.Path = ".waxseal/reminders.ics"
+			}
+		}
+		if i := c.Reminders.Issues; c.Reminders.Provider == "issues" && i != nil && i.TokenEnv == "" {
+			switch i.Tracker {
+			case "github":
+				i.TokenEnv = "GITHUB_TOKEN"
+			case "gitlab":
+				i.TokenEnv = "GITLAB_TOKEN"
+			case "jira":
+				i.TokenEnv = "JIRA_API_TOKEN"
+			}
+		}
 		if len(c.Reminders.LeadTimeDays) == 0 {
 			c.Reminders.LeadTimeDays = []int{30, 7, 1}
 		}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/shermanhuman/waxseal/internal/core"
@@ -56,6 +57,9 @@ store:
 	if cfg.Cert.RepoCertPath != "keys/pub-cert.pem" {
 		t.Errorf("cert.repoCertPath = %q, want %q", cfg.Cert.RepoCertPath, "keys/pub-cert.pem")
 	}
+	if cfg.Cert.Sealer != SealerNative {
+		t.Errorf("cert.sealer = %q, want %q", cfg.Cert.Sealer, SealerNative)
+	}
 
 	// Discovery defaults
 	if len(cfg.Discovery.IncludeGlobs) != 1 || cfg.Discovery.IncludeGlobs[0] != "apps/**/*.yaml" {
@@ -111,7 +115,7 @@ func TestParse_UnsupportedStoreKind(t *testing.T) {
 	yaml := `
 version: "1"
 store:
-  kind: vault
+  kind: azure
   projectId: my-project
 `
 	_, err := Parse([]byte(yaml))
@@ -120,6 +124,126 @@ store:
 	}
 }
 
+func TestParse_VaultStore(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: vault
+  projectId: my-project
+  vault:
+    address: https://vault.example.com:8200
+    namespace: team-a
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if cfg.Store.Vault == nil {
+		t.Fatal("store.vault should be set")
+	}
+	if cfg.Store.Vault.Address != "https://vault.example.com:8200" {
+		t.Errorf("store.vault.address = %q", cfg.Store.Vault.Address)
+	}
+	if cfg.Store.Vault.Mount != "secret" {
+		t.Errorf("store.vault.mount = %q, want %q", cfg.Store.Vault.Mount, "secret")
+	}
+	if cfg.Store.Vault.Namespace != "team-a" {
+		t.Errorf("store.vault.namespace = %q, want %q", cfg.Store.Vault.Namespace, "team-a")
+	}
+}
+
+func TestParse_VaultAddressFromEnv(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:8200")
+	yaml := `
+version: "1"
+store:
+  kind: vault
+  projectId: my-project
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if cfg.Store.Vault == nil || cfg.Store.Vault.Address != "http://127.0.0.1:8200" {
+		t.Errorf("store.vault.address should default to $VAULT_ADDR, got %+v", cfg.Store.Vault)
+	}
+}
+
+func TestParse_VaultConfigRequiresVaultKind(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+  vault:
+    address: https://vault.example.com:8200
+`
+	_, err := Parse([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected error for store.vault with kind gsm")
+	}
+}
+
+func TestParse_AWSStore(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	yaml := `
+version: "1"
+store:
+  kind: aws
+  projectId: my-project
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if cfg.Store.AWS == nil || cfg.Store.AWS.Region != "eu-west-1" {
+		t.Errorf("store.aws.region should default to $AWS_DEFAULT_REGION, got %+v", cfg.Store.AWS)
+	}
+}
+
+func TestParse_AWSConfigRequiresAWSKind(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+  aws:
+    region: us-east-1
+`
+	_, err := Parse([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected error for store.aws with kind gsm")
+	}
+}
+
+func TestParse_FileStore(t *testing.T) {
+	t.Setenv("WAXSEAL_AGE_IDENTITY", "/home/me/.config/waxseal/key.txt")
+	yaml := `
+version: "1"
+store:
+  kind: file
+  projectId: homelab
+  file:
+    recipients:
+      - age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if cfg.Store.File.Path != ".waxseal/vault.age" {
+		t.Errorf("store.file.path = %q, want %q", cfg.Store.File.Path, ".waxseal/vault.age")
+	}
+	if cfg.Store.File.IdentityFile != "/home/me/.config/waxseal/key.txt" {
+		t.Errorf("store.file.identityFile should default to $WAXSEAL_AGE_IDENTITY, got %q", cfg.Store.File.IdentityFile)
+	}
+}
+
 func TestParse_MissingProjectID(t *testing.T) {
 	yaml := `
 version: "1"
@@ -146,6 +270,36 @@ store:
 	}
 }
 
+func TestParse_InvalidSealer(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+cert:
+  sealer: openssl
+`
+	_, err := Parse([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected error for invalid sealer")
+	}
+}
+
+func TestParse_DigestKeyVersion(t *testing.T) {
+	for _, version := range []string{"latest", "1.0", "-1"} {
+		yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+  digestKeyVersion: "` + version + `"
+`
+		if _, err := Parse([]byte(yaml)); err == nil {
+			t.Errorf("expected error for digestKeyVersion %q", version)
+		}
+	}
+}
+
 func TestParse_RemindersWithAuth(t *testing.T) {
 	yaml := `
 version: "1"
@@ -191,6 +345,107 @@ reminders:
 	}
 }
 
+func TestParse_RemindersWebhook(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+reminders:
+  enabled: true
+  provider: webhook
+  webhook:
+    urlEnv: SLACK_WEBHOOK_URL
+    signingSecretEnv: WAXSEAL_WEBHOOK_SECRET
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cfg.Reminders.Webhook.URLEnv != "SLACK_WEBHOOK_URL" {
+		t.Errorf("reminders.webhook.urlEnv = %q", cfg.Reminders.Webhook.URLEnv)
+	}
+
+	for name, webhook := range map[string]string{
+		"missing":  "",
+		"no url":   "  webhook:\n    bodyTemplate: '{}'\n",
+		"both url": "  webhook:\n    url: https://hooks.example.com/x\n    urlEnv: URL\n",
+	} {
+		bad := strings.SplitAfter(yaml, "provider: webhook\n")[0] + webhook
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParse_RemindersIssues(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+reminders:
+  enabled: true
+  provider: issues
+  issues:
+    tracker: gitlab
+    project: platform/infra
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cfg.Reminders.Issues.TokenEnv != "GITLAB_TOKEN" {
+		t.Errorf("reminders.issues.tokenEnv = %q, want default GITLAB_TOKEN", cfg.Reminders.Issues.TokenEnv)
+	}
+
+	for name, issues := range map[string]string{
+		"missing":     "",
+		"bad tracker": "  issues:\n    tracker: trello\n    project: x\n",
+		"no project":  "  issues:\n    tracker: github\n",
+		"jira no url": "  issues:\n    tracker: jira\n    project: OPS\n",
+	} {
+		bad := strings.SplitAfter(yaml, "provider: issues\n")[0] + issues
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParse_RemindersICSDefaultPath(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+reminders:
+  enabled: true
+  provider: ics
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cfg.Reminders.ICS == nil || cfg.Reminders.ICS.Path != ".waxseal/reminders.ics" {
+		t.Errorf("reminders.ics = %+v, want the default path", cfg.Reminders.ICS)
+	}
+}
+
+func TestParse_RemindersUnknownProvider(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+reminders:
+  enabled: true
+  provider: pager
+`
+	if _, err := Parse([]byte(yaml)); err == nil {
+		t.Fatal("expected error for an unknown provider")
+	}
+}
+
 func TestLoad_FileNotFound(t *testing.T) {
 	_, err := Load("/nonexistent/path/config.yaml")
 	if err == nil {
@@ -224,3 +479,101 @@ store:
 		t.Errorf("store.projectId = %q, want %q", cfg.Store.ProjectID, "my-project")
 	}
 }
+
+func TestForEnv(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+  digestKeyVersion: "2"
+controller:
+  namespace: kube-system
+  serviceName: sealed-secrets
+environments:
+  prod:
+    kubeContext: prod-cluster
+    projectId: prod-project
+    controller:
+      namespace: sealed-secrets
+  staging:
+    cert:
+      repoCertPath: certs/staging.pem
+      sealer: kubeseal
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if got := cfg.EnvNames(); len(got) != 2 || got[0] != "prod" || got[1] != "staging" {
+		t.Errorf("EnvNames() = %v, want [prod staging]", got)
+	}
+
+	top, err := cfg.ForEnv("")
+	if err != nil || top != cfg {
+		t.Errorf("ForEnv(\"\") should return the top-level config")
+	}
+
+	prod, err := cfg.ForEnv("prod")
+	if err != nil {
+		t.Fatalf("ForEnv(prod) failed: %v", err)
+	}
+	if prod.Env != "prod" {
+		t.Errorf("Env = %q, want %q", prod.Env, "prod")
+	}
+	if prod.Store.ProjectID != "prod-project" {
+		t.Errorf("store.projectId = %q, want %q", prod.Store.ProjectID, "prod-project")
+	}
+	if prod.Bootstrap.Cluster.KubeContext != "prod-cluster" {
+		t.Errorf("kubeContext = %q, want %q", prod.Bootstrap.Cluster.KubeContext, "prod-cluster")
+	}
+	if prod.Controller.Namespace != "sealed-secrets" || prod.Controller.ServiceName != "sealed-secrets" {
+		t.Errorf("controller = %+v, want overridden namespace and inherited service", prod.Controller)
+	}
+	if prod.Cert.RepoCertPath != "keys/prod/pub-cert.pem" {
+		t.Errorf("cert path = %q, want per-env default", prod.Cert.RepoCertPath)
+	}
+	if prod.Store.DigestKeyVersion != "" {
+		t.Errorf("digestKeyVersion = %q, want none for a separate project", prod.Store.DigestKeyVersion)
+	}
+	if prod.Cert.Sealer != SealerNative {
+		t.Errorf("sealer = %q, want inherited %q", prod.Cert.Sealer, SealerNative)
+	}
+
+	staging, err := cfg.ForEnv("staging")
+	if err != nil {
+		t.Fatalf("ForEnv(staging) failed: %v", err)
+	}
+	if staging.Cert.RepoCertPath != "certs/staging.pem" || staging.Cert.Sealer != SealerKubeseal {
+		t.Errorf("cert = %+v, want staging overrides", staging.Cert)
+	}
+	if staging.Store.ProjectID != "my-project" || staging.Store.DigestKeyVersion != "2" {
+		t.Errorf("store = %+v, want inherited project and digest key", staging.Store)
+	}
+
+	// The top-level profile is unchanged
+	if cfg.Store.ProjectID != "my-project" || cfg.Cert.RepoCertPath != "keys/pub-cert.pem" {
+		t.Error("ForEnv must not modify the top-level config")
+	}
+
+	if _, err := cfg.ForEnv("dev"); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+}
+
+func TestParse_InvalidEnvironmentName(t *testing.T) {
+	yaml := `
+version: "1"
+store:
+  kind: gsm
+  projectId: my-project
+environments:
+  Prod_1:
+    kubeContext: prod
+`
+	_, err := Parse([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected error for invalid environment name")
+	}
+}
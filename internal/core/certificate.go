@@ -0,0 +1,204 @@
+package core
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"time"
+)
+
+// Certificate defaults.
+const (
+	DefaultCertValidity = "90d"
+	DefaultCertKeyType  = "ecdsa"
+)
+
+// certKeyTypes are the supported private key types of issued certificates.
+var certKeyTypes = []string{"ecdsa", "rsa", "ed25519"}
+
+// Issuer is a CA that signs certificates: its certificate and private key.
+type Issuer struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	Key     crypto.Signer
+}
+
+// ParseIssuer parses a PEM CA certificate and its PEM private key (PKCS#8,
+// PKCS#1 or SEC 1) and checks that they belong together.
+func ParseIssuer(certPEM, keyPEM []byte) (*Issuer, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil || certBlock.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("issuer certificate is not a PEM certificate")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse issuer certificate: %w", err)
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("issuer certificate %q is not a CA", cert.Subject.CommonName)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("issuer key is not PEM")
+	}
+	var key any
+	switch keyBlock.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(keyBlock.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse issuer key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("issuer key of type %T cannot sign", key)
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(cert.PublicKey) {
+		return nil, fmt.Errorf("issuer key does not match the issuer certificate")
+	}
+
+	return &Issuer{
+		Cert:    cert,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		Key:     signer,
+	}, nil
+}
+
+// IssueCertificate issues a TLS certificate for a "tls" generator: a new
+// private key (the generated Value) and a certificate for CommonName and
+// SANs, valid from now for Validity. The certificate is signed by issuer,
+// or self-signed when issuer is nil.
+//
+// Outputs: "cert" is the certificate PEM; "ca" is the issuer's certificate
+// PEM (the certificate itself when self-signed). NotAfter is set.
+func IssueCertificate(gen *GeneratorConfig, issuer *Issuer, now time.Time) (*Generated, error) {
+	validity := gen.Validity
+	if validity == "" {
+		validity = DefaultCertValidity
+	}
+	lifetime, err := ParseInterval(validity)
+	if err != nil {
+		return nil, fmt.Errorf("validity: %w", err)
+	}
+
+	now = now.UTC().Truncate(time.Second) // certificates carry whole seconds
+
+	priv, err := generateCertKey(gen)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: gen.CommonName},
+		NotBefore:    now.Add(-5 * time.Minute), // tolerate clock skew
+		NotAfter:     now.Add(lifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if _, ok := priv.(*rsa.PrivateKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	for _, san := range gen.SANs {
+		if ip := net.ParseIP(san); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, san)
+		}
+	}
+
+	parent, signer := template, crypto.Signer(priv)
+	if issuer != nil {
+		parent, signer = issuer.Cert, issuer.Key
+		if template.NotAfter.After(issuer.Cert.NotAfter) {
+			return nil, fmt.Errorf("certificate would outlive its issuer (issuer expires %s)", issuer.Cert.NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, priv.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate: %w", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	caPEM := certPEM
+	if issuer != nil {
+		caPEM = issuer.CertPEM
+	}
+	return &Generated{
+		Value:    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		Outputs:  map[string][]byte{"cert": certPEM, "ca": caPEM},
+		NotAfter: template.NotAfter,
+	}, nil
+}
+
+// generateCertKey generates the private key of a certificate by KeyType.
+func generateCertKey(gen *GeneratorConfig) (crypto.Signer, error) {
+	switch gen.KeyType {
+	case "", "ecdsa":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "rsa":
+		bits := gen.Bits
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+		return rsa.GenerateKey(rand.Reader, bits)
+	case "ed25519":
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", gen.KeyType)
+	}
+}
+
+// validateCertificate checks the parameters of a "tls" generator.
+func (g *GeneratorConfig) validateCertificate() error {
+	if g.CommonName == "" && len(g.SANs) == 0 {
+		return NewValidationError("rotation.generator.sans", "commonName or sans is required for tls")
+	}
+	if g.Validity != "" {
+		if _, err := ParseInterval(g.Validity); err != nil {
+			return NewValidationError("rotation.generator.validity", err.Error())
+		}
+	}
+	if g.KeyType != "" && !slices.Contains(certKeyTypes, g.KeyType) {
+		return NewValidationError("rotation.generator.keyType", "must be 'ecdsa', 'rsa', or 'ed25519'")
+	}
+	if g.Issuer != nil {
+		if g.Issuer.Cert == nil || g.Issuer.Key == nil {
+			return NewValidationError("rotation.generator.issuer", "cert and key are required")
+		}
+		if err := g.Issuer.Cert.Validate(); err != nil {
+			return WrapValidation("rotation.generator.issuer.cert", err)
+		}
+		if err := g.Issuer.Key.Validate(); err != nil {
+			return WrapValidation("rotation.generator.issuer.key", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestCA returns a PEM CA certificate and PKCS#8 key valid for a year.
+func newTestCA(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func parseCertPEM(t *testing.T, data []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("not PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return cert
+}
+
+func TestGenerate_TLSSelfSigned(t *testing.T) {
+	gen := &GeneratorConfig{
+		Kind:       "tls",
+		CommonName: "webhook.infra.svc",
+		SANs:       []string{"webhook.infra.svc", "10.0.0.1"},
+		Validity:   "30d",
+	}
+	g, err := Generate(gen)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	cert := parseCertPEM(t, g.Outputs["cert"])
+	if cert.Subject.CommonName != "webhook.infra.svc" {
+		t.Errorf("CN = %q", cert.Subject.CommonName)
+	}
+	if len(cert.DNSNames) != 1 || len(cert.IPAddresses) != 1 {
+		t.Errorf("DNSNames = %v, IPAddresses = %v", cert.DNSNames, cert.IPAddresses)
+	}
+	if !g.NotAfter.Equal(cert.NotAfter) {
+		t.Errorf("NotAfter = %v, certificate says %v", g.NotAfter, cert.NotAfter)
+	}
+	if d := time.Until(cert.NotAfter); d < 29*24*time.Hour || d > 31*24*time.Hour {
+		t.Errorf("certificate valid for %v, want ~30d", d)
+	}
+	if string(g.Outputs["ca"]) != string(g.Outputs["cert"]) {
+		t.Error("self-signed ca output should be the certificate itself")
+	}
+
+	keyBlock, _ := pem.Decode(g.Value)
+	if keyBlock == nil {
+		t.Fatal("private key is not PEM")
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	if !priv.(*ecdsa.PrivateKey).PublicKey.Equal(cert.PublicKey) {
+		t.Error("private key does not match the certificate")
+	}
+}
+
+func TestIssueCertificate_SignedByIssuer(t *testing.T) {
+	caCert, caKey := newTestCA(t)
+	issuer, err := ParseIssuer(caCert, caKey)
+	if err != nil {
+		t.Fatalf("ParseIssuer failed: %v", err)
+	}
+
+	gen := &GeneratorConfig{Kind: "tls", SANs: []string{"api.internal"}, KeyType: "rsa", Bits: 2048}
+	g, err := IssueCertificate(gen, issuer, time.Now())
+	if err != nil {
+		t.Fatalf("IssueCertificate failed: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(g.Outputs["ca"])
+	cert := parseCertPEM(t, g.Outputs["cert"])
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "api.internal", Roots: roots}); err != nil {
+		t.Errorf("certificate does not verify against the issuer: %v", err)
+	}
+}
+
+func TestIssueCertificate_OutlivesIssuer(t *testing.T) {
+	caCert, caKey := newTestCA(t)
+	issuer, err := ParseIssuer(caCert, caKey)
+	if err != nil {
+		t.Fatalf("ParseIssuer failed: %v", err)
+	}
+	gen := &GeneratorConfig{Kind: "tls", SANs: []string{"api.internal"}, Validity: "730d"}
+	if _, err := IssueCertificate(gen, issuer, time.Now()); err == nil {
+		t.Error("expected error for a certificate outliving its issuer")
+	}
+}
+
+func TestParseIssuer_Errors(t *testing.T) {
+	caCert, caKey := newTestCA(t)
+	_, otherKey := newTestCA(t)
+
+	if _, err := ParseIssuer(caCert, otherKey); err == nil {
+		t.Error("expected error for a key that does not match the certificate")
+	}
+	if _, err := ParseIssuer(caKey, caKey); err == nil {
+		t.Error("expected error for a key given as certificate")
+	}
+
+	leaf, err := Generate(&GeneratorConfig{Kind: "tls", SANs: []string{"leaf"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseIssuer(leaf.Outputs["cert"], leaf.Value); err == nil {
+		t.Error("expected error for a non-CA certificate")
+	}
+}
+
+func TestGenerate_TLSWithIssuerNeedsStore(t *testing.T) {
+	gen := &GeneratorConfig{
+		Kind: "tls",
+		SANs: []string{"api.internal"},
+		Issuer: &IssuerRef{
+			Cert: &StoreRef{SecretResource: "ca-crt", Version: "1"},
+			Key:  &StoreRef{SecretResource: "ca-key", Version: "1"},
+		},
+	}
+	if _, err := Generate(gen); err == nil {
+		t.Error("expected error: issuer certificate and key are not available to Generate")
+	}
+}
+
+func TestGeneratorConfig_ValidateTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		gen     GeneratorConfig
+		wantErr bool
+	}{
+		{"sans", GeneratorConfig{Kind: "tls", SANs: []string{"a.example"}}, false},
+		{"common name only", GeneratorConfig{Kind: "tls", CommonName: "a"}, false},
+		{"no names", GeneratorConfig{Kind: "tls"}, true},
+		{"bad validity", GeneratorConfig{Kind: "tls", CommonName: "a", Validity: "soon"}, true},
+		{"bad key type", GeneratorConfig{Kind: "tls", CommonName: "a", KeyType: "dsa"}, true},
+		{"incomplete issuer", GeneratorConfig{Kind: "tls", CommonName: "a", Issuer: &IssuerRef{Cert: &StoreRef{SecretResource: "c", Version: "1"}}}, true},
+		{"issuer alias version", GeneratorConfig{Kind: "tls", CommonName: "a", Issuer: &IssuerRef{
+			Cert: &StoreRef{SecretResource: "c", Version: "latest"},
+			Key:  &StoreRef{SecretResource: "k", Version: "1"},
+		}}, true},
+		{"outputs", GeneratorConfig{Kind: "tls", CommonName: "a", Outputs: map[string]string{"cert": "tls.crt", "ca": "ca.crt"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.gen.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
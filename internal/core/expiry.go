@@ -0,0 +1,95 @@
+package core
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Expiry sources.
+const (
+	ExpirySourceManual  = "manual"
+	ExpirySourceDerived = "derived"
+
+	// expirySourceCertNotAfter is the name earlier documentation used for
+	// a certificate's expiry; it is read as derived.
+	expirySourceCertNotAfter = "cert-notAfter"
+)
+
+// IsDerived reports whether the expiry is read from the key's value.
+func (e *ExpiryConfig) IsDerived() bool {
+	return e != nil && (e.Source == ExpirySourceDerived || e.Source == expirySourceCertNotAfter)
+}
+
+// DeriveExpiry reads the expiry date from a secret value:
+//   - PEM: the earliest NotAfter of its certificates (a bundle expires with
+//     its first certificate)
+//   - DER: the certificate's NotAfter
+//   - JWT: the "exp" claim
+//
+// Returns an error if the value is none of these or carries no expiry.
+func DeriveExpiry(value []byte) (time.Time, error) {
+	trimmed := strings.TrimSpace(string(value))
+
+	if strings.HasPrefix(trimmed, "-----BEGIN ") {
+		return pemExpiry([]byte(trimmed))
+	}
+	if cert, err := x509.ParseCertificate(value); err == nil {
+		return cert.NotAfter, nil
+	}
+	if strings.Count(trimmed, ".") == 2 {
+		return jwtExpiry(trimmed)
+	}
+	return time.Time{}, fmt.Errorf("value is not a certificate, PEM bundle or JWT")
+}
+
+func pemExpiry(data []byte) (time.Time, error) {
+	var earliest time.Time
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse certificate: %w", err)
+		}
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	if earliest.IsZero() {
+		return time.Time{}, fmt.Errorf("PEM value contains no certificate")
+	}
+	return earliest, nil
+}
+
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("decode JWT payload: %w", err)
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("parse JWT claims: %w", err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, fmt.Errorf("JWT has no exp claim")
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("JWT exp claim: %w", err)
+	}
+	return time.Unix(int64(exp), 0).UTC(), nil
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeriveExpiry_Certificate(t *testing.T) {
+	short, err := Generate(&GeneratorConfig{Kind: "tls", CommonName: "short", Validity: "10d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	long, err := Generate(&GeneratorConfig{Kind: "tls", CommonName: "long", Validity: "100d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DeriveExpiry(long.Outputs["cert"])
+	if err != nil || !got.Equal(long.NotAfter) {
+		t.Errorf("PEM certificate: got %v, %v; want %v", got, err, long.NotAfter)
+	}
+
+	// A bundle (key + chain, in any order) expires with its first certificate
+	bundle := append(append(append([]byte{}, long.Value...), long.Outputs["cert"]...), short.Outputs["cert"]...)
+	got, err = DeriveExpiry(bundle)
+	if err != nil || !got.Equal(short.NotAfter) {
+		t.Errorf("PEM bundle: got %v, %v; want %v", got, err, short.NotAfter)
+	}
+
+	block, _ := pem.Decode(short.Outputs["cert"])
+	got, err = DeriveExpiry(block.Bytes)
+	if err != nil || !got.Equal(short.NotAfter) {
+		t.Errorf("DER certificate: got %v, %v; want %v", got, err, short.NotAfter)
+	}
+
+	if _, err := DeriveExpiry(long.Value); err == nil {
+		t.Error("expected error for a PEM private key without certificate")
+	}
+}
+
+func TestDeriveExpiry_JWT(t *testing.T) {
+	enc := base64.RawURLEncoding.EncodeToString
+	header := enc([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+	token := header + "." + enc([]byte(`{"sub":"ci","exp":1893456000}`)) + ".c2ln"
+	got, err := DeriveExpiry([]byte(token + "\n"))
+	if err != nil {
+		t.Fatalf("DeriveExpiry failed: %v", err)
+	}
+	if want := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("exp = %v, want %v", got, want)
+	}
+
+	noExp := header + "." + enc([]byte(`{"sub":"ci"}`)) + ".c2ln"
+	if _, err := DeriveExpiry([]byte(noExp)); err == nil || !strings.Contains(err.Error(), "exp") {
+		t.Errorf("expected missing exp error, got %v", err)
+	}
+}
+
+func TestDeriveExpiry_NoExpiry(t *testing.T) {
+	for _, value := range []string{"hunter2", "a.b", ""} {
+		if _, err := DeriveExpiry([]byte(value)); err == nil {
+			t.Errorf("DeriveExpiry(%q) expected error", value)
+		}
+	}
+}
+
+func TestExpiryConfig_ValidateSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		expiry  ExpiryConfig
+		wantErr bool
+	}{
+		{"manual", ExpiryConfig{ExpiresAt: "2030-01-01T00:00:00Z"}, false},
+		{"manual without date", ExpiryConfig{}, true},
+		{"derived without date", ExpiryConfig{Source: "derived"}, false},
+		{"derived with date", ExpiryConfig{Source: "derived", ExpiresAt: "2030-01-01T00:00:00Z"}, false},
+		{"derived with bad date", ExpiryConfig{Source: "derived", ExpiresAt: "soon"}, true},
+		{"cert-notAfter alias", ExpiryConfig{Source: "cert-notAfter"}, false},
+		{"unknown source", ExpiryConfig{Source: "calendar", ExpiresAt: "2030-01-01T00:00:00Z"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.expiry.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
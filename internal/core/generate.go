@@ -1,45 +1,518 @@
 package core
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	_ "embed"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+// Generator defaults.
+const (
+	DefaultGeneratorBytes  = 32
+	DefaultPasswordLength  = 32
+	DefaultPassphraseWords = 6
+	DefaultPassphraseSep   = "-"
+	DefaultRSABits         = 3072
 )
 
-// GenerateValue produces a random value encoded according to gen.Kind.
+// bcryptMaxLength is the longest password bcrypt accepts, in bytes. Password
+// characters are ASCII, so it also caps the length in characters.
+const bcryptMaxLength = 72
+
+// passwordClasses are the character classes a password charset is built from.
+var passwordClasses = map[string]string{
+	"lower":   "abcdefghijklmnopqrstuvwxyz",
+	"upper":   "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"digits":  "0123456789",
+	"symbols": "!#$%&()*+,-./:;<=>?@[]^_{|}~",
+}
+
+// defaultPasswordCharset is used when a password generator sets no charset.
+var defaultPasswordCharset = []string{"lower", "upper", "digits"}
+
+// generatorKinds lists every supported generator kind.
+var generatorKinds = []string{
+	"randomBase64", "randomBase64URL", "randomHex", "randomBytes",
+	"password", "passphrase", "uuid", "bcrypt", "htpasswd",
+	"ed25519", "sshEd25519", "rsa", "tls",
+}
+
+// generatorOutputs lists, per kind, the named values a generator produces
+// besides the value of the key that owns it. GeneratorConfig.Outputs maps
+// them onto other keys of the secret.
+var generatorOutputs = map[string][]string{
+	"bcrypt":     {"hash"},
+	"htpasswd":   {"htpasswd"},
+	"ed25519":    {"publicKey"},
+	"sshEd25519": {"publicKey"},
+	"rsa":        {"publicKey"},
+	"tls":        {"cert", "ca"},
+}
+
+//go:embed wordlist.txt
+var wordlistText string
+
+// wordlist is the BIP39 English word list, used for passphrases.
+var wordlist = strings.Fields(wordlistText)
+
+// Generated is the output of a generator.
+type Generated struct {
+	// Value is the value of the key that owns the generator: the password,
+	// the private key, or the random value.
+	Value []byte
+
+	// Outputs are the other values of the bundle by output name, e.g. the
+	// "hash" of a bcrypt password or the "publicKey" of a keypair.
+	// Nil for kinds that produce a single value.
+	Outputs map[string][]byte
+
+	// NotAfter is when the generated value expires (the certificate of
+	// "tls"). Zero for values that do not expire.
+	NotAfter time.Time
+}
+
+// GenerateValue produces a value according to gen.Kind and returns the
+// key's own value. See Generate for kinds that produce several outputs.
+func GenerateValue(gen *GeneratorConfig) ([]byte, error) {
+	g, err := Generate(gen)
+	if err != nil {
+		return nil, err
+	}
+	return g.Value, nil
+}
+
+// Generate produces a value according to gen.Kind.
 //
-// Supported kinds:
-//   - "randomBase64": base64-encoded random bytes
-//   - "randomHex":    hex-encoded random bytes
-//   - "randomBytes":  raw random bytes
+// Supported kinds (outputs besides Value in brackets):
+//   - "randomBase64":    base64-encoded random bytes (Bytes, default 32)
+//   - "randomBase64URL": unpadded base64url random bytes, e.g. JWT HMAC secrets
+//   - "randomHex":       hex-encoded random bytes
+//   - "randomBytes":     raw random bytes
+//   - "password":        Length characters from the Charset classes, minus Exclude
+//   - "passphrase":      Words random words joined by Separator
+//   - "uuid":            a random (version 4) UUID
+//   - "bcrypt":          a password [hash: its bcrypt hash]
+//   - "htpasswd":        a password [htpasswd: a "Username:bcrypt-hash" line]
+//   - "ed25519":         a PKCS#8 PEM private key [publicKey: PKIX PEM]
+//   - "sshEd25519":      an OpenSSH private key [publicKey: authorized_keys line]
+//   - "rsa":             a PKCS#8 PEM RSA private key of Bits [publicKey: PKIX PEM]
+//   - "tls":             a self-signed certificate's private key [cert, ca]; see IssueCertificate
 //
-// If gen.Bytes is 0, defaults to 32 bytes of randomness.
-func GenerateValue(gen *GeneratorConfig) ([]byte, error) {
+// A "tls" generator with an Issuer needs the issuer's certificate and key
+// from the store; call IssueCertificate for it instead.
+func Generate(gen *GeneratorConfig) (*Generated, error) {
 	if gen == nil {
 		return nil, fmt.Errorf("no generator config")
 	}
 
-	byteCount := gen.Bytes
-	if byteCount == 0 {
-		byteCount = 32 // Default to 32 bytes
-	}
+	switch gen.Kind {
+	case "randomBase64", "randomBase64URL", "randomHex", "randomBytes":
+		byteCount := gen.Bytes
+		if byteCount == 0 {
+			byteCount = DefaultGeneratorBytes
+		}
+		randomBytes := make([]byte, byteCount)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, fmt.Errorf("read random: %w", err)
+		}
+		switch gen.Kind {
+		case "randomBase64":
+			return &Generated{Value: []byte(base64.StdEncoding.EncodeToString(randomBytes))}, nil
+		case "randomBase64URL":
+			return &Generated{Value: []byte(base64.RawURLEncoding.EncodeToString(randomBytes))}, nil
+		case "randomHex":
+			return &Generated{Value: []byte(hex.EncodeToString(randomBytes))}, nil
+		default:
+			return &Generated{Value: randomBytes}, nil
+		}
 
-	// Generate random bytes
-	randomBytes := make([]byte, byteCount)
-	if _, err := rand.Read(randomBytes); err != nil {
-		return nil, fmt.Errorf("read random: %w", err)
-	}
+	case "password":
+		password, err := generatePassword(gen)
+		if err != nil {
+			return nil, err
+		}
+		return &Generated{Value: password}, nil
+
+	case "passphrase":
+		passphrase, err := generatePassphrase(gen)
+		if err != nil {
+			return nil, err
+		}
+		return &Generated{Value: passphrase}, nil
+
+	case "uuid":
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return nil, fmt.Errorf("generate uuid: %w", err)
+		}
+		return &Generated{Value: []byte(id.String())}, nil
+
+	case "bcrypt", "htpasswd":
+		password, err := generatePassword(gen)
+		if err != nil {
+			return nil, err
+		}
+		cost := gen.Cost
+		if cost == 0 {
+			cost = bcrypt.DefaultCost
+		}
+		hash, err := bcrypt.GenerateFromPassword(password, cost)
+		if err != nil {
+			return nil, fmt.Errorf("bcrypt: %w", err)
+		}
+		if gen.Kind == "htpasswd" {
+			line := []byte(gen.Username + ":" + string(hash))
+			return &Generated{Value: password, Outputs: map[string][]byte{"htpasswd": line}}, nil
+		}
+		return &Generated{Value: password, Outputs: map[string][]byte{"hash": hash}}, nil
+
+	case "ed25519", "sshEd25519":
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("generate ed25519 key: %w", err)
+		}
+		if gen.Kind == "sshEd25519" {
+			return sshKeypair(pub, priv)
+		}
+		return pemKeypair(pub, priv)
+
+	case "rsa":
+		bits := gen.Bits
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+		priv, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, fmt.Errorf("generate rsa key: %w", err)
+		}
+		return pemKeypair(&priv.PublicKey, priv)
+
+	case "tls":
+		if gen.Issuer != nil {
+			return nil, fmt.Errorf("tls generator with an issuer needs the issuer certificate and key")
+		}
+		return IssueCertificate(gen, nil, time.Now())
 
-	// Encode based on kind
-	switch gen.Kind {
-	case "randomBase64":
-		return []byte(base64.StdEncoding.EncodeToString(randomBytes)), nil
-	case "randomHex":
-		return []byte(hex.EncodeToString(randomBytes)), nil
-	case "randomBytes":
-		return randomBytes, nil
 	default:
 		return nil, fmt.Errorf("unsupported generator kind: %s", gen.Kind)
 	}
 }
+
+// passwordAlphabet returns the characters a password generator draws from,
+// one string per charset class, with the excluded characters removed.
+func passwordAlphabet(gen *GeneratorConfig) ([]string, error) {
+	charset := gen.Charset
+	if len(charset) == 0 {
+		charset = defaultPasswordCharset
+	}
+
+	var classes []string
+	for _, name := range charset {
+		chars, ok := passwordClasses[name]
+		if !ok {
+			return nil, NewValidationError("rotation.generator.charset", fmt.Sprintf("unknown class %q (use lower, upper, digits, symbols)", name))
+		}
+		chars = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(gen.Exclude, r) {
+				return -1
+			}
+			return r
+		}, chars)
+		if chars == "" {
+			return nil, NewValidationError("rotation.generator.exclude", fmt.Sprintf("excludes every character of class %q", name))
+		}
+		classes = append(classes, chars)
+	}
+	return classes, nil
+}
+
+// generatePassword draws Length characters from the charset. The password
+// contains at least one character of every class.
+func generatePassword(gen *GeneratorConfig) ([]byte, error) {
+	classes, err := passwordAlphabet(gen)
+	if err != nil {
+		return nil, err
+	}
+	length := gen.Length
+	if length == 0 {
+		length = DefaultPasswordLength
+	}
+	if length < len(classes) {
+		return nil, NewValidationError("rotation.generator.length", "must be at least the number of charset classes")
+	}
+	alphabet := strings.Join(classes, "")
+
+	for {
+		password := make([]byte, length)
+		for i := range password {
+			c, err := randomIndex(len(alphabet))
+			if err != nil {
+				return nil, err
+			}
+			password[i] = alphabet[c]
+		}
+		if containsEveryClass(password, classes) {
+			return password, nil
+		}
+	}
+}
+
+func containsEveryClass(password []byte, classes []string) bool {
+	for _, class := range classes {
+		if !slices.ContainsFunc(password, func(b byte) bool { return strings.IndexByte(class, b) >= 0 }) {
+			return false
+		}
+	}
+	return true
+}
+
+// generatePassphrase joins Words random words from the BIP39 word list
+// (11 bits of entropy per word).
+func generatePassphrase(gen *GeneratorConfig) ([]byte, error) {
+	words := gen.Words
+	if words == 0 {
+		words = DefaultPassphraseWords
+	}
+	sep := gen.Separator
+	if sep == "" {
+		sep = DefaultPassphraseSep
+	}
+
+	chosen := make([]string, words)
+	for i := range chosen {
+		n, err := randomIndex(len(wordlist))
+		if err != nil {
+			return nil, err
+		}
+		chosen[i] = wordlist[n]
+	}
+	return []byte(strings.Join(chosen, sep)), nil
+}
+
+// randomIndex returns a uniformly random integer in [0, n).
+func randomIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("read random: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// pemKeypair encodes a private key as PKCS#8 PEM and its public key as
+// PKIX PEM.
+func pemKeypair(pub, priv any) (*Generated, error) {
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+	return &Generated{
+		Value: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		Outputs: map[string][]byte{
+			"publicKey": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		},
+	}, nil
+}
+
+// sshKeypair encodes an Ed25519 key in OpenSSH format, with its public key
+// as an authorized_keys line.
+func sshKeypair(pub ed25519.PublicKey, priv ed25519.PrivateKey) (*Generated, error) {
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return nil, fmt.Errorf("marshal ssh private key: %w", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("marshal ssh public key: %w", err)
+	}
+	return &Generated{
+		Value:   pem.EncodeToMemory(block),
+		Outputs: map[string][]byte{"publicKey": ssh.MarshalAuthorizedKey(sshPub)},
+	}, nil
+}
+
+// Validate checks the generator kind and its parameters.
+func (g *GeneratorConfig) Validate() error {
+	if !slices.Contains(generatorKinds, g.Kind) {
+		return NewValidationError("rotation.generator.kind", "must be one of "+strings.Join(generatorKinds, ", "))
+	}
+	if g.Bytes < 0 || g.Bytes > 4096 {
+		return NewValidationError("rotation.generator.bytes", "must be between 1 and 4096")
+	}
+	if g.Length < 0 || g.Length > 4096 {
+		return NewValidationError("rotation.generator.length", "must be between 1 and 4096")
+	}
+	if g.Words < 0 || g.Words > 64 {
+		return NewValidationError("rotation.generator.words", "must be between 1 and 64")
+	}
+	if g.Cost != 0 && (g.Cost < bcrypt.MinCost || g.Cost > bcrypt.MaxCost) {
+		return NewValidationError("rotation.generator.cost", fmt.Sprintf("must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+	if g.Bits != 0 && (g.Bits < 2048 || g.Bits > 8192) {
+		return NewValidationError("rotation.generator.bits", "must be between 2048 and 8192")
+	}
+
+	switch g.Kind {
+	case "password", "bcrypt", "htpasswd":
+		classes, err := passwordAlphabet(g)
+		if err != nil {
+			return err
+		}
+		if g.Length != 0 && g.Length < len(classes) {
+			return NewValidationError("rotation.generator.length", "must be at least the number of charset classes")
+		}
+	}
+	if (g.Kind == "bcrypt" || g.Kind == "htpasswd") && g.Length > bcryptMaxLength {
+		return NewValidationError("rotation.generator.length", fmt.Sprintf("must be at most %d for %s", bcryptMaxLength, g.Kind))
+	}
+	if g.Kind == "tls" {
+		if err := g.validateCertificate(); err != nil {
+			return err
+		}
+	}
+	if g.Kind == "htpasswd" && (g.Username == "" || strings.Contains(g.Username, ":")) {
+		return NewValidationError("rotation.generator.username", "required for htpasswd and must not contain ':'")
+	}
+
+	outputs := generatorOutputs[g.Kind]
+	if g.PairedKey != "" {
+		if len(outputs) != 1 {
+			return NewValidationError("rotation.generator.pairedKey", "not supported by kind "+g.Kind+" (use outputs)")
+		}
+		if _, ok := g.Outputs[outputs[0]]; ok {
+			return NewValidationError("rotation.generator.pairedKey", fmt.Sprintf("conflicts with outputs.%s", outputs[0]))
+		}
+	}
+	for name, keyName := range g.Outputs {
+		if !slices.Contains(outputs, name) {
+			return NewValidationError("rotation.generator.outputs", fmt.Sprintf("kind %s has no output %q", g.Kind, name))
+		}
+		if keyName == "" {
+			return NewValidationError("rotation.generator.outputs."+name, "key name is required")
+		}
+	}
+	seen := make(map[string]bool)
+	for _, keyName := range g.OutputKeys() {
+		if seen[keyName] {
+			return NewValidationError("rotation.generator.outputs", fmt.Sprintf("key %q receives more than one output", keyName))
+		}
+		seen[keyName] = true
+	}
+	if (g.Kind == "bcrypt" || g.Kind == "htpasswd") && len(g.OutputKeys()) == 0 {
+		return NewValidationError("rotation.generator.pairedKey", "required for "+g.Kind+" (the key that receives the hash)")
+	}
+	return nil
+}
+
+// OutputKeys maps the generator's outputs onto the keys that receive them,
+// combining Outputs with the PairedKey shorthand.
+func (g *GeneratorConfig) OutputKeys() map[string]string {
+	keys := make(map[string]string, len(g.Outputs)+1)
+	for name, keyName := range g.Outputs {
+		keys[name] = keyName
+	}
+	if outputs := generatorOutputs[g.Kind]; g.PairedKey != "" && len(outputs) == 1 {
+		keys[outputs[0]] = g.PairedKey
+	}
+	return keys
+}
+
+// ParseGeneratorSpec parses a generator from its command-line form:
+// "kind" or "kind:param=value:param=value", e.g.
+// "password:length=24:charset=lower+upper+digits:exclude=0O1l".
+// Parameters are the GeneratorConfig fields by their YAML names; charset
+// classes and sans are joined with "+"; issuerCert and issuerKey are the
+// issuer's store references as "<secretResource>@<version>". An output name of the kind maps that output
+// onto a key, e.g. "sshEd25519:publicKey=deploy_key.pub".
+func ParseGeneratorSpec(spec string) (*GeneratorConfig, error) {
+	parts := strings.Split(spec, ":")
+	gen := &GeneratorConfig{Kind: parts[0]}
+
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, NewValidationError("generator", fmt.Sprintf("parameter %q must be name=value", param))
+		}
+
+		var err error
+		switch name {
+		case "bytes":
+			gen.Bytes, err = strconv.Atoi(value)
+		case "length":
+			gen.Length, err = strconv.Atoi(value)
+		case "words":
+			gen.Words, err = strconv.Atoi(value)
+		case "cost":
+			gen.Cost, err = strconv.Atoi(value)
+		case "bits":
+			gen.Bits, err = strconv.Atoi(value)
+		case "charset":
+			gen.Charset = strings.Split(value, "+")
+		case "exclude":
+			gen.Exclude = value
+		case "separator":
+			gen.Separator = value
+		case "username":
+			gen.Username = value
+		case "pairedKey":
+			gen.PairedKey = value
+		case "commonName":
+			gen.CommonName = value
+		case "sans":
+			gen.SANs = strings.Split(value, "+")
+		case "validity":
+			gen.Validity = value
+		case "keyType":
+			gen.KeyType = value
+		case "issuerCert", "issuerKey":
+			resource, version, ok := strings.Cut(value, "@")
+			if !ok {
+				return nil, NewValidationError("generator."+name, "must be <secretResource>@<version>")
+			}
+			if gen.Issuer == nil {
+				gen.Issuer = &IssuerRef{}
+			}
+			ref := &StoreRef{SecretResource: resource, Version: version}
+			if name == "issuerCert" {
+				gen.Issuer.Cert = ref
+			} else {
+				gen.Issuer.Key = ref
+			}
+		default:
+			// An output name maps that output onto a key
+			if !slices.Contains(generatorOutputs[gen.Kind], name) {
+				return nil, NewValidationError("generator", fmt.Sprintf("unknown parameter %q", name))
+			}
+			if gen.Outputs == nil {
+				gen.Outputs = make(map[string]string)
+			}
+			gen.Outputs[name] = value
+		}
+		if err != nil {
+			return nil, NewValidationError("generator."+name, "must be a number")
+		}
+	}
+
+	if err := gen.Validate(); err != nil {
+		return nil, err
+	}
+	return gen, nil
+}
@@ -1,9 +1,18 @@
 package core
 
 import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
+	"strings"
 	"testing"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestGenerateValue_RandomBase64(t *testing.T) {
@@ -118,3 +127,253 @@ func TestGenerateValue_NilGenerator(t *testing.T) {
 		t.Error("expected error for nil generator")
 	}
 }
+
+func TestGenerate_PasswordCharset(t *testing.T) {
+	gen := &GeneratorConfig{
+		Kind:    "password",
+		Length:  40,
+		Charset: []string{"lower", "digits"},
+		Exclude: "0o1l",
+	}
+
+	for i := 0; i < 20; i++ {
+		g, err := Generate(gen)
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		password := string(g.Value)
+		if len(password) != 40 {
+			t.Fatalf("length = %d, want 40", len(password))
+		}
+		if strings.ContainsAny(password, "0o1lABCXYZ!#$") {
+			t.Fatalf("password %q contains excluded or out-of-charset characters", password)
+		}
+		if !strings.ContainsAny(password, "abcdefghijkmnpqrstuvwxyz") || !strings.ContainsAny(password, "23456789") {
+			t.Fatalf("password %q is missing a charset class", password)
+		}
+		if g.Outputs != nil {
+			t.Error("password should have no other outputs")
+		}
+	}
+}
+
+func TestGenerate_PasswordDefaultHasNoSymbols(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "password"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(g.Value) != DefaultPasswordLength {
+		t.Errorf("length = %d, want %d", len(g.Value), DefaultPasswordLength)
+	}
+	for _, c := range g.Value {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			t.Fatalf("default password %q is not alphanumeric", g.Value)
+		}
+	}
+}
+
+func TestGenerate_Passphrase(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "passphrase", Words: 4, Separator: "."})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	words := strings.Split(string(g.Value), ".")
+	if len(words) != 4 {
+		t.Fatalf("got %d words, want 4: %q", len(words), g.Value)
+	}
+	for _, w := range words {
+		found := false
+		for _, known := range wordlist {
+			if w == known {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("word %q is not in the word list", w)
+		}
+	}
+	if len(wordlist) != 2048 {
+		t.Errorf("word list has %d words, want 2048", len(wordlist))
+	}
+}
+
+func TestGenerate_UUID(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "uuid"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	id, err := uuid.Parse(string(g.Value))
+	if err != nil {
+		t.Fatalf("invalid uuid %q: %v", g.Value, err)
+	}
+	if id.Version() != 4 {
+		t.Errorf("uuid version = %d, want 4", id.Version())
+	}
+}
+
+func TestGenerate_RandomBase64URL(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "randomBase64URL", Bytes: 64})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(string(g.Value))
+	if err != nil {
+		t.Fatalf("invalid base64url: %v", err)
+	}
+	if len(decoded) != 64 {
+		t.Errorf("decoded length = %d, want 64", len(decoded))
+	}
+}
+
+func TestGenerate_Bcrypt(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "bcrypt", Cost: bcrypt.MinCost, PairedKey: "hash"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword(g.Outputs["hash"], g.Value); err != nil {
+		t.Errorf("hash does not match password: %v", err)
+	}
+}
+
+func TestGenerate_Htpasswd(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "htpasswd", Cost: bcrypt.MinCost, Username: "admin", PairedKey: "auth"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	user, hash, ok := strings.Cut(string(g.Outputs["htpasswd"]), ":")
+	if !ok || user != "admin" {
+		t.Fatalf("htpasswd line = %q, want admin:<hash>", g.Outputs["htpasswd"])
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), g.Value); err != nil {
+		t.Errorf("hash does not match password: %v", err)
+	}
+}
+
+func TestGenerate_Ed25519(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "ed25519"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	privBlock, _ := pem.Decode(g.Value)
+	if privBlock == nil {
+		t.Fatal("private key is not PEM")
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(privBlock.Bytes)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	pubBlock, _ := pem.Decode(g.Outputs["publicKey"])
+	if pubBlock == nil {
+		t.Fatal("public key is not PEM")
+	}
+	pub, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
+	if err != nil {
+		t.Fatalf("parse public key: %v", err)
+	}
+	if !priv.(ed25519.PrivateKey).Public().(ed25519.PublicKey).Equal(pub) {
+		t.Error("public key does not belong to private key")
+	}
+}
+
+func TestGenerate_SSHEd25519(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "sshEd25519"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(g.Value)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(g.Outputs["publicKey"])
+	if err != nil {
+		t.Fatalf("parse authorized key: %v", err)
+	}
+	if string(signer.PublicKey().Marshal()) != string(pub.Marshal()) {
+		t.Error("public key does not belong to private key")
+	}
+}
+
+func TestGenerate_RSA(t *testing.T) {
+	g, err := Generate(&GeneratorConfig{Kind: "rsa", Bits: 2048})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	block, _ := pem.Decode(g.Value)
+	if block == nil {
+		t.Fatal("private key is not PEM")
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	if bits := priv.(*rsa.PrivateKey).N.BitLen(); bits != 2048 {
+		t.Errorf("key size = %d, want 2048", bits)
+	}
+}
+
+func TestGeneratorConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		gen     GeneratorConfig
+		wantErr bool
+	}{
+		{"random", GeneratorConfig{Kind: "randomHex", Bytes: 16}, false},
+		{"password", GeneratorConfig{Kind: "password", Length: 20, Charset: []string{"upper", "symbols"}}, false},
+		{"unknown kind", GeneratorConfig{Kind: "md5"}, true},
+		{"unknown class", GeneratorConfig{Kind: "password", Charset: []string{"emoji"}}, true},
+		{"exclude whole class", GeneratorConfig{Kind: "password", Charset: []string{"digits"}, Exclude: "0123456789"}, true},
+		{"length below classes", GeneratorConfig{Kind: "password", Length: 2, Charset: []string{"lower", "upper", "digits"}}, true},
+		{"bytes too large", GeneratorConfig{Kind: "randomBase64", Bytes: 1 << 20}, true},
+		{"bcrypt without pairedKey", GeneratorConfig{Kind: "bcrypt"}, true},
+		{"bcrypt cost too high", GeneratorConfig{Kind: "bcrypt", Cost: 40, PairedKey: "hash"}, true},
+		{"bcrypt length over 72", GeneratorConfig{Kind: "bcrypt", Length: 73, PairedKey: "hash"}, true},
+		{"htpasswd length over 72", GeneratorConfig{Kind: "htpasswd", Length: 100, Username: "admin", PairedKey: "auth"}, true},
+		{"bcrypt length 72", GeneratorConfig{Kind: "bcrypt", Length: 72, PairedKey: "hash"}, false},
+		{"htpasswd without username", GeneratorConfig{Kind: "htpasswd", PairedKey: "auth"}, true},
+		{"rsa too small", GeneratorConfig{Kind: "rsa", Bits: 1024}, true},
+		{"pairedKey on unpaired kind", GeneratorConfig{Kind: "uuid", PairedKey: "other"}, true},
+		{"outputs", GeneratorConfig{Kind: "rsa", Outputs: map[string]string{"publicKey": "pub"}}, false},
+		{"unknown output", GeneratorConfig{Kind: "rsa", Outputs: map[string]string{"cert": "tls.crt"}}, true},
+		{"pairedKey and outputs", GeneratorConfig{Kind: "rsa", PairedKey: "a", Outputs: map[string]string{"publicKey": "b"}}, true},
+		{"bcrypt with hash output", GeneratorConfig{Kind: "bcrypt", Outputs: map[string]string{"hash": "h"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.gen.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGeneratorSpec(t *testing.T) {
+	gen, err := ParseGeneratorSpec("password:length=24:charset=lower+digits:exclude=0O")
+	if err != nil {
+		t.Fatalf("ParseGeneratorSpec failed: %v", err)
+	}
+	if gen.Kind != "password" || gen.Length != 24 || gen.Exclude != "0O" {
+		t.Errorf("unexpected config: %+v", gen)
+	}
+	if len(gen.Charset) != 2 || gen.Charset[0] != "lower" || gen.Charset[1] != "digits" {
+		t.Errorf("charset = %v, want [lower digits]", gen.Charset)
+	}
+
+	gen, err = ParseGeneratorSpec("sshEd25519:publicKey=deploy_key.pub")
+	if err != nil {
+		t.Fatalf("ParseGeneratorSpec failed: %v", err)
+	}
+	if gen.Outputs["publicKey"] != "deploy_key.pub" {
+		t.Errorf("outputs = %v, want publicKey=deploy_key.pub", gen.Outputs)
+	}
+
+	for _, spec := range []string{"password:length", "password:length=x", "password:size=3", "password:publicKey=x", "nope"} {
+		if _, err := ParseGeneratorSpec(spec); err == nil {
+			t.Errorf("ParseGeneratorSpec(%q) expected error", spec)
+		}
+	}
+}
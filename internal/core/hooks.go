@@ -0,0 +1,110 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Hook phases.
+const (
+	HookPhasePreRotate  = "preRotate"
+	HookPhasePostRotate = "postRotate"
+)
+
+// DefaultHookTimeout bounds a hook without an explicit timeout.
+const DefaultHookTimeout = 30 * time.Second
+
+// HooksConfig lists commands or local HTTP endpoints run around a rotation.
+// Hooks receive the secret's short name, key names and new store versions,
+// never the values.
+type HooksConfig struct {
+	PreRotate  []HookConfig `json:"preRotate,omitempty"`  // failure aborts the rotation
+	PostRotate []HookConfig `json:"postRotate,omitempty"` // run after resealing
+	// RollbackOnFailure points the metadata back at the previous versions
+	// and reseals again when a post-rotate hook fails.
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+}
+
+// HookConfig is one hook: a command or an HTTP endpoint on this machine.
+type HookConfig struct {
+	Name    string   `json:"name,omitempty"`
+	Command []string `json:"command,omitempty"` // argv, run in the repo root
+	URL     string   `json:"url,omitempty"`     // POSTed the event as JSON
+	Timeout string   `json:"timeout,omitempty"` // Go duration, default 30s
+}
+
+// Label names the hook in output and state: its name, else its command or URL.
+func (h HookConfig) Label() string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case len(h.Command) > 0:
+		return strings.Join(h.Command, " ")
+	default:
+		return h.URL
+	}
+}
+
+// TimeoutDuration returns the hook's timeout, DefaultHookTimeout if unset.
+func (h HookConfig) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultHookTimeout
+}
+
+// Validate checks the hooks configuration.
+func (c *HooksConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for i, h := range c.PreRotate {
+		if err := h.Validate(); err != nil {
+			return WrapValidation(fmt.Sprintf("preRotate[%d]", i), err)
+		}
+	}
+	for i, h := range c.PostRotate {
+		if err := h.Validate(); err != nil {
+			return WrapValidation(fmt.Sprintf("postRotate[%d]", i), err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that a hook has exactly one of command and url, and that
+// the url is on a loopback address.
+func (h HookConfig) Validate() error {
+	if (len(h.Command) == 0) == (h.URL == "") {
+		return NewValidationError("hook", "exactly one of command and url required")
+	}
+	if len(h.Command) > 0 && h.Command[0] == "" {
+		return NewValidationError("command", "program must not be empty")
+	}
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return NewValidationError("url", "must be an http or https URL")
+		}
+		if !isLoopbackHost(u.Hostname()) {
+			return NewValidationError("url", "must point to localhost")
+		}
+	}
+	if h.Timeout != "" {
+		d, err := time.ParseDuration(h.Timeout)
+		if err != nil || d <= 0 {
+			return NewValidationError("timeout", "must be a positive duration like 30s")
+		}
+	}
+	return nil
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHookConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    HookConfig
+		wantErr bool
+	}{
+		{"command", HookConfig{Command: []string{"./reload.sh"}}, false},
+		{"localhost url", HookConfig{URL: "http://localhost:9000/reload"}, false},
+		{"loopback ip", HookConfig{URL: "https://127.0.0.1/verify"}, false},
+		{"ipv6 loopback", HookConfig{URL: "http://[::1]:8080/"}, false},
+		{"timeout", HookConfig{Command: []string{"true"}, Timeout: "2m"}, false},
+		{"neither", HookConfig{Name: "empty"}, true},
+		{"both", HookConfig{Command: []string{"true"}, URL: "http://localhost/"}, true},
+		{"empty program", HookConfig{Command: []string{""}}, true},
+		{"remote url", HookConfig{URL: "https://example.com/reload"}, true},
+		{"not http", HookConfig{URL: "ftp://localhost/"}, true},
+		{"bad timeout", HookConfig{Command: []string{"true"}, Timeout: "soon"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHookConfig_LabelAndTimeout(t *testing.T) {
+	h := HookConfig{Command: []string{"kubectl", "rollout", "restart"}}
+	if got := h.Label(); got != "kubectl rollout restart" {
+		t.Errorf("Label() = %q", got)
+	}
+	if got := h.TimeoutDuration(); got != DefaultHookTimeout {
+		t.Errorf("TimeoutDuration() = %v, want default", got)
+	}
+	h = HookConfig{Name: "reload", URL: "http://localhost/", Timeout: "5s"}
+	if got := h.Label(); got != "reload" {
+		t.Errorf("Label() = %q", got)
+	}
+	if got := h.TimeoutDuration(); got != 5*time.Second {
+		t.Errorf("TimeoutDuration() = %v, want 5s", got)
+	}
+}
+
+func TestParseMetadata_InvalidHook(t *testing.T) {
+	yaml := `shortName: app
+manifestPath: apps/app/sealed-secret.yaml
+sealedSecret:
+  name: app
+  namespace: default
+  scope: strict
+hooks:
+  postRotate:
+    - url: https://hooks.example.com/reload
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/p/secrets/app-password
+      version: "1"
+`
+	if _, err := ParseMetadata([]byte(yaml)); err == nil {
+		t.Error("expected error for a hook URL outside localhost")
+	}
+}
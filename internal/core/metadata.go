@@ -4,6 +4,9 @@ package core
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/yaml"
@@ -14,11 +17,13 @@ import (
 type SecretMetadata struct {
 	ShortName    string          `json:"shortName"`
 	ManifestPath string          `json:"manifestPath"`
+	Environments []string        `json:"environments,omitempty"` // config environments; manifestPath may use {env}
 	SealedSecret SealedSecretRef `json:"sealedSecret"`
 	Status       string          `json:"status,omitempty"`    // "active" or "retired"
 	RetiredAt    string          `json:"retiredAt,omitempty"` // RFC3339
 	RetireReason string          `json:"retireReason,omitempty"`
 	ReplacedBy   string          `json:"replacedBy,omitempty"`
+	Hooks        *HooksConfig    `json:"hooks,omitempty"` // run after the config-level hooks
 	Keys         []KeyMetadata   `json:"keys"`
 }
 
@@ -46,27 +51,59 @@ type SourceConfig struct {
 	Kind string `json:"kind"` // "gsm" or "computed"
 }
 
-// GSMRef references a secret in Google Secret Manager.
-type GSMRef struct {
+// StoreRef references a pinned version of a secret in the configured store.
+// The resource uses the "projects/<project>/secrets/<secretId>" form for every
+// backend; each store maps it onto its own naming scheme, and every backend
+// exposes versions as increasing integers.
+type StoreRef struct {
 	SecretResource string `json:"secretResource"` // "projects/<project>/secrets/<secretId>"
 	Version        string `json:"version"`        // Must be numeric
 }
 
+// GSMRef is the original name of StoreRef. Metadata still serializes store
+// references under the "gsm" key, whichever backend is configured.
+type GSMRef = StoreRef
+
 // RotationConfig describes how a key is rotated.
 type RotationConfig struct {
 	Mode      string           `json:"mode"` // "static", "generated", "external", "unknown"
 	Generator *GeneratorConfig `json:"generator,omitempty"`
+	Interval  string           `json:"interval,omitempty"` // rotation policy, e.g. "90d"; see ParseInterval
 }
 
-// GeneratorConfig describes how to generate a key value.
+// GeneratorConfig describes how to generate a key value. See Generate for
+// the kinds and the parameters each one uses.
 type GeneratorConfig struct {
-	Kind  string `json:"kind"`            // "randomBase64", "randomHex", "randomBytes"
-	Bytes int    `json:"bytes,omitempty"` // Number of random bytes to generate
+	Kind       string            `json:"kind"`
+	Bytes      int               `json:"bytes,omitempty"`      // random kinds: number of random bytes (default 32)
+	Length     int               `json:"length,omitempty"`     // password kinds: number of characters (default 32)
+	Charset    []string          `json:"charset,omitempty"`    // password kinds: lower, upper, digits, symbols (default lower, upper, digits)
+	Exclude    string            `json:"exclude,omitempty"`    // password kinds: characters never used, e.g. "0O1lI"
+	Words      int               `json:"words,omitempty"`      // passphrase: number of words (default 6)
+	Separator  string            `json:"separator,omitempty"`  // passphrase: word separator (default "-")
+	Cost       int               `json:"cost,omitempty"`       // bcrypt, htpasswd: bcrypt cost (default 10)
+	Username   string            `json:"username,omitempty"`   // htpasswd: user of the entry
+	Bits       int               `json:"bits,omitempty"`       // rsa, tls: RSA key size (default 3072)
+	CommonName string            `json:"commonName,omitempty"` // tls: certificate subject CN
+	SANs       []string          `json:"sans,omitempty"`       // tls: DNS names and IP addresses
+	Validity   string            `json:"validity,omitempty"`   // tls: certificate lifetime, e.g. "90d" (default 90d)
+	KeyType    string            `json:"keyType,omitempty"`    // tls: ecdsa (P-256, default), rsa, ed25519
+	Issuer     *IssuerRef        `json:"issuer,omitempty"`     // tls: CA that signs the certificate (default self-signed)
+	PairedKey  string            `json:"pairedKey,omitempty"`  // shorthand for outputs with the kind's only output
+	Outputs    map[string]string `json:"outputs,omitempty"`    // other outputs (e.g. publicKey) → key of the same secret that receives it
+}
+
+// IssuerRef points at a CA certificate and its private key (both PEM) in
+// the secret store.
+type IssuerRef struct {
+	Cert *StoreRef `json:"cert"`
+	Key  *StoreRef `json:"key"`
 }
 
 // ExpiryConfig tracks key expiration.
 type ExpiryConfig struct {
-	ExpiresAt string `json:"expiresAt"` // RFC3339
+	ExpiresAt string `json:"expiresAt,omitempty"` // RFC3339
+	Source    string `json:"source,omitempty"`    // "manual" (default) or "derived": ExpiresAt is read from the value; see DeriveExpiry
 }
 
 // OperatorHints references guidance for manual rotation stored in GSM.
@@ -134,6 +171,13 @@ func (m *SecretMetadata) Validate() error {
 	if m.ManifestPath == "" {
 		return NewValidationError("manifestPath", "required")
 	}
+	hasPlaceholder := strings.Contains(m.ManifestPath, EnvPlaceholder)
+	if len(m.Environments) > 1 && !hasPlaceholder {
+		return NewValidationError("manifestPath", "must contain "+EnvPlaceholder+" when targeting several environments")
+	}
+	if len(m.Environments) == 0 && hasPlaceholder {
+		return NewValidationError("manifestPath", EnvPlaceholder+" requires environments")
+	}
 	if err := m.SealedSecret.Validate(); err != nil {
 		return err
 	}
@@ -147,10 +191,96 @@ func (m *SecretMetadata) Validate() error {
 		if err := k.Validate(); err != nil {
 			return WrapValidation(fmt.Sprintf("keys[%d]", i), err)
 		}
+		if err := m.validateOutputKeys(k); err != nil {
+			return WrapValidation(fmt.Sprintf("keys[%d]", i), err)
+		}
+	}
+	if err := m.Hooks.Validate(); err != nil {
+		return WrapValidation("hooks", err)
+	}
+	return nil
+}
+
+// validateOutputKeys checks that the keys receiving a generator's outputs
+// are other store keys of the same secret, not written by a second
+// generator and not generated themselves.
+func (m *SecretMetadata) validateOutputKeys(k KeyMetadata) error {
+	if k.Rotation == nil || k.Rotation.Generator == nil {
+		return nil
+	}
+	for output, keyName := range k.Rotation.Generator.OutputKeys() {
+		field := "rotation.generator.outputs." + output
+		if keyName == k.KeyName {
+			return NewValidationError(field, "must name another key")
+		}
+		target := m.findKey(keyName)
+		if target == nil {
+			return NewValidationError(field, fmt.Sprintf("key %q not found in this secret", keyName))
+		}
+		if target.Source.Kind != "gsm" {
+			return NewValidationError(field, fmt.Sprintf("key %q must have source.kind 'gsm'", keyName))
+		}
+		if target.Rotation != nil && target.Rotation.Mode == "generated" {
+			return NewValidationError(field, fmt.Sprintf("key %q is generated itself", keyName))
+		}
+		if owner, _ := m.GeneratorOwner(keyName); owner != k.KeyName {
+			return NewValidationError(field, fmt.Sprintf("key %q is already an output of %q", keyName, owner))
+		}
+	}
+	return nil
+}
+
+func (m *SecretMetadata) findKey(keyName string) *KeyMetadata {
+	for i := range m.Keys {
+		if m.Keys[i].KeyName == keyName {
+			return &m.Keys[i]
+		}
 	}
 	return nil
 }
 
+// GeneratorOwner returns the key whose generator writes keyName as one of
+// its outputs, and the output name.
+func (m *SecretMetadata) GeneratorOwner(keyName string) (owner, output string) {
+	for _, k := range m.Keys {
+		if k.Rotation == nil || k.Rotation.Generator == nil {
+			continue
+		}
+		for name, target := range k.Rotation.Generator.OutputKeys() {
+			if target == keyName {
+				return k.KeyName, name
+			}
+		}
+	}
+	return "", ""
+}
+
+// EnvPlaceholder is replaced by the environment name in manifestPath.
+const EnvPlaceholder = "{env}"
+
+// TargetsEnv reports whether the secret is sealed for an environment.
+// Secrets without environments belong to the top-level profile ("").
+func (m *SecretMetadata) TargetsEnv(env string) bool {
+	if env == "" {
+		return len(m.Environments) == 0
+	}
+	return slices.Contains(m.Environments, env)
+}
+
+// TargetEnvs returns the environments the secret is sealed for, or the
+// top-level profile ("") alone when it targets none.
+func (m *SecretMetadata) TargetEnvs() []string {
+	if len(m.Environments) == 0 {
+		return []string{""}
+	}
+	return m.Environments
+}
+
+// ManifestPathFor returns the manifest path for an environment.
+func (m *SecretMetadata) ManifestPathFor(env string) string {
+	return strings.ReplaceAll(m.ManifestPath, EnvPlaceholder, env)
+}
+
 // Validate checks the SealedSecretRef.
 func (s *SealedSecretRef) Validate() error {
 	if s.Name == "" {
@@ -221,6 +351,71 @@ func (g *GSMRef) Validate() error {
 	return nil
 }
 
+// Store kinds accepted by StoreRef.ValidateForStore.
+const (
+	StoreKindGSM   = "gsm"
+	StoreKindVault = "vault"
+	StoreKindAWS   = "aws"
+	StoreKindFile  = "file"
+)
+
+// storeResourcePatterns constrains the <project> and <secretId> segments of a
+// secret resource for each backend's naming rules.
+var storeResourcePatterns = map[string]*regexp.Regexp{
+	// GSM: project IDs are lowercase; secret IDs are letters, digits, - and _
+	StoreKindGSM: regexp.MustCompile(`^projects/[a-z0-9-]+/secrets/[A-Za-z0-9_-]{1,255}$`),
+	// Vault: segments become KV path components, so no slashes
+	StoreKindVault: regexp.MustCompile(`^projects/[A-Za-z0-9_.-]+/secrets/[A-Za-z0-9_.-]+$`),
+	// AWS: "<project>/<secretId>" becomes the secret name (max 512 chars)
+	StoreKindAWS: regexp.MustCompile(`^projects/[A-Za-z0-9_+=.@-]+/secrets/[A-Za-z0-9_+=.@-]+$`),
+	// File: resources are only map keys inside the encrypted store
+	StoreKindFile: regexp.MustCompile(`^projects/[^/]+/secrets/[^/]+$`),
+}
+
+// ValidateForStore checks the reference against a specific backend's naming
+// rules, in addition to the backend-independent checks in Validate.
+func (g *StoreRef) ValidateForStore(kind string) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	pattern, ok := storeResourcePatterns[kind]
+	if !ok {
+		return NewValidationError("store.kind", fmt.Sprintf("unknown store kind %q", kind))
+	}
+	if !pattern.MatchString(g.SecretResource) {
+		return NewValidationError("gsm.secretResource",
+			fmt.Sprintf("%q is not a valid %s secret resource", g.SecretResource, kind))
+	}
+	// AWS secret name is "<project>/<secretId>"
+	if kind == StoreKindAWS && len(g.SecretResource)-len("projects/")-len("/secrets/")+len("/") > 512 {
+		return NewValidationError("gsm.secretResource", "AWS secret names are limited to 512 characters")
+	}
+	return nil
+}
+
+// ValidateStoreRefs checks every store reference in the metadata against
+// the naming rules of the given backend.
+func (m *SecretMetadata) ValidateStoreRefs(kind string) error {
+	for i, k := range m.Keys {
+		refs := []*StoreRef{k.GSM}
+		if k.Computed != nil {
+			refs = append(refs, k.Computed.GSM)
+		}
+		if k.OperatorHints != nil {
+			refs = append(refs, k.OperatorHints.GSM)
+		}
+		for _, ref := range refs {
+			if ref == nil {
+				continue
+			}
+			if err := ref.ValidateForStore(kind); err != nil {
+				return WrapValidation(fmt.Sprintf("keys[%d]", i), err)
+			}
+		}
+	}
+	return nil
+}
+
 // Validate checks the RotationConfig.
 func (r *RotationConfig) Validate() error {
 	validModes := map[string]bool{"static": true, "generated": true, "external": true, "unknown": true}
@@ -231,17 +426,53 @@ func (r *RotationConfig) Validate() error {
 		return NewValidationError("rotation.generator", "required when mode is 'generated'")
 	}
 	if r.Generator != nil {
-		validKinds := map[string]bool{"randomBase64": true, "randomHex": true, "randomBytes": true}
-		if !validKinds[r.Generator.Kind] {
-			return NewValidationError("rotation.generator.kind", "must be 'randomBase64', 'randomHex', or 'randomBytes'")
+		if err := r.Generator.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Interval != "" {
+		if _, err := ParseInterval(r.Interval); err != nil {
+			return NewValidationError("rotation.interval", err.Error())
 		}
 	}
 	return nil
 }
 
+// intervalPattern matches day and week intervals such as "90d" or "2w".
+var intervalPattern = regexp.MustCompile(`^([0-9]+)([dw])$`)
+
+// ParseInterval parses a rotation interval: a number of days ("90d") or
+// weeks ("2w"), or a Go duration ("720h").
+func ParseInterval(s string) (time.Duration, error) {
+	var d time.Duration
+	if m := intervalPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		d = time.Duration(n) * 24 * time.Hour
+		if m[2] == "w" {
+			d *= 7
+		}
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("must be a number of days (\"90d\"), weeks (\"2w\") or a duration (\"720h\")")
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
 // Validate checks the ExpiryConfig.
 func (e *ExpiryConfig) Validate() error {
+	if e.Source != "" && e.Source != ExpirySourceManual && !e.IsDerived() {
+		return NewValidationError("expiry.source", "must be 'manual' or 'derived'")
+	}
 	if e.ExpiresAt == "" {
+		// A derived expiry is filled in by reseal and rotate
+		if e.IsDerived() {
+			return nil
+		}
 		return NewValidationError("expiry.expiresAt", "required")
 	}
 	if _, err := time.Parse(time.RFC3339, e.ExpiresAt); err != nil {
@@ -295,5 +526,3 @@ func (m *SecretMetadata) ExpiresWithinDays(days int) bool {
 	}
 	return false
 }
-
-
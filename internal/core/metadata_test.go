@@ -2,7 +2,9 @@ package core
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseMetadata_Valid(t *testing.T) {
@@ -296,6 +298,61 @@ keys:
 	}
 }
 
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "90d", want: 90 * 24 * time.Hour},
+		{in: "2w", want: 14 * 24 * time.Hour},
+		{in: "720h", want: 720 * time.Hour},
+		{in: "0d", wantErr: true},
+		{in: "-5h", wantErr: true},
+		{in: "3mo", wantErr: true},
+		{in: "d", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseInterval(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseInterval(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseInterval(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMetadata_InvalidRotationInterval(t *testing.T) {
+	yaml := `
+shortName: my-secret
+manifestPath: apps/my-app/sealed-secret.yaml
+sealedSecret:
+  name: my-secret
+  namespace: default
+  scope: strict
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/my-project/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+      interval: quarterly
+`
+	_, err := ParseMetadata([]byte(yaml))
+	if err == nil || !strings.Contains(err.Error(), "rotation.interval") {
+		t.Errorf("expected rotation.interval error, got %v", err)
+	}
+}
+
 func TestSecretMetadata_IsRetired(t *testing.T) {
 	m := &SecretMetadata{Status: "retired"}
 	if !m.IsRetired() {
@@ -336,3 +393,188 @@ func TestGSMRef_NumericVersionOnly(t *testing.T) {
 		})
 	}
 }
+
+func TestStoreRef_ValidateForStore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		kind     string
+		resource string
+		wantErr  bool
+	}{
+		{"gsm valid", StoreKindGSM, "projects/my-project/secrets/my-app-api_key", false},
+		{"gsm rejects dots", StoreKindGSM, "projects/my-project/secrets/my.app", true},
+		{"gsm rejects uppercase project", StoreKindGSM, "projects/MyProject/secrets/key", true},
+		{"vault valid", StoreKindVault, "projects/team.a/secrets/db-password", false},
+		{"vault rejects extra segments", StoreKindVault, "projects/p/secrets/a/b", true},
+		{"aws valid", StoreKindAWS, "projects/prod/secrets/app+key=v@1", false},
+		{"aws rejects spaces", StoreKindAWS, "projects/prod/secrets/app key", true},
+		{"aws rejects long name", StoreKindAWS, "projects/p/secrets/" + strings.Repeat("a", 511), true},
+		{"file valid", StoreKindFile, "projects/homelab/secrets/any thing", false},
+		{"file rejects extra segments", StoreKindFile, "projects/p/secrets/a/b", true},
+		{"unknown kind", "azure", "projects/p/secrets/s", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref := &StoreRef{SecretResource: tc.resource, Version: "1"}
+			err := ref.ValidateForStore(tc.kind)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateForStore(%q) error = %v, wantErr %v", tc.kind, err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrValidation) {
+				t.Errorf("expected ErrValidation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretMetadata_ValidateStoreRefs(t *testing.T) {
+	m := &SecretMetadata{
+		ShortName: "app",
+		Keys: []KeyMetadata{
+			{KeyName: "a", GSM: &GSMRef{SecretResource: "projects/p/secrets/ok", Version: "1"}},
+			{KeyName: "b", Computed: &ComputedConfig{GSM: &GSMRef{SecretResource: "projects/p/secrets/has space", Version: "1"}}},
+		},
+	}
+
+	if err := m.ValidateStoreRefs(StoreKindAWS); err == nil {
+		t.Fatal("expected error for invalid computed store reference")
+	} else if !strings.Contains(err.Error(), "keys[1]") {
+		t.Errorf("error should identify keys[1], got %v", err)
+	}
+
+	m.Keys[1].Computed.GSM.SecretResource = "projects/p/secrets/fine"
+	if err := m.ValidateStoreRefs(StoreKindAWS); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSecretMetadata_Environments(t *testing.T) {
+	m := &SecretMetadata{
+		ManifestPath: "apps/{env}/sealed-secret.yaml",
+		Environments: []string{"prod", "staging"},
+	}
+	if !m.TargetsEnv("prod") || !m.TargetsEnv("staging") {
+		t.Error("should target listed environments")
+	}
+	if m.TargetsEnv("dev") || m.TargetsEnv("") {
+		t.Error("should not target unlisted environments or the top-level profile")
+	}
+	if got := m.ManifestPathFor("prod"); got != "apps/prod/sealed-secret.yaml" {
+		t.Errorf("ManifestPathFor(prod) = %q", got)
+	}
+
+	plain := &SecretMetadata{ManifestPath: "apps/a/sealed-secret.yaml"}
+	if !plain.TargetsEnv("") || plain.TargetsEnv("prod") {
+		t.Error("secret without environments belongs to the top-level profile only")
+	}
+	if got := plain.ManifestPathFor(""); got != plain.ManifestPath {
+		t.Errorf("ManifestPathFor(\"\") = %q", got)
+	}
+}
+
+func TestParseMetadata_EnvironmentsRequirePlaceholder(t *testing.T) {
+	base := `
+shortName: my-secret
+manifestPath: MANIFEST
+ENVS
+sealedSecret:
+  name: my-secret
+  namespace: default
+  scope: strict
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/p/secrets/s
+      version: "1"
+    rotation:
+      mode: static
+`
+	tests := []struct {
+		name     string
+		manifest string
+		envs     string
+		wantErr  bool
+	}{
+		{"several envs with placeholder", "apps/{env}/ss.yaml", "environments: [prod, staging]", false},
+		{"single env without placeholder", "apps/prod/ss.yaml", "environments: [prod]", false},
+		{"several envs without placeholder", "apps/ss.yaml", "environments: [prod, staging]", true},
+		{"placeholder without envs", "apps/{env}/ss.yaml", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := strings.Replace(strings.Replace(base, "MANIFEST", tt.manifest, 1), "ENVS", tt.envs, 1)
+			_, err := ParseMetadata([]byte(yaml))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMetadata error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMetadata_InvalidPairedKey(t *testing.T) {
+	yaml := `
+shortName: my-secret
+manifestPath: apps/my-app/sealed-secret.yaml
+sealedSecret:
+  name: my-secret
+  namespace: default
+  scope: strict
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/my-project/secrets/password
+      version: "1"
+    rotation:
+      mode: generated
+      generator:
+        kind: bcrypt
+        pairedKey: password_hash
+`
+	_, err := ParseMetadata([]byte(yaml))
+	if err == nil || !strings.Contains(err.Error(), `"password_hash" not found`) {
+		t.Errorf("expected missing paired key error, got %v", err)
+	}
+}
+
+func TestParseMetadata_OutputKeyGenerated(t *testing.T) {
+	yaml := `
+shortName: my-secret
+manifestPath: apps/my-app/sealed-secret.yaml
+sealedSecret:
+  name: my-secret
+  namespace: default
+  scope: strict
+keys:
+  - keyName: id_ed25519
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/my-project/secrets/key
+      version: "1"
+    rotation:
+      mode: generated
+      generator:
+        kind: sshEd25519
+        outputs:
+          publicKey: id_ed25519.pub
+  - keyName: id_ed25519.pub
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/my-project/secrets/pub
+      version: "1"
+    rotation:
+      mode: generated
+      generator:
+        kind: randomHex
+`
+	_, err := ParseMetadata([]byte(yaml))
+	if err == nil || !strings.Contains(err.Error(), "outputs.publicKey") {
+		t.Errorf("expected outputs.publicKey error, got %v", err)
+	}
+}
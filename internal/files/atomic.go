@@ -102,15 +102,15 @@ func (w *AtomicWriter) validate(content []byte) error {
 }
 
 // YAMLKindValidator returns a validator that checks for a specific YAML kind.
+// Multi-document files pass if any document has that kind.
 func YAMLKindValidator(expectedKind string) Validator {
 	return func(content []byte) error {
-		// Simple check: look for "kind: <expectedKind>" in content
-		// A more robust implementation would parse the YAML
-		kindPattern := []byte("kind: " + expectedKind)
-		if !containsBytes(content, kindPattern) {
-			return fmt.Errorf("expected kind: %s", expectedKind)
+		for _, doc := range SplitYAMLDocuments(content) {
+			if doc.Kind == expectedKind {
+				return nil
+			}
 		}
-		return nil
+		return fmt.Errorf("expected kind: %s", expectedKind)
 	}
 }
 
@@ -123,19 +123,3 @@ func NonEmptyValidator() Validator {
 		return nil
 	}
 }
-
-func containsBytes(data, pattern []byte) bool {
-	for i := 0; i <= len(data)-len(pattern); i++ {
-		match := true
-		for j := 0; j < len(pattern); j++ {
-			if data[i+j] != pattern[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return true
-		}
-	}
-	return false
-}
@@ -157,6 +157,16 @@ func TestYAMLKindValidator(t *testing.T) {
 			content: "foo: bar",
 			wantErr: true,
 		},
+		{
+			name:    "multi-document",
+			content: "apiVersion: apps/v1\nkind: Deployment\n---\napiVersion: bitnami.com/v1alpha1\nkind: SealedSecret\n",
+			wantErr: false,
+		},
+		{
+			name:    "kind only in a comment",
+			content: "# kind: SealedSecret\napiVersion: v1\nkind: Secret\n",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,122 @@
+package files
+
+import (
+	"bytes"
+
+	"sigs.k8s.io/yaml"
+)
+
+// YAMLDocument locates one document within a (possibly multi-document) YAML
+// file. Start and End bound the document body: separator lines ("---") and
+// comment or blank lines before and after the body are excluded, so they
+// survive a ReplaceYAMLDocument untouched.
+type YAMLDocument struct {
+	Index int    // position among the file's non-empty documents
+	Kind  string // top-level "kind", or "" if absent or unparseable
+	Start int
+	End   int
+}
+
+// Content returns the document body from the file it was split from.
+func (d YAMLDocument) Content(data []byte) []byte {
+	return data[d.Start:d.End]
+}
+
+// SplitYAMLDocuments returns the non-empty documents in data, in file order.
+// Documents containing only comments or whitespace are skipped.
+func SplitYAMLDocuments(data []byte) []YAMLDocument {
+	var docs []YAMLDocument
+	start, end := -1, -1
+
+	flush := func() {
+		if start >= 0 {
+			doc := YAMLDocument{Index: len(docs), Start: start, End: end}
+			doc.Kind = yamlKind(doc.Content(data))
+			docs = append(docs, doc)
+		}
+		start, end = -1, -1
+	}
+
+	for pos := 0; pos < len(data); {
+		lineEnd := bytes.IndexByte(data[pos:], '\n')
+		next := len(data)
+		if lineEnd >= 0 {
+			next = pos + lineEnd + 1
+		}
+		line := data[pos:next]
+
+		switch {
+		case isDocumentSeparator(line):
+			flush()
+		case !isCommentOrBlank(line):
+			if start < 0 {
+				start = pos
+			}
+			end = next
+		}
+		pos = next
+	}
+	flush()
+
+	return docs
+}
+
+// ReplaceYAMLDocument returns a copy of data with the body of doc replaced.
+// Everything outside the body, including other documents, separators and
+// comments, is kept byte-for-byte.
+func ReplaceYAMLDocument(data []byte, doc YAMLDocument, replacement []byte) []byte {
+	// Keep the line structure when the original body ended without a newline
+	// (last document in a file with no trailing newline) or with one.
+	if doc.End < len(data) && !bytes.HasSuffix(replacement, []byte("\n")) {
+		replacement = append(append([]byte(nil), replacement...), '\n')
+	}
+
+	out := make([]byte, 0, len(data)-(doc.End-doc.Start)+len(replacement))
+	out = append(out, data[:doc.Start]...)
+	out = append(out, replacement...)
+	out = append(out, data[doc.End:]...)
+	return out
+}
+
+// AppendYAMLDocument returns data with document appended after a "---"
+// separator. Empty data yields document unchanged.
+func AppendYAMLDocument(data, document []byte) []byte {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return document
+	}
+	out := append([]byte(nil), data...)
+	if !bytes.HasSuffix(out, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	out = append(out, "---\n"...)
+	return append(out, document...)
+}
+
+// isDocumentSeparator reports whether line is a "---" document marker,
+// optionally followed by whitespace or a comment.
+func isDocumentSeparator(line []byte) bool {
+	line = bytes.TrimRight(line, "\r\n")
+	if !bytes.HasPrefix(line, []byte("---")) {
+		return false
+	}
+	rest := bytes.TrimLeft(line[3:], " \t")
+	return len(rest) == 0 || (len(rest) < len(line[3:]) && rest[0] == '#')
+}
+
+// isCommentOrBlank reports whether line is blank or a column-0 comment.
+// Indented "#" lines are treated as content since they may belong to a
+// block scalar.
+func isCommentOrBlank(line []byte) bool {
+	return len(bytes.TrimSpace(line)) == 0 || line[0] == '#'
+}
+
+// yamlKind extracts the top-level kind of a single YAML document.
+func yamlKind(doc []byte) string {
+	var obj struct {
+		Kind string `json:"kind"`
+	}
+	if err := yaml.Unmarshal(doc, &obj); err != nil {
+		return ""
+	}
+	return obj.Kind
+}
@@ -0,0 +1,123 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+const multiDoc = `# Bundle for my-app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-app   # keep this comment
+---
+# The sealed credentials
+apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: my-app
+  namespace: default
+spec:
+  encryptedData:
+    password: OLD
+# trailing note
+--- # service follows
+apiVersion: v1
+kind: Service
+metadata:
+  name: my-app
+`
+
+func TestSplitYAMLDocuments(t *testing.T) {
+	docs := SplitYAMLDocuments([]byte(multiDoc))
+	if len(docs) != 3 {
+		t.Fatalf("got %d documents, want 3", len(docs))
+	}
+
+	wantKinds := []string{"Deployment", "SealedSecret", "Service"}
+	for i, doc := range docs {
+		if doc.Kind != wantKinds[i] {
+			t.Errorf("docs[%d].Kind = %q, want %q", i, doc.Kind, wantKinds[i])
+		}
+		if doc.Index != i {
+			t.Errorf("docs[%d].Index = %d", i, doc.Index)
+		}
+	}
+
+	// Comments around the body are not part of it
+	body := string(docs[1].Content([]byte(multiDoc)))
+	if body[:len("apiVersion")] != "apiVersion" || strings.Contains(body, "#") {
+		t.Errorf("SealedSecret body should exclude surrounding comments, got %q", body)
+	}
+}
+
+func TestSplitYAMLDocuments_SkipsEmpty(t *testing.T) {
+	docs := SplitYAMLDocuments([]byte("---\n# only a comment\n---\nkind: Secret\n---\n"))
+	if len(docs) != 1 || docs[0].Kind != "Secret" {
+		t.Errorf("got %+v, want a single Secret document", docs)
+	}
+}
+
+func TestReplaceYAMLDocument_KeepsRestIntact(t *testing.T) {
+	data := []byte(multiDoc)
+	docs := SplitYAMLDocuments(data)
+
+	replacement := "apiVersion: bitnami.com/v1alpha1\nkind: SealedSecret\nmetadata:\n  name: my-app\n  namespace: default\nspec:\n  encryptedData:\n    password: NEW\n"
+	out := string(ReplaceYAMLDocument(data, docs[1], []byte(replacement)))
+
+	before := multiDoc[:docs[1].Start]
+	after := multiDoc[docs[1].End:]
+	if out != before+replacement+after {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if !strings.Contains(out, "# keep this comment") || !strings.Contains(out, "# trailing note") ||
+		!strings.Contains(out, "--- # service follows") {
+		t.Error("comments and separators must be preserved")
+	}
+
+	// Re-splitting finds the same layout
+	if got := SplitYAMLDocuments([]byte(out)); len(got) != 3 || got[1].Kind != "SealedSecret" {
+		t.Errorf("re-split = %+v", got)
+	}
+}
+
+func TestReplaceYAMLDocument_NoTrailingNewline(t *testing.T) {
+	data := []byte("kind: Deployment\n---\nkind: SealedSecret\nold: true")
+	docs := SplitYAMLDocuments(data)
+	out := ReplaceYAMLDocument(data, docs[1], []byte("kind: SealedSecret\nnew: true\n"))
+	if string(out) != "kind: Deployment\n---\nkind: SealedSecret\nnew: true\n" {
+		t.Errorf("got %q", out)
+	}
+
+	// Replacement in the middle gains a newline before the separator
+	out = ReplaceYAMLDocument(data, docs[0], []byte("kind: Deployment"))
+	if string(out) != string(data) {
+		t.Errorf("got %q, want %q", out, data)
+	}
+}
+
+func TestAppendYAMLDocument(t *testing.T) {
+	if got := AppendYAMLDocument(nil, []byte("kind: A\n")); string(got) != "kind: A\n" {
+		t.Errorf("empty data: got %q", got)
+	}
+	if got := AppendYAMLDocument([]byte("kind: A"), []byte("kind: B\n")); string(got) != "kind: A\n---\nkind: B\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestIsDocumentSeparator(t *testing.T) {
+	tests := map[string]bool{
+		"---\n":           true,
+		"---\r\n":         true,
+		"--- \n":          true,
+		"--- # comment\n": true,
+		"----\n":          false,
+		"---foo\n":        false,
+		"  ---\n":         false,
+	}
+	for line, want := range tests {
+		if got := isDocumentSeparator([]byte(line)); got != want {
+			t.Errorf("isDocumentSeparator(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
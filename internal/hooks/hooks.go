@@ -0,0 +1,126 @@
+// Package hooks runs rotation hooks: commands or local HTTP endpoints that
+// verify a new credential or tell an application to reload it.
+//
+// A hook receives an Event describing the rotation, the secret's short name,
+// key names and store versions, but never a secret value. Commands get it as
+// JSON on stdin and as WAXSEAL_* environment variables; URLs get it as a JSON
+// POST body. A command fails on a non-zero exit, a URL on a non-2xx status.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// Event describes the rotation a hook runs for.
+type Event struct {
+	Phase     string            `json:"phase"` // core.HookPhasePreRotate or core.HookPhasePostRotate
+	ShortName string            `json:"shortName"`
+	Keys      []string          `json:"keys"`
+	Versions  map[string]string `json:"versions,omitempty"` // new store versions, post-rotate only
+}
+
+// Result is the outcome of one hook.
+type Result struct {
+	Hook     string // core.HookConfig.Label
+	Phase    string
+	Err      error
+	Duration time.Duration
+}
+
+// OK reports whether the hook succeeded.
+func (r Result) OK() bool {
+	return r.Err == nil
+}
+
+// Runner runs hooks.
+type Runner struct {
+	Dir    string       // working directory of commands
+	Output io.Writer    // receives command output; nil discards it
+	Client *http.Client // for URL hooks; nil uses http.DefaultClient
+}
+
+// RunAll runs hooks in order and stops at the first failure. It returns the
+// results of the hooks that ran.
+func (r *Runner) RunAll(ctx context.Context, hooks []core.HookConfig, event Event) []Result {
+	var results []Result
+	for _, h := range hooks {
+		result := r.Run(ctx, h, event)
+		results = append(results, result)
+		if !result.OK() {
+			break
+		}
+	}
+	return results
+}
+
+// Run runs one hook within its timeout.
+func (r *Runner) Run(ctx context.Context, h core.HookConfig, event Event) Result {
+	ctx, cancel := context.WithTimeout(ctx, h.TimeoutDuration())
+	defer cancel()
+
+	body, err := json.Marshal(event)
+	start := time.Now()
+	if err == nil {
+		if len(h.Command) > 0 {
+			err = r.runCommand(ctx, h.Command, event, body)
+		} else {
+			err = r.post(ctx, h.URL, body)
+		}
+	}
+	return Result{Hook: h.Label(), Phase: event.Phase, Err: err, Duration: time.Since(start)}
+}
+
+func (r *Runner) runCommand(ctx context.Context, argv []string, event Event, body []byte) error {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = r.Dir
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = r.Output
+	cmd.Stderr = r.Output
+	cmd.Env = append(os.Environ(),
+		"WAXSEAL_HOOK_PHASE="+event.Phase,
+		"WAXSEAL_SHORT_NAME="+event.ShortName,
+		"WAXSEAL_KEYS="+strings.Join(event.Keys, ","),
+	)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out: %w", ctx.Err())
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Runner) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+func testEvent() Event {
+	return Event{
+		Phase:     core.HookPhasePostRotate,
+		ShortName: "app",
+		Keys:      []string{"password", "username"},
+		Versions:  map[string]string{"password": "4"},
+	}
+}
+
+func TestRun_Command(t *testing.T) {
+	var out bytes.Buffer
+	r := &Runner{Dir: t.TempDir(), Output: &out}
+
+	hook := core.HookConfig{Command: []string{"sh", "-c", `echo "$WAXSEAL_HOOK_PHASE $WAXSEAL_SHORT_NAME $WAXSEAL_KEYS"; cat`}}
+	result := r.Run(context.Background(), hook, testEvent())
+	if !result.OK() {
+		t.Fatalf("Run failed: %v", result.Err)
+	}
+
+	lines := strings.SplitN(out.String(), "\n", 2)
+	if lines[0] != "postRotate app password,username" {
+		t.Errorf("environment = %q", lines[0])
+	}
+	var got Event
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatalf("stdin is not the JSON event: %v", err)
+	}
+	if got.ShortName != "app" || got.Versions["password"] != "4" {
+		t.Errorf("event = %+v", got)
+	}
+}
+
+func TestRun_CommandFailure(t *testing.T) {
+	r := &Runner{Dir: t.TempDir()}
+
+	result := r.Run(context.Background(), core.HookConfig{Command: []string{"sh", "-c", "exit 3"}}, testEvent())
+	if result.OK() {
+		t.Error("expected a non-zero exit to fail")
+	}
+
+	result = r.Run(context.Background(), core.HookConfig{Command: []string{"sleep", "5"}, Timeout: "50ms"}, testEvent())
+	if result.OK() || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", result.Err)
+	}
+}
+
+func TestRun_URL(t *testing.T) {
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	r := &Runner{Client: srv.Client()}
+	result := r.Run(context.Background(), core.HookConfig{URL: srv.URL + "/reload"}, testEvent())
+	if !result.OK() {
+		t.Fatalf("Run failed: %v", result.Err)
+	}
+	if got.ShortName != "app" || len(got.Keys) != 2 {
+		t.Errorf("event = %+v", got)
+	}
+
+	result = r.Run(context.Background(), core.HookConfig{URL: srv.URL + "/fail"}, testEvent())
+	if result.OK() || !strings.Contains(result.Err.Error(), "503") {
+		t.Errorf("expected a 503 failure, got %v", result.Err)
+	}
+}
+
+func TestRunAll_StopsAtFailure(t *testing.T) {
+	r := &Runner{Dir: t.TempDir()}
+	list := []core.HookConfig{
+		{Name: "first", Command: []string{"true"}},
+		{Name: "second", Command: []string{"false"}},
+		{Name: "third", Command: []string{"true"}},
+	}
+
+	results := r.RunAll(context.Background(), list, testEvent())
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if !results[0].OK() || results[1].OK() || results[1].Hook != "second" {
+		t.Errorf("results = %+v", results)
+	}
+}
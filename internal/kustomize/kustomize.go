@@ -0,0 +1,263 @@
+// Package kustomize resolves the namespace and name a manifest ends up with
+// after the kustomizations that include it are applied.
+//
+// Only the identity-changing fields (namespace, namePrefix, nameSuffix) are
+// understood. Every kustomization in the repo is indexed, so both enclosing
+// kustomizations and sibling overlays (overlays/prod listing ../../base) are
+// followed up to the top-level kustomizations that build them. A manifest
+// can only be sealed for one identity; when several overlays build it under
+// different identities, resolution fails with ErrAmbiguous.
+package kustomize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// fileNames are the names kustomize recognizes for a kustomization file.
+var fileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// Kustomization is the subset of kustomization.yaml that waxseal reads.
+type Kustomization struct {
+	Namespace  string   `json:"namespace,omitempty"`
+	NamePrefix string   `json:"namePrefix,omitempty"`
+	NameSuffix string   `json:"nameSuffix,omitempty"`
+	Resources  []string `json:"resources,omitempty"`
+	Bases      []string `json:"bases,omitempty"` // deprecated alias of resources
+}
+
+// Transform is the combined identity change applied to a manifest.
+type Transform struct {
+	Namespace  string // overrides the manifest namespace if set
+	NamePrefix string
+	NameSuffix string
+}
+
+// IsZero reports whether the transform leaves identities unchanged.
+func (t Transform) IsZero() bool {
+	return t == Transform{}
+}
+
+// Apply returns the effective namespace and name of a manifest.
+func (t Transform) Apply(namespace, name string) (string, string) {
+	if t.Namespace != "" {
+		namespace = t.Namespace
+	}
+	return namespace, t.NamePrefix + name + t.NameSuffix
+}
+
+// Unapply maps an effective identity back to the one written in the
+// manifest. The namespace is "" (unknown) when the transform overrides it.
+func (t Transform) Unapply(namespace, name string) (string, string) {
+	if t.Namespace != "" {
+		namespace = ""
+	}
+	if strings.HasPrefix(name, t.NamePrefix) && strings.HasSuffix(name, t.NameSuffix) &&
+		len(name) > len(t.NamePrefix)+len(t.NameSuffix) {
+		name = strings.TrimSuffix(strings.TrimPrefix(name, t.NamePrefix), t.NameSuffix)
+	}
+	return namespace, name
+}
+
+// ErrAmbiguous is returned when overlays build a manifest under several
+// different identities.
+var ErrAmbiguous = errors.New("manifest is built by several overlays with different identities")
+
+// Overlay is a top-level kustomization that builds a manifest, with the
+// transform it applies.
+type Overlay struct {
+	Dir       string // repo-relative directory; "" if nothing includes the manifest
+	Transform Transform
+}
+
+// Index holds every kustomization under a repo root.
+type Index struct {
+	root           string
+	kustomizations map[string]*Kustomization // by absolute directory
+}
+
+// LoadIndex reads every kustomization under repoRoot. Hidden directories
+// (.git, .waxseal, ...) are skipped. Directories above repoRoot are never
+// consulted.
+func LoadIndex(repoRoot string) (*Index, error) {
+	root, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{root: root, kustomizations: make(map[string]*Kustomization)}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		k, err := load(path)
+		if err != nil {
+			return err
+		}
+		if k != nil {
+			idx.kustomizations[path] = k
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// ForFile returns the transform the kustomizations that build the manifest
+// at path apply to it. Directories above repoRoot are never consulted.
+func ForFile(repoRoot, path string) (Transform, error) {
+	idx, err := LoadIndex(repoRoot)
+	if err != nil {
+		return Transform{}, err
+	}
+	return idx.ForFile(path)
+}
+
+// ForFile returns the single transform under which the manifest at path is
+// built. It fails with ErrAmbiguous if overlays disagree.
+func (idx *Index) ForFile(path string) (Transform, error) {
+	overlays, err := idx.Overlays(path)
+	if err != nil {
+		return Transform{}, err
+	}
+	for _, o := range overlays[1:] {
+		if o.Transform != overlays[0].Transform {
+			var names []string
+			for _, o := range overlays {
+				names = append(names, o.Dir)
+			}
+			return Transform{}, fmt.Errorf("%w (%s); use a separate manifest per overlay",
+				ErrAmbiguous, strings.Join(names, ", "))
+		}
+	}
+	return overlays[0].Transform, nil
+}
+
+// Overlays returns every top-level kustomization that builds the manifest
+// at path, sorted by directory. A manifest nothing includes is returned as
+// a single Overlay with the zero transform.
+func (idx *Index) Overlays(path string) ([]Overlay, error) {
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	overlays := idx.overlays(target, map[string]bool{})
+	sort.Slice(overlays, func(i, j int) bool { return overlays[i].Dir < overlays[j].Dir })
+	return overlays, nil
+}
+
+// overlays walks up the include graph from target. visiting guards against
+// kustomizations that include each other.
+func (idx *Index) overlays(target string, visiting map[string]bool) []Overlay {
+	var parents []string
+	for dir, k := range idx.kustomizations {
+		if dir != target && !visiting[dir] && idx.includes(k, dir, target) {
+			parents = append(parents, dir)
+		}
+	}
+	if len(parents) == 0 {
+		rel := ""
+		if info, err := os.Stat(target); err == nil && info.IsDir() {
+			rel, _ = filepath.Rel(idx.root, target)
+		}
+		return []Overlay{{Dir: filepath.ToSlash(rel)}}
+	}
+
+	visiting[target] = true
+	defer delete(visiting, target)
+
+	var result []Overlay
+	for _, dir := range parents {
+		k := idx.kustomizations[dir]
+		for _, outer := range idx.overlays(dir, visiting) {
+			// Outer kustomizations wrap inner names and override namespaces
+			t := Transform{
+				Namespace:  k.Namespace,
+				NamePrefix: outer.Transform.NamePrefix + k.NamePrefix,
+				NameSuffix: k.NameSuffix + outer.Transform.NameSuffix,
+			}
+			if outer.Transform.Namespace != "" {
+				t.Namespace = outer.Transform.Namespace
+			}
+			result = append(result, Overlay{Dir: outer.Dir, Transform: t})
+		}
+	}
+	return result
+}
+
+// load reads the kustomization in dir, or returns nil if there is none.
+func load(dir string) (*Kustomization, error) {
+	for _, name := range fileNames {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		var k Kustomization
+		if err := yaml.Unmarshal(data, &k); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return &k, nil
+	}
+	return nil, nil
+}
+
+// includes reports whether the kustomization k in dir lists target as a
+// resource. A directory resource means the kustomization in it; a directory
+// without one (which kustomize itself rejects) is treated as including
+// everything below it that no nested kustomization claims.
+func (idx *Index) includes(k *Kustomization, dir, target string) bool {
+	for _, r := range append(append([]string(nil), k.Resources...), k.Bases...) {
+		if strings.Contains(r, "://") {
+			continue // remote resource
+		}
+		resource := filepath.Join(dir, r)
+		if !isWithin(idx.root, resource) {
+			continue
+		}
+		if resource == target {
+			return true
+		}
+		if isWithin(resource, target) && !idx.claimed(resource, filepath.Dir(target)) {
+			return true
+		}
+	}
+	return false
+}
+
+// claimed reports whether any directory from top down to dir holds a
+// kustomization.
+func (idx *Index) claimed(top, dir string) bool {
+	for {
+		if _, ok := idx.kustomizations[dir]; ok {
+			return true
+		}
+		if dir == top || !isWithin(top, dir) {
+			return false
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// isWithin reports whether path is parent or a descendant of it.
+func isWithin(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,192 @@
+package kustomize
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestForFile_Nested(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "apps", "kustomization.yaml"), `
+namespace: prod
+namePrefix: team-
+resources:
+  - billing
+`)
+	writeFile(t, filepath.Join(root, "apps", "billing", "kustomization.yaml"), `
+namespace: billing
+namePrefix: billing-
+nameSuffix: -v2
+resources:
+  - sealed-secret.yaml
+`)
+	manifest := filepath.Join(root, "apps", "billing", "sealed-secret.yaml")
+	writeFile(t, manifest, "kind: SealedSecret\n")
+
+	tr, err := ForFile(root, manifest)
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	want := Transform{Namespace: "prod", NamePrefix: "team-billing-", NameSuffix: "-v2"}
+	if tr != want {
+		t.Errorf("transform = %+v, want %+v", tr, want)
+	}
+
+	ns, name := tr.Apply("", "db")
+	if ns != "prod" || name != "team-billing-db-v2" {
+		t.Errorf("Apply = %s/%s, want prod/team-billing-db-v2", ns, name)
+	}
+	ns, name = tr.Unapply("prod", "team-billing-db-v2")
+	if ns != "" || name != "db" {
+		t.Errorf("Unapply = %q/%q, want \"\"/db", ns, name)
+	}
+}
+
+func TestForFile_NotListed(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app", "kustomization.yaml"), `
+namespace: prod
+resources:
+  - deployment.yaml
+`)
+	manifest := filepath.Join(root, "app", "sealed-secret.yaml")
+	writeFile(t, manifest, "kind: SealedSecret\n")
+
+	tr, err := ForFile(root, manifest)
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if !tr.IsZero() {
+		t.Errorf("manifest not listed as a resource should not be transformed, got %+v", tr)
+	}
+}
+
+func TestForFile_DirectoryResourceAndBases(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "kustomization.yml"), `
+bases:
+  - apps
+namespace: shared
+`)
+	manifest := filepath.Join(root, "apps", "db", "sealed-secret.yaml")
+	writeFile(t, manifest, "kind: SealedSecret\n")
+
+	tr, err := ForFile(root, manifest)
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if tr.Namespace != "shared" {
+		t.Errorf("namespace = %q, want shared", tr.Namespace)
+	}
+}
+
+func TestForFile_IgnoresAboveRoot(t *testing.T) {
+	outer := t.TempDir()
+	writeFile(t, filepath.Join(outer, "kustomization.yaml"), "namespace: outside\nresources:\n  - repo\n")
+	root := filepath.Join(outer, "repo")
+	manifest := filepath.Join(root, "sealed-secret.yaml")
+	writeFile(t, manifest, "kind: SealedSecret\n")
+
+	tr, err := ForFile(root, manifest)
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if !tr.IsZero() {
+		t.Errorf("kustomizations above the repo root must be ignored, got %+v", tr)
+	}
+}
+
+func TestForFile_SiblingOverlay(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app", "base", "kustomization.yaml"), `
+namePrefix: app-
+resources:
+  - sealed-secret.yaml
+`)
+	writeFile(t, filepath.Join(root, "app", "overlays", "prod", "kustomization.yaml"), `
+namespace: prod
+resources:
+  - ../../base
+`)
+	manifest := filepath.Join(root, "app", "base", "sealed-secret.yaml")
+	writeFile(t, manifest, "kind: SealedSecret\n")
+
+	tr, err := ForFile(root, manifest)
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	want := Transform{Namespace: "prod", NamePrefix: "app-"}
+	if tr != want {
+		t.Errorf("transform = %+v, want %+v", tr, want)
+	}
+}
+
+func TestForFile_AmbiguousOverlays(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "base", "kustomization.yaml"), "resources:\n  - sealed-secret.yaml\n")
+	writeFile(t, filepath.Join(root, "overlays", "prod", "kustomization.yaml"), "namespace: prod\nresources:\n  - ../../base\n")
+	writeFile(t, filepath.Join(root, "overlays", "staging", "kustomization.yaml"), "namespace: staging\nresources:\n  - ../../base\n")
+	manifest := filepath.Join(root, "base", "sealed-secret.yaml")
+	writeFile(t, manifest, "kind: SealedSecret\n")
+
+	_, err := ForFile(root, manifest)
+	if !errors.Is(err, ErrAmbiguous) {
+		t.Fatalf("expected ErrAmbiguous, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "overlays/prod") || !strings.Contains(err.Error(), "overlays/staging") {
+		t.Errorf("error should name both overlays: %v", err)
+	}
+
+	idx, err := LoadIndex(root)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	overlays, err := idx.Overlays(manifest)
+	if err != nil {
+		t.Fatalf("Overlays failed: %v", err)
+	}
+	if len(overlays) != 2 || overlays[0].Dir != "overlays/prod" || overlays[0].Transform.Namespace != "prod" ||
+		overlays[1].Dir != "overlays/staging" || overlays[1].Transform.Namespace != "staging" {
+		t.Errorf("overlays = %+v", overlays)
+	}
+}
+
+func TestForFile_OverlaysAgreeing(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "base", "kustomization.yaml"), "namespace: shared\nresources:\n  - sealed-secret.yaml\n")
+	writeFile(t, filepath.Join(root, "overlays", "a", "kustomization.yaml"), "resources:\n  - ../../base\n")
+	writeFile(t, filepath.Join(root, "overlays", "b", "kustomization.yaml"), "resources:\n  - ../../base\n")
+	manifest := filepath.Join(root, "base", "sealed-secret.yaml")
+	writeFile(t, manifest, "kind: SealedSecret\n")
+
+	tr, err := ForFile(root, manifest)
+	if err != nil {
+		t.Fatalf("ForFile failed: %v", err)
+	}
+	if tr.Namespace != "shared" {
+		t.Errorf("namespace = %q, want shared", tr.Namespace)
+	}
+}
+
+func TestTransform_UnapplyWithoutAffixes(t *testing.T) {
+	tr := Transform{NamePrefix: "p-"}
+	if _, name := tr.Unapply("ns", "other"); name != "other" {
+		t.Errorf("name = %q, want unchanged", name)
+	}
+	if ns, _ := (Transform{}).Unapply("ns", "x"); ns != "ns" {
+		t.Errorf("namespace = %q, want ns", ns)
+	}
+}
@@ -4,6 +4,7 @@ package reminder
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/shermanhuman/waxseal/internal/core"
@@ -25,9 +26,17 @@ type SyncResult struct {
 	Created int
 	Updated int
 	Skipped int
+	Closed  int // issues closed, by the issues provider
 	Errors  []error
 }
 
+// calendarOwnerProperty marks the events waxseal creates, as a private
+// extended property, so they can be listed for reconciliation.
+const (
+	calendarOwnerProperty = "waxseal"
+	calendarOwnerValue    = "reminder"
+)
+
 // GoogleCalendarProvider implements Provider using Google Calendar API.
 type GoogleCalendarProvider struct {
 	calendarID   string
@@ -36,9 +45,11 @@ type GoogleCalendarProvider struct {
 }
 
 // NewGoogleCalendarProvider creates a provider using Application Default Credentials.
-func NewGoogleCalendarProvider(ctx context.Context, calendarID string, leadTimeDays []int) (*GoogleCalendarProvider, error) {
+// Extra options override the client, e.g. its endpoint in tests.
+func NewGoogleCalendarProvider(ctx context.Context, calendarID string, leadTimeDays []int, opts ...option.ClientOption) (*GoogleCalendarProvider, error) {
 	// Use ADC for authentication
-	service, err := calendar.NewService(ctx, option.WithScopes(calendar.CalendarEventsScope))
+	opts = append([]option.ClientOption{option.WithScopes(calendar.CalendarEventsScope)}, opts...)
+	service, err := calendar.NewService(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("create calendar service: %w", err)
 	}
@@ -58,65 +69,74 @@ func NewGoogleCalendarProvider(ctx context.Context, calendarID string, leadTimeD
 func (p *GoogleCalendarProvider) SyncReminders(ctx context.Context, secrets []*core.SecretMetadata) (*SyncResult, error) {
 	result := &SyncResult{}
 
-	for _, secret := range secrets {
-		if secret.IsRetired() {
-			result.Skipped++
-			continue
-		}
-
-		// Check each key for expiry
-		for _, key := range secret.Keys {
-			if key.Expiry == nil || key.Expiry.ExpiresAt == "" {
-				continue
+	// Create reminder events for each lead time
+	planned, retired := planReminders(secrets, p.leadTimeDays, time.Now())
+	result.Skipped = retired
+	for _, r := range planned {
+		eventID := generateEventID(r.secret.ShortName, r.key.KeyName, r.leadDays)
+		event := p.createEvent(r.secret, r.key, r.expiresAt, r.leadDays)
+
+		// Check if event exists
+		existing, err := p.service.Events.Get(p.calendarID, eventID).Context(ctx).Do()
+		if err == nil && existing != nil {
+			// Update existing event
+			_, err = p.service.Events.Update(p.calendarID, eventID, event).Context(ctx).Do()
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("update event %s: %w", eventID, err))
+			} else {
+				result.Updated++
 			}
-
-			expiresAt, err := time.Parse(time.RFC3339, key.Expiry.ExpiresAt)
+		} else {
+			// Create new event
+			event.Id = eventID
+			_, err = p.service.Events.Insert(p.calendarID, event).Context(ctx).Do()
 			if err != nil {
-				logging.Warn("invalid expiry date",
-					"secret", secret.ShortName,
-					"key", key.KeyName,
-					"expiresAt", key.Expiry.ExpiresAt,
-				)
-				continue
+				result.Errors = append(result.Errors, fmt.Errorf("create event %s: %w", eventID, err))
+			} else {
+				result.Created++
 			}
+		}
+	}
 
-			// Create reminder events for each lead time
-			for _, days := range p.leadTimeDays {
-				reminderTime := expiresAt.AddDate(0, 0, -days)
-
-				// Skip if reminder time is in the past
-				if reminderTime.Before(time.Now()) {
-					continue
-				}
+	return result, nil
+}
 
-				eventID := generateEventID(secret.ShortName, key.KeyName, days)
-				event := p.createEvent(secret, key, expiresAt, days)
-
-				// Check if event exists
-				existing, err := p.service.Events.Get(p.calendarID, eventID).Context(ctx).Do()
-				if err == nil && existing != nil {
-					// Update existing event
-					_, err = p.service.Events.Update(p.calendarID, eventID, event).Context(ctx).Do()
-					if err != nil {
-						result.Errors = append(result.Errors, fmt.Errorf("update event %s: %w", eventID, err))
-					} else {
-						result.Updated++
-					}
-				} else {
-					// Create new event
-					event.Id = eventID
-					_, err = p.service.Events.Insert(p.calendarID, event).Context(ctx).Do()
-					if err != nil {
-						result.Errors = append(result.Errors, fmt.Errorf("create event %s: %w", eventID, err))
-					} else {
-						result.Created++
-					}
+// ListReminders returns the events waxseal created, found by their private
+// extended property.
+func (p *GoogleCalendarProvider) ListReminders(ctx context.Context) ([]Reminder, error) {
+	var reminders []Reminder
+	err := p.service.Events.List(p.calendarID).
+		PrivateExtendedProperty(calendarOwnerProperty+"="+calendarOwnerValue).
+		Pages(ctx, func(events *calendar.Events) error {
+			for _, event := range events.Items {
+				r := Reminder{ID: event.Id, Title: event.Summary}
+				if event.Start != nil {
+					r.Due = event.Start.Date
 				}
+				reminders = append(reminders, r)
 			}
-		}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
 	}
+	return reminders, nil
+}
 
-	return result, nil
+// ExpectedReminders returns the IDs of the events metadata backs, past
+// lead times included.
+func (p *GoogleCalendarProvider) ExpectedReminders(secrets []*core.SecretMetadata) map[string]bool {
+	expected := make(map[string]bool)
+	backed, _ := backedReminders(secrets, p.leadTimeDays)
+	for _, r := range backed {
+		expected[generateEventID(r.secret.ShortName, r.key.KeyName, r.leadDays)] = true
+	}
+	return expected
+}
+
+// DeleteReminder deletes one event.
+func (p *GoogleCalendarProvider) DeleteReminder(ctx context.Context, r Reminder) error {
+	return p.service.Events.Delete(p.calendarID, r.ID).Context(ctx).Do()
 }
 
 // DeleteReminders removes all calendar events for a secret.
@@ -179,6 +199,9 @@ Manifest: %s
 	return &calendar.Event{
 		Summary:     summary,
 		Description: description,
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{calendarOwnerProperty: calendarOwnerValue},
+		},
 		Start: &calendar.EventDateTime{
 			Date: reminderTime.Format("2006-01-02"),
 		},
@@ -223,6 +246,11 @@ type FakeProvider struct {
 	DeleteCalls []string
 	SyncError   error
 	DeleteError error
+
+	// Reminders are listed by ListReminders; DeleteReminder removes them.
+	Reminders []Reminder
+	// Expected is returned by ExpectedReminders.
+	Expected map[string]bool
 }
 
 type SyncCall struct {
@@ -246,8 +274,24 @@ func (f *FakeProvider) DeleteReminders(ctx context.Context, shortName string) er
 	return f.DeleteError
 }
 
+func (f *FakeProvider) ListReminders(ctx context.Context) ([]Reminder, error) {
+	return slices.Clone(f.Reminders), nil
+}
+
+func (f *FakeProvider) ExpectedReminders(secrets []*core.SecretMetadata) map[string]bool {
+	return f.Expected
+}
+
+func (f *FakeProvider) DeleteReminder(ctx context.Context, r Reminder) error {
+	if f.DeleteError != nil {
+		return f.DeleteError
+	}
+	f.Reminders = slices.DeleteFunc(f.Reminders, func(x Reminder) bool { return x.ID == r.ID })
+	return nil
+}
+
 // Compile-time interface checks
 var (
-	_ Provider = (*GoogleCalendarProvider)(nil)
-	_ Provider = (*FakeProvider)(nil)
+	_ Reconciler = (*GoogleCalendarProvider)(nil)
+	_ Reconciler = (*FakeProvider)(nil)
 )
@@ -0,0 +1,273 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/logging"
+)
+
+// ICSStdout is the ICS provider path that writes to standard output.
+const ICSStdout = "-"
+
+// icsShortNameProperty tags each event with its secret, so DeleteReminders
+// can drop a secret's events without parsing UIDs.
+const icsShortNameProperty = "X-WAXSEAL-SHORTNAME"
+
+// ICSProvider implements Provider by writing an RFC 5545 iCalendar file with
+// one all-day event per expiring key on its expiry date, and an alarm at
+// each lead time. Calendar apps subscribed to the file update events in
+// place: each event's UID is stable for its key.
+type ICSProvider struct {
+	path         string // file path, or ICSStdout
+	stdout       io.Writer
+	leadTimeDays []int
+	now          func() time.Time
+}
+
+// NewICSProvider creates a provider that writes to path, or to standard
+// output if path is ICSStdout.
+func NewICSProvider(path string, leadTimeDays []int) *ICSProvider {
+	if len(leadTimeDays) == 0 {
+		leadTimeDays = []int{30, 7, 1} // Default: 30 days, 7 days, 1 day before
+	}
+	return &ICSProvider{
+		path:         path,
+		stdout:       os.Stdout,
+		leadTimeDays: leadTimeDays,
+		now:          time.Now,
+	}
+}
+
+// SyncReminders rewrites the file with an event for every key of the
+// active secrets that has an expiry date.
+func (p *ICSProvider) SyncReminders(ctx context.Context, secrets []*core.SecretMetadata) (*SyncResult, error) {
+	result := &SyncResult{}
+	var active []*core.SecretMetadata
+	for _, secret := range secrets {
+		if secret.IsRetired() {
+			result.Skipped++
+			continue
+		}
+		active = append(active, secret)
+	}
+
+	data, n := RenderICS(active, p.leadTimeDays, p.now())
+	if err := p.write(data); err != nil {
+		return nil, err
+	}
+	result.Created = n
+	return result, nil
+}
+
+// DeleteReminders removes a secret's events from the file.
+func (p *ICSProvider) DeleteReminders(ctx context.Context, shortName string) error {
+	if p.path == ICSStdout {
+		return nil
+	}
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read calendar: %w", err)
+	}
+
+	var out []string
+	var event []string
+	inEvent, drop := false, false
+	for _, line := range unfoldICS(string(data)) {
+		switch {
+		case line == "BEGIN:VEVENT":
+			inEvent, drop, event = true, false, nil
+		case inEvent && line == icsShortNameProperty+":"+escapeICSText(shortName):
+			drop = true
+		}
+		if !inEvent {
+			out = append(out, line)
+			continue
+		}
+		event = append(event, line)
+		if line == "END:VEVENT" {
+			if !drop {
+				out = append(out, event...)
+			}
+			inEvent = false
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, line := range out {
+		writeICSLine(&buf, line)
+	}
+	return p.write(buf.Bytes())
+}
+
+func (p *ICSProvider) write(data []byte) error {
+	if p.path == ICSStdout {
+		_, err := p.stdout.Write(data)
+		return err
+	}
+	// DTSTAMP is the render time: if nothing else changed, leave the file
+	// alone so a committed calendar does not change on every sync
+	if existing, err := os.ReadFile(p.path); err == nil && sameICSContent(existing, data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		return fmt.Errorf("create calendar directory: %w", err)
+	}
+	if err := files.NewAtomicWriter().Write(p.path, data); err != nil {
+		return fmt.Errorf("write calendar: %w", err)
+	}
+	return nil
+}
+
+// sameICSContent reports whether two calendars differ at most in DTSTAMP.
+func sameICSContent(a, b []byte) bool {
+	withoutStamps := func(data []byte) []string {
+		return slices.DeleteFunc(unfoldICS(string(data)), func(line string) bool {
+			return strings.HasPrefix(line, "DTSTAMP:")
+		})
+	}
+	return slices.Equal(withoutStamps(a), withoutStamps(b))
+}
+
+// RenderICS renders a calendar with one event per key with an expiry date,
+// sorted by secret, and returns it with the number of events.
+func RenderICS(secrets []*core.SecretMetadata, leadTimeDays []int, now time.Time) ([]byte, int) {
+	w := &bytes.Buffer{}
+	writeICSLine(w, "BEGIN:VCALENDAR")
+	writeICSLine(w, "VERSION:2.0")
+	writeICSLine(w, "PRODID:-//waxseal//Secret Expiry Reminders//EN")
+	writeICSLine(w, "CALSCALE:GREGORIAN")
+	writeICSLine(w, "METHOD:PUBLISH")
+	writeICSLine(w, "X-WR-CALNAME:waxseal secret expiry")
+
+	sorted := slices.Clone(secrets)
+	slices.SortFunc(sorted, func(a, b *core.SecretMetadata) int {
+		return strings.Compare(a.ShortName, b.ShortName)
+	})
+
+	leads := slices.Clone(leadTimeDays)
+	slices.Sort(leads)
+	leads = slices.Compact(leads)
+
+	dtstamp := now.UTC().Format("20060102T150405Z")
+	n := 0
+	for _, secret := range sorted {
+		for _, key := range secret.Keys {
+			if key.Expiry == nil || key.Expiry.ExpiresAt == "" {
+				continue
+			}
+			expiresAt, err := time.Parse(time.RFC3339, key.Expiry.ExpiresAt)
+			if err != nil {
+				logging.Warn("invalid expiry date",
+					"secret", secret.ShortName,
+					"key", key.KeyName,
+					"expiresAt", key.Expiry.ExpiresAt,
+				)
+				continue
+			}
+			writeICSEvent(w, secret, key, expiresAt, leads, dtstamp)
+			n++
+		}
+	}
+
+	writeICSLine(w, "END:VCALENDAR")
+	return w.Bytes(), n
+}
+
+func writeICSEvent(w *bytes.Buffer, secret *core.SecretMetadata, key core.KeyMetadata, expiresAt time.Time, leadTimeDays []int, dtstamp string) {
+	day := expiresAt.UTC()
+	summary := fmt.Sprintf("🔐 Secret expires: %s/%s", secret.ShortName, key.KeyName)
+	description := fmt.Sprintf("Secret: %s\nKey: %s\nExpires: %s\nRotation Mode: %s\n\nTo rotate:\n  waxseal rotate %s %s\n\nManifest: %s",
+		secret.ShortName,
+		key.KeyName,
+		day.Format("2006-01-02 15:04 MST"),
+		getRotationMode(key),
+		secret.ShortName,
+		key.KeyName,
+		secret.ManifestPath,
+	)
+
+	writeICSLine(w, "BEGIN:VEVENT")
+	writeICSLine(w, "UID:"+ICSEventUID(secret.ShortName, key.KeyName))
+	writeICSLine(w, "DTSTAMP:"+dtstamp)
+	writeICSLine(w, "DTSTART;VALUE=DATE:"+day.Format("20060102"))
+	writeICSLine(w, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"))
+	writeICSLine(w, "SUMMARY:"+escapeICSText(summary))
+	writeICSLine(w, "DESCRIPTION:"+escapeICSText(description))
+	writeICSLine(w, "TRANSP:TRANSPARENT")
+	writeICSLine(w, icsShortNameProperty+":"+escapeICSText(secret.ShortName))
+	for _, days := range leadTimeDays {
+		alarm := fmt.Sprintf("%s/%s expires in %d days", secret.ShortName, key.KeyName, days)
+		trigger := fmt.Sprintf("-P%dD", days)
+		if days == 0 {
+			alarm = fmt.Sprintf("%s/%s expires TODAY", secret.ShortName, key.KeyName)
+			trigger = "PT0S"
+		}
+		writeICSLine(w, "BEGIN:VALARM")
+		writeICSLine(w, "ACTION:DISPLAY")
+		writeICSLine(w, "DESCRIPTION:"+escapeICSText(alarm))
+		writeICSLine(w, "TRIGGER:"+trigger)
+		writeICSLine(w, "END:VALARM")
+	}
+	writeICSLine(w, "END:VEVENT")
+}
+
+// ICSEventUID is the UID of a key's event. It does not include the expiry
+// date, so a re-dated key updates its event instead of adding another.
+func ICSEventUID(shortName, keyName string) string {
+	return fmt.Sprintf("%s.%s@waxseal", shortName, keyName)
+}
+
+// escapeICSText escapes a TEXT value (RFC 5545 section 3.3.11).
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// writeICSLine writes a content line, folded at 75 octets without splitting
+// a UTF-8 sequence, with the CRLF line ending RFC 5545 requires.
+func writeICSLine(w *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// unfoldICS splits a calendar into unfolded content lines.
+func unfoldICS(data string) []string {
+	var lines []string
+	for _, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += raw[1:]
+			continue
+		}
+		if raw != "" {
+			lines = append(lines, raw)
+		}
+	}
+	return lines
+}
+
+// Compile-time interface check
+var _ Provider = (*ICSProvider)(nil)
@@ -0,0 +1,200 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+func icsSecrets() []*core.SecretMetadata {
+	return []*core.SecretMetadata{
+		{
+			ShortName:    "payments",
+			ManifestPath: "apps/payments/sealed.yaml",
+			Keys: []core.KeyMetadata{
+				{
+					KeyName: "stripe_key",
+					Expiry:  &core.ExpiryConfig{ExpiresAt: "2026-06-30T00:00:00Z"},
+				},
+				{KeyName: "username"},
+			},
+		},
+		{
+			ShortName:    "app",
+			ManifestPath: "apps/app/sealed.yaml",
+			Keys: []core.KeyMetadata{
+				{
+					KeyName:  "api_key",
+					Rotation: &core.RotationConfig{Mode: "external"},
+					Expiry:   &core.ExpiryConfig{ExpiresAt: "2026-05-15T12:00:00Z"},
+				},
+			},
+		},
+	}
+}
+
+func TestRenderICS(t *testing.T) {
+	now := time.Date(2026, 4, 1, 8, 30, 0, 0, time.UTC)
+	data, n := RenderICS(icsSecrets(), []int{7, 30, 0, 7}, now)
+	if n != 2 {
+		t.Errorf("events = %d, want 2", n)
+	}
+
+	text := string(data)
+	if !strings.HasPrefix(text, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(text, "END:VCALENDAR\r\n") {
+		t.Errorf("not a CRLF VCALENDAR:\n%s", text)
+	}
+	if strings.Contains(strings.ReplaceAll(text, "\r\n", ""), "\n") {
+		t.Error("bare LF line ending")
+	}
+	for _, line := range strings.Split(text, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+
+	lines := unfoldICS(text)
+	want := []string{
+		"UID:app.api_key@waxseal",
+		"UID:payments.stripe_key@waxseal",
+		"DTSTAMP:20260401T083000Z",
+		"DTSTART;VALUE=DATE:20260515",
+		"DTEND;VALUE=DATE:20260516",
+		"TRIGGER:-P30D",
+		"TRIGGER:-P7D",
+		"TRIGGER:PT0S",
+	}
+	for _, w := range want {
+		if !slices.Contains(lines, w) {
+			t.Errorf("missing %q", w)
+		}
+	}
+	// Sorted by secret, three alarms per event (7 listed twice)
+	if slices.Index(lines, "UID:app.api_key@waxseal") > slices.Index(lines, "UID:payments.stripe_key@waxseal") {
+		t.Error("events not sorted by short name")
+	}
+	if got := strings.Count(text, "BEGIN:VALARM"); got != 6 {
+		t.Errorf("alarms = %d, want 6", got)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), `waxseal rotate app api_key\n`) {
+		t.Error("description does not escape newlines")
+	}
+}
+
+func TestRenderICS_StableUID(t *testing.T) {
+	secrets := icsSecrets()
+	before, _ := RenderICS(secrets, []int{7}, time.Now())
+	secrets[1].Keys[0].Expiry.ExpiresAt = "2027-05-15T12:00:00Z"
+	after, _ := RenderICS(secrets, []int{7}, time.Now())
+
+	if strings.Count(string(after), "UID:app.api_key@waxseal") != 1 {
+		t.Error("a re-dated key must keep its UID")
+	}
+	if bytes.Equal(before, after) {
+		t.Error("re-dated key did not change the calendar")
+	}
+}
+
+func TestWriteICSLine_FoldsUTF8(t *testing.T) {
+	var buf bytes.Buffer
+	line := "SUMMARY:" + strings.Repeat("🔐", 30)
+	writeICSLine(&buf, line)
+
+	for _, part := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("folded line longer than 75 octets: %d", len(part))
+		}
+		if !strings.HasPrefix(part, "SUMMARY") && !strings.HasPrefix(part, " ") {
+			t.Errorf("continuation line without leading space: %q", part)
+		}
+	}
+	if got := unfoldICS(buf.String()); len(got) != 1 || got[0] != line {
+		t.Errorf("unfold = %q, want the original line", got)
+	}
+}
+
+func TestICSProvider_SyncAndDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar", "reminders.ics")
+	p := NewICSProvider(path, []int{30, 7})
+
+	secrets := icsSecrets()
+	secrets = append(secrets, &core.SecretMetadata{
+		ShortName: "old",
+		Status:    "retired",
+		Keys:      []core.KeyMetadata{{KeyName: "k", Expiry: &core.ExpiryConfig{ExpiresAt: "2026-07-01T00:00:00Z"}}},
+	})
+	result, err := p.SyncReminders(context.Background(), secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 2 || result.Skipped != 1 {
+		t.Errorf("created %d, skipped %d; want 2 and the retired secret skipped", result.Created, result.Skipped)
+	}
+
+	if err := p.DeleteReminders(context.Background(), "app"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	if strings.Contains(text, "app.api_key") {
+		t.Error("deleted secret's event still present")
+	}
+	if !strings.Contains(text, "UID:payments.stripe_key@waxseal") || !strings.HasSuffix(text, "END:VCALENDAR\r\n") {
+		t.Errorf("other events or the calendar were lost:\n%s", text)
+	}
+}
+
+func TestICSProvider_KeepsFileWhenOnlyDTSTAMPChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminders.ics")
+	p := NewICSProvider(path, []int{7})
+	now := time.Date(2026, 4, 1, 8, 30, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	secrets := icsSecrets()
+	if _, err := p.SyncReminders(context.Background(), secrets); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	// A later sync with the same data leaves the file byte-for-byte
+	now = now.Add(24 * time.Hour)
+	if _, err := p.SyncReminders(context.Background(), secrets); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("calendar rewritten although only DTSTAMP changed")
+	}
+
+	// A real change is written with the new DTSTAMP
+	secrets[0].Keys[0].Expiry.ExpiresAt = "2026-09-30T00:00:00Z"
+	if _, err := p.SyncReminders(context.Background(), secrets); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.ReadFile(path)
+	if !strings.Contains(string(after), "DTSTART;VALUE=DATE:20260930") || !strings.Contains(string(after), "DTSTAMP:20260402T083000Z") {
+		t.Errorf("changed expiry not written:\n%s", after)
+	}
+}
+
+func TestICSProvider_Stdout(t *testing.T) {
+	var out bytes.Buffer
+	p := NewICSProvider(ICSStdout, nil)
+	p.stdout = &out
+
+	if _, err := p.SyncReminders(context.Background(), icsSecrets()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "BEGIN:VEVENT") {
+		t.Error("nothing written to stdout")
+	}
+}
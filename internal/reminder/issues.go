@@ -0,0 +1,255 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/logging"
+)
+
+// IssueLabel is the label on every issue waxseal opens; trackers list
+// issues by it.
+const IssueLabel = "waxseal"
+
+// TrackerIssue is an open issue in an issue tracker.
+type TrackerIssue struct {
+	ID    string // GitHub issue number, GitLab iid or Jira key
+	Title string
+	Body  string
+}
+
+// IssueTracker is the part of an issue tracker's API the issues provider uses.
+type IssueTracker interface {
+	// ListIssues returns the open issues with IssueLabel.
+	ListIssues(ctx context.Context) ([]TrackerIssue, error)
+	// CreateIssue opens an issue with IssueLabel and returns its ID.
+	CreateIssue(ctx context.Context, title, body string) (string, error)
+	UpdateIssue(ctx context.Context, id, title, body string) error
+	// CloseIssue comments on an issue and closes it.
+	CloseIssue(ctx context.Context, id, comment string) error
+}
+
+// issueMarker ties an issue to a key and the expiry it was opened for. It
+// is an HTML comment, invisible in GitHub and GitLab.
+var issueMarker = regexp.MustCompile(`<!-- waxseal-reminder: (\S+)/(\S+) expires=(\S+) -->`)
+
+// IssuesProvider implements Provider with one issue per expiring key. The
+// issue opens when the key crosses its earliest lead time, is retitled as
+// later lead times pass, and closes once the key's expiry moves (it was
+// rotated) or the key or secret is gone.
+type IssuesProvider struct {
+	tracker      IssueTracker
+	leadTimeDays []int
+	now          func() time.Time
+}
+
+// NewIssuesProvider creates an issues provider for tracker.
+func NewIssuesProvider(tracker IssueTracker, leadTimeDays []int) *IssuesProvider {
+	if len(leadTimeDays) == 0 {
+		leadTimeDays = []int{30, 7, 1} // Default: 30 days, 7 days, 1 day before
+	}
+	return &IssuesProvider{
+		tracker:      tracker,
+		leadTimeDays: leadTimeDays,
+		now:          time.Now,
+	}
+}
+
+// issueRef identifies the key and expiry an issue was opened for.
+type issueRef struct {
+	shortName, keyName, expiresAt string
+}
+
+func (r issueRef) key() string { return r.shortName + "/" + r.keyName }
+
+// SyncReminders opens, updates and closes issues to match secrets. Every
+// key with an expiry date should be passed, so issues for keys that were
+// rotated can be closed.
+func (p *IssuesProvider) SyncReminders(ctx context.Context, secrets []*core.SecretMetadata) (*SyncResult, error) {
+	result := &SyncResult{}
+	now := p.now()
+
+	issues, err := p.tracker.ListIssues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list issues: %w", err)
+	}
+	open := make(map[string]TrackerIssue) // by key
+	refs := make(map[string]issueRef)     // by issue ID
+	for _, issue := range issues {
+		ref, ok := parseIssueMarker(issue.Body)
+		if !ok {
+			continue
+		}
+		refs[issue.ID] = ref
+		open[ref.key()] = issue
+	}
+
+	// Keys that still expire, and their current expiry
+	current := make(map[string]string)
+	for _, secret := range secrets {
+		if secret.IsRetired() {
+			result.Skipped++
+			continue
+		}
+		for _, key := range secret.Keys {
+			if key.Expiry == nil || key.Expiry.ExpiresAt == "" {
+				continue
+			}
+			expiresAt, err := time.Parse(time.RFC3339, key.Expiry.ExpiresAt)
+			if err != nil {
+				logging.Warn("invalid expiry date",
+					"secret", secret.ShortName,
+					"key", key.KeyName,
+					"expiresAt", key.Expiry.ExpiresAt,
+				)
+				continue
+			}
+			ref := issueRef{secret.ShortName, key.KeyName, expiresAt.UTC().Format(time.RFC3339)}
+			current[ref.key()] = ref.expiresAt
+
+			// The issue opened for an earlier expiry is closed below
+			issue, exists := open[ref.key()]
+			if exists && refs[issue.ID].expiresAt != ref.expiresAt {
+				exists = false
+			}
+
+			leadDays, due := p.crossedLeadTime(expiresAt, now)
+			if !due {
+				continue
+			}
+			title, body := issueContent(secret, key, expiresAt, leadDays, now, ref)
+
+			switch {
+			case !exists:
+				if _, err := p.tracker.CreateIssue(ctx, title, body); err != nil {
+					result.Errors = append(result.Errors, fmt.Errorf("open issue for %s: %w", ref.key(), err))
+					continue
+				}
+				result.Created++
+			case issue.Title != title || issue.Body != body:
+				if err := p.tracker.UpdateIssue(ctx, issue.ID, title, body); err != nil {
+					result.Errors = append(result.Errors, fmt.Errorf("update issue %s: %w", issue.ID, err))
+					continue
+				}
+				result.Updated++
+			default:
+				result.Skipped++
+			}
+		}
+	}
+
+	// Close issues whose key was rotated or no longer expires
+	for _, issue := range issues {
+		ref, ok := refs[issue.ID]
+		if !ok {
+			continue
+		}
+		expiresAt, tracked := current[ref.key()]
+		var comment string
+		switch {
+		case !tracked:
+			comment = fmt.Sprintf("%s no longer has an expiry date in waxseal metadata (removed or retired). Closing.", ref.key())
+		case expiresAt != ref.expiresAt:
+			comment = fmt.Sprintf("%s was rotated: it now expires %s. Closing.", ref.key(), expiresAt)
+		default:
+			continue
+		}
+		if err := p.tracker.CloseIssue(ctx, issue.ID, comment); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("close issue %s: %w", issue.ID, err))
+			continue
+		}
+		result.Closed++
+	}
+
+	return result, nil
+}
+
+// DeleteReminders closes the open issues for a secret.
+func (p *IssuesProvider) DeleteReminders(ctx context.Context, shortName string) error {
+	issues, err := p.tracker.ListIssues(ctx)
+	if err != nil {
+		return fmt.Errorf("list issues: %w", err)
+	}
+	for _, issue := range issues {
+		ref, ok := parseIssueMarker(issue.Body)
+		if !ok || ref.shortName != shortName {
+			continue
+		}
+		comment := fmt.Sprintf("Reminders for %s were cleared. Closing.", shortName)
+		if err := p.tracker.CloseIssue(ctx, issue.ID, comment); err != nil {
+			return fmt.Errorf("close issue %s: %w", issue.ID, err)
+		}
+	}
+	return nil
+}
+
+// crossedLeadTime returns the smallest lead time whose reminder date has
+// passed, and whether any has.
+func (p *IssuesProvider) crossedLeadTime(expiresAt, now time.Time) (int, bool) {
+	leads := slices.Clone(p.leadTimeDays)
+	slices.Sort(leads)
+	for _, days := range leads {
+		if !now.Before(expiresAt.AddDate(0, 0, -days)) {
+			return days, true
+		}
+	}
+	return 0, false
+}
+
+func issueContent(secret *core.SecretMetadata, key core.KeyMetadata, expiresAt time.Time, leadDays int, now time.Time, ref issueRef) (title, body string) {
+	date := expiresAt.UTC().Format("2006-01-02")
+	switch {
+	case !now.Before(expiresAt):
+		title = fmt.Sprintf("🚨 Rotate %s/%s: EXPIRED on %s", secret.ShortName, key.KeyName, date)
+	case leadDays == 0:
+		title = fmt.Sprintf("🚨 Rotate %s/%s: expires TODAY", secret.ShortName, key.KeyName)
+	case leadDays == 1:
+		title = fmt.Sprintf("⚠️ Rotate %s/%s: expires TOMORROW", secret.ShortName, key.KeyName)
+	default:
+		title = fmt.Sprintf("🔐 Rotate %s/%s: expires within %d days (%s)", secret.ShortName, key.KeyName, leadDays, date)
+	}
+
+	body = fmt.Sprintf(`Secret Expiration Reminder
+
+Secret: %s
+Key: %s
+Expires: %s
+Rotation Mode: %s
+Manifest: %s
+
+To rotate:
+
+    waxseal rotate %s %s
+
+This issue closes on the next 'waxseal reminders sync' after the key's
+expiry moves.
+
+<!-- waxseal-reminder: %s/%s expires=%s -->`,
+		secret.ShortName,
+		key.KeyName,
+		expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+		getRotationMode(key),
+		secret.ManifestPath,
+		secret.ShortName,
+		key.KeyName,
+		ref.shortName,
+		ref.keyName,
+		ref.expiresAt,
+	)
+	return title, body
+}
+
+func parseIssueMarker(body string) (issueRef, bool) {
+	m := issueMarker.FindStringSubmatch(body)
+	if m == nil {
+		return issueRef{}, false
+	}
+	return issueRef{shortName: m[1], keyName: m[2], expiresAt: m[3]}, true
+}
+
+// Compile-time interface check
+var _ Provider = (*IssuesProvider)(nil)
@@ -0,0 +1,337 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// fakeGitHub is an in-memory GitHub issues REST API.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	issues   map[int]map[string]any
+	comments map[int][]string
+	next     int
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *httptest.Server) {
+	t.Helper()
+	f := &fakeGitHub{issues: map[int]map[string]any{}, comments: map[int][]string{}, next: 1}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/infra/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var out []map[string]any
+		// A pull request carrying the label is not an issue
+		out = append(out, map[string]any{"number": 999, "title": "PR", "body": "", "pull_request": map[string]any{}})
+		for n := 1; n < f.next; n++ {
+			if issue, ok := f.issues[n]; ok && issue["state"] == "open" && r.URL.Query().Get("labels") == IssueLabel {
+				out = append(out, issue)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	mux.HandleFunc("POST /repos/acme/infra/issues", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		in["number"], in["state"] = f.next, "open"
+		f.issues[f.next] = in
+		f.next++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(in)
+	})
+	mux.HandleFunc("PATCH /repos/acme/infra/issues/{n}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.PathValue("n"))
+		var in map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for k, v := range in {
+			f.issues[n][k] = v
+		}
+		_ = json.NewEncoder(w).Encode(f.issues[n])
+	})
+	mux.HandleFunc("POST /repos/acme/infra/issues/{n}/comments", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.PathValue("n"))
+		var in struct{ Body string }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.comments[n] = append(f.comments[n], in.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("{}"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeGitHub) issue(n int) map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.issues[n]
+}
+
+func TestIssuesProvider_Lifecycle(t *testing.T) {
+	gh, srv := newFakeGitHub(t)
+	p := NewIssuesProvider(NewGitHubTracker(srv.URL, "acme/infra", "gh-token", []string{"security"}), []int{30, 7, 1})
+	ctx := context.Background()
+
+	expiresAt := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	secrets := []*core.SecretMetadata{expiringSecret(expiresAt)}
+	run := func(now time.Time) *SyncResult {
+		t.Helper()
+		p.now = func() time.Time { return now }
+		result, err := p.SyncReminders(ctx, secrets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Errors) > 0 {
+			t.Fatalf("errors: %v", result.Errors)
+		}
+		return result
+	}
+
+	// 60 days out: no threshold crossed yet
+	if r := run(expiresAt.AddDate(0, 0, -60)); r.Created != 0 {
+		t.Fatalf("created %d issues before the first lead time", r.Created)
+	}
+
+	// 20 days out: one issue
+	if r := run(expiresAt.AddDate(0, 0, -20)); r.Created != 1 {
+		t.Fatalf("created = %d, want 1", r.Created)
+	}
+	issue := gh.issue(1)
+	if title := issue["title"].(string); !strings.Contains(title, "app/api_key") || !strings.Contains(title, "30 days") {
+		t.Errorf("title = %q", title)
+	}
+	if labels := issue["labels"].([]any); len(labels) != 2 || labels[0] != IssueLabel || labels[1] != "security" {
+		t.Errorf("labels = %v", labels)
+	}
+
+	// Same threshold: nothing to change
+	if r := run(expiresAt.AddDate(0, 0, -19)); r.Skipped != 1 || r.Updated != 0 {
+		t.Errorf("second sync = %+v, want skipped", r)
+	}
+
+	// 5 days out: the same issue is retitled
+	if r := run(expiresAt.AddDate(0, 0, -5)); r.Updated != 1 || r.Created != 0 {
+		t.Fatalf("sync at 5 days = %+v, want one update", r)
+	}
+	if title := gh.issue(1)["title"].(string); !strings.Contains(title, "7 days") {
+		t.Errorf("title = %q", title)
+	}
+
+	// Rotated: the expiry moves out of range, and the issue is closed
+	secrets[0].Keys[0].Expiry.ExpiresAt = expiresAt.AddDate(1, 0, 0).Format(time.RFC3339)
+	if r := run(expiresAt.AddDate(0, 0, -4)); r.Closed != 1 || r.Created != 0 {
+		t.Fatalf("sync after rotate = %+v, want one closed", r)
+	}
+	if state := gh.issue(1)["state"]; state != "closed" {
+		t.Errorf("state = %v, want closed", state)
+	}
+	if len(gh.comments[1]) != 1 || !strings.Contains(gh.comments[1][0], "was rotated") {
+		t.Errorf("comments = %v", gh.comments[1])
+	}
+}
+
+func TestIssuesProvider_RotatedIntoLeadTime(t *testing.T) {
+	gh, srv := newFakeGitHub(t)
+	p := NewIssuesProvider(NewGitHubTracker(srv.URL, "acme/infra", "gh-token", nil), []int{30})
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	secrets := []*core.SecretMetadata{expiringSecret(now.AddDate(0, 0, 3))}
+	if _, err := p.SyncReminders(context.Background(), secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	// A short-lived key rotated to a new expiry that is already due: the
+	// old issue closes and a new one opens for the new deadline
+	secrets[0].Keys[0].Expiry.ExpiresAt = now.AddDate(0, 0, 20).Format(time.RFC3339)
+	r, err := p.SyncReminders(context.Background(), secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Closed != 1 || r.Created != 1 {
+		t.Fatalf("result = %+v, want one closed and one opened", r)
+	}
+	if gh.issue(1)["state"] != "closed" || gh.issue(2)["state"] != "open" {
+		t.Errorf("issue states = %v, %v", gh.issue(1)["state"], gh.issue(2)["state"])
+	}
+}
+
+func TestIssuesProvider_ClosesUntrackedAndCleared(t *testing.T) {
+	gh, srv := newFakeGitHub(t)
+	p := NewIssuesProvider(NewGitHubTracker(srv.URL, "acme/infra", "gh-token", nil), []int{30})
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	app := expiringSecret(now.AddDate(0, 0, 3))
+	other := expiringSecret(now.AddDate(0, 0, 3))
+	other.ShortName = "other"
+	if _, err := p.SyncReminders(ctx, []*core.SecretMetadata{app, other}); err != nil {
+		t.Fatal(err)
+	}
+
+	// other is gone from metadata
+	r, err := p.SyncReminders(ctx, []*core.SecretMetadata{app})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Closed != 1 || gh.issue(2)["state"] != "closed" || gh.issue(1)["state"] != "open" {
+		t.Fatalf("result = %+v, states %v %v", r, gh.issue(1)["state"], gh.issue(2)["state"])
+	}
+
+	if err := p.DeleteReminders(ctx, "app"); err != nil {
+		t.Fatal(err)
+	}
+	if gh.issue(1)["state"] != "closed" {
+		t.Error("DeleteReminders did not close the issue")
+	}
+}
+
+func TestGitLabTracker(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "gl-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath()+" "+r.URL.Query().Get("labels")+" "+fmtBody(body))
+		switch {
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`[{"iid": 4, "title": "t", "description": "d"}]`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues"):
+			_, _ = w.Write([]byte(`{"iid": 5}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	tr := NewGitLabTracker(srv.URL, "platform/infra", "gl-token", []string{"security"})
+	ctx := context.Background()
+	issues, err := tr.ListIssues(ctx)
+	if err != nil || len(issues) != 1 || issues[0].ID != "4" || issues[0].Body != "d" {
+		t.Fatalf("ListIssues = %v, %v", issues, err)
+	}
+	id, err := tr.CreateIssue(ctx, "title", "body")
+	if err != nil || id != "5" {
+		t.Fatalf("CreateIssue = %q, %v", id, err)
+	}
+	if err := tr.CloseIssue(ctx, "5", "done"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /projects/platform%2Finfra/issues waxseal ",
+		"POST /projects/platform%2Finfra/issues  description=body labels=waxseal,security title=title",
+		"POST /projects/platform%2Finfra/issues/5/notes  body=done",
+		"PUT /projects/platform%2Finfra/issues/5  state_event=close",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestJiraTracker(t *testing.T) {
+	var transitioned string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "ops@example.com" || pass != "jira-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/rest/api/2/search":
+			if jql := r.URL.Query().Get("jql"); !strings.Contains(jql, `project = "OPS"`) || !strings.Contains(jql, `labels = "waxseal"`) {
+				t.Errorf("jql = %q", jql)
+			}
+			_, _ = w.Write([]byte(`{"total": 1, "issues": [{"key": "OPS-7", "fields": {"summary": "s", "description": "d"}}]}`))
+		case r.URL.Path == "/rest/api/2/issue" && r.Method == http.MethodPost:
+			var in struct {
+				Fields struct {
+					IssueType struct{ Name string } `json:"issuetype"`
+					Labels    []string
+				}
+			}
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			if in.Fields.IssueType.Name != "Task" || len(in.Fields.Labels) != 1 {
+				t.Errorf("create fields = %+v", in.Fields)
+			}
+			_, _ = w.Write([]byte(`{"key": "OPS-8"}`))
+		case r.URL.Path == "/rest/api/2/issue/OPS-8/transitions" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"transitions": [
+				{"id": "11", "to": {"statusCategory": {"key": "indeterminate"}}},
+				{"id": "31", "to": {"statusCategory": {"key": "done"}}}]}`))
+		case r.URL.Path == "/rest/api/2/issue/OPS-8/transitions":
+			var in struct{ Transition struct{ ID string } }
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			transitioned = in.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	tr := NewJiraTracker(srv.URL, "OPS", "ops@example.com", "jira-token", "", nil)
+	ctx := context.Background()
+	issues, err := tr.ListIssues(ctx)
+	if err != nil || len(issues) != 1 || issues[0].ID != "OPS-7" {
+		t.Fatalf("ListIssues = %v, %v", issues, err)
+	}
+	id, err := tr.CreateIssue(ctx, "title", "body")
+	if err != nil || id != "OPS-8" {
+		t.Fatalf("CreateIssue = %q, %v", id, err)
+	}
+	if err := tr.CloseIssue(ctx, id, "done"); err != nil {
+		t.Fatal(err)
+	}
+	if transitioned != "31" {
+		t.Errorf("transition = %q, want the done transition 31", transitioned)
+	}
+}
+
+func TestParseIssueMarker(t *testing.T) {
+	secret := expiringSecret(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	ref := issueRef{"app", "api_key", "2026-04-01T00:00:00Z"}
+	_, body := issueContent(secret, secret.Keys[0], time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), 7, time.Date(2026, 3, 28, 0, 0, 0, 0, time.UTC), ref)
+	got, ok := parseIssueMarker(body)
+	if !ok || got != ref {
+		t.Errorf("parseIssueMarker = %+v, %v; want %+v", got, ok, ref)
+	}
+	if _, ok := parseIssueMarker("an unrelated issue"); ok {
+		t.Error("matched an issue without a marker")
+	}
+}
+
+// fmtBody renders a JSON object's fields as sorted key=value pairs.
+func fmtBody(body map[string]any) string {
+	var parts []string
+	for k, v := range body {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, " ")
+}
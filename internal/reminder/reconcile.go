@@ -0,0 +1,132 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/logging"
+)
+
+// Reconciler is a Provider whose reminders can be listed and deleted one by
+// one, so sync can remove the reminders metadata no longer backs: keys
+// whose expiry moved, deleted keys and retired secrets.
+type Reconciler interface {
+	Provider
+	// ListReminders returns the reminders waxseal created.
+	ListReminders(ctx context.Context) ([]Reminder, error)
+	// ExpectedReminders returns the IDs of the reminders metadata backs
+	// for secrets, including those whose date has passed.
+	ExpectedReminders(secrets []*core.SecretMetadata) map[string]bool
+	// DeleteReminder removes one reminder.
+	DeleteReminder(ctx context.Context, r Reminder) error
+}
+
+// Reminder is a reminder owned by a provider.
+type Reminder struct {
+	ID    string // matched against ExpectedReminders
+	Title string
+	Due   string // date, for display
+	ref   string // provider's own identifier, if not ID
+}
+
+// Orphans returns the provider's reminders that sync no longer maintains
+// for secrets, sorted by title.
+func Orphans(ctx context.Context, r Reconciler, secrets []*core.SecretMetadata) ([]Reminder, error) {
+	owned, err := r.ListReminders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := r.ExpectedReminders(secrets)
+
+	var orphans []Reminder
+	for _, rem := range owned {
+		if !expected[rem.ID] {
+			orphans = append(orphans, rem)
+		}
+	}
+	slices.SortFunc(orphans, func(a, b Reminder) int {
+		return strings.Compare(a.Title, b.Title)
+	})
+	return orphans, nil
+}
+
+// Prune deletes reminders, returning an error for each one that failed.
+func Prune(ctx context.Context, r Reconciler, reminders []Reminder) []error {
+	var errs []error
+	for _, rem := range reminders {
+		if err := r.DeleteReminder(ctx, rem); err != nil {
+			errs = append(errs, fmt.Errorf("delete %q: %w", rem.Title, err))
+		}
+	}
+	return errs
+}
+
+// plannedReminder is one reminder sync maintains: a key's expiry at one
+// lead time.
+type plannedReminder struct {
+	secret       *core.SecretMetadata
+	key          core.KeyMetadata
+	expiresAt    time.Time
+	leadDays     int
+	reminderTime time.Time
+}
+
+// planReminders lists the reminders sync creates for secrets: the backed
+// reminders whose date is still ahead. retired counts the retired secrets,
+// which get none.
+func planReminders(secrets []*core.SecretMetadata, leadTimeDays []int, now time.Time) (planned []plannedReminder, retired int) {
+	backed, retired := backedReminders(secrets, leadTimeDays)
+	for _, r := range backed {
+		// Skip if reminder time is in the past
+		if r.reminderTime.Before(now) {
+			continue
+		}
+		planned = append(planned, r)
+	}
+	return planned, retired
+}
+
+// backedReminders lists every reminder current metadata backs: one per key
+// with an expiry date and lead time, whether or not its date has passed.
+// A reminder whose date passed is still live until the key is rotated, so
+// pruning must keep it.
+func backedReminders(secrets []*core.SecretMetadata, leadTimeDays []int) (backed []plannedReminder, retired int) {
+	for _, secret := range secrets {
+		if secret.IsRetired() {
+			retired++
+			continue
+		}
+
+		// Check each key for expiry
+		for _, key := range secret.Keys {
+			if key.Expiry == nil || key.Expiry.ExpiresAt == "" {
+				continue
+			}
+
+			expiresAt, err := time.Parse(time.RFC3339, key.Expiry.ExpiresAt)
+			if err != nil {
+				logging.Warn("invalid expiry date",
+					"secret", secret.ShortName,
+					"key", key.KeyName,
+					"expiresAt", key.Expiry.ExpiresAt,
+				)
+				continue
+			}
+
+			for _, days := range leadTimeDays {
+				backed = append(backed, plannedReminder{
+					secret:       secret,
+					key:          key,
+					expiresAt:    expiresAt,
+					leadDays:     days,
+					reminderTime: expiresAt.AddDate(0, 0, -days),
+				})
+			}
+		}
+	}
+	return backed, retired
+}
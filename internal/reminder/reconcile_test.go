@@ -0,0 +1,183 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"google.golang.org/api/option"
+	"google.golang.org/api/tasks/v1"
+)
+
+func TestOrphansAndPrune(t *testing.T) {
+	fake := NewFakeProvider()
+	fake.Reminders = []Reminder{
+		{ID: "b", Title: "b"},
+		{ID: "keep", Title: "keep"},
+		{ID: "a", Title: "a"},
+	}
+	fake.Expected = map[string]bool{"keep": true, "not-created-yet": true}
+
+	orphans, err := Orphans(context.Background(), fake, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 2 || orphans[0].ID != "a" || orphans[1].ID != "b" {
+		t.Fatalf("orphans = %+v, want a and b sorted", orphans)
+	}
+
+	if errs := Prune(context.Background(), fake, orphans); len(errs) != 0 {
+		t.Fatalf("Prune: %v", errs)
+	}
+	if len(fake.Reminders) != 1 || fake.Reminders[0].ID != "keep" {
+		t.Errorf("remaining = %+v, want only keep", fake.Reminders)
+	}
+}
+
+func TestPlanReminders(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	secrets := []*core.SecretMetadata{
+		{
+			ShortName: "app",
+			Keys: []core.KeyMetadata{
+				{KeyName: "soon", Expiry: &core.ExpiryConfig{ExpiresAt: "2026-03-10T00:00:00Z"}},
+				{KeyName: "bad", Expiry: &core.ExpiryConfig{ExpiresAt: "next week"}},
+				{KeyName: "none"},
+			},
+		},
+		{
+			ShortName: "old",
+			Status:    "retired",
+			Keys:      []core.KeyMetadata{{KeyName: "k", Expiry: &core.ExpiryConfig{ExpiresAt: "2026-12-01T00:00:00Z"}}},
+		},
+	}
+
+	planned, retired := planReminders(secrets, []int{30, 7, 1}, now)
+	if retired != 1 {
+		t.Errorf("retired = %d, want 1", retired)
+	}
+	// 30 days before is already past; 7 and 1 remain
+	if len(planned) != 2 || planned[0].leadDays != 7 || planned[1].leadDays != 1 {
+		t.Errorf("planned = %+v, want lead times 7 and 1", planned)
+	}
+
+	// Pruning keeps every lead time, passed or not
+	if backed, _ := backedReminders(secrets, []int{30, 7, 1}); len(backed) != 3 {
+		t.Errorf("backed = %d reminders, want 3", len(backed))
+	}
+}
+
+// fakeTasksServer serves the parts of the Google Tasks API the provider
+// uses for reconciliation.
+type fakeTasksServer struct {
+	mu      sync.Mutex
+	tasks   []*tasks.Task
+	deleted []string
+}
+
+func (f *fakeTasksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const prefix = "/tasks/v1/lists/@default/tasks"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == prefix:
+		var items []*tasks.Task
+		for _, task := range f.tasks {
+			if r.URL.Query().Get("showCompleted") == "false" && task.Status == "completed" {
+				continue
+			}
+			items = append(items, task)
+		}
+		_ = json.NewEncoder(w).Encode(&tasks.Tasks{Items: items})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, prefix+"/"):
+		f.deleted = append(f.deleted, strings.TrimPrefix(r.URL.Path, prefix+"/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unexpected "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+	}
+}
+
+func TestGoogleTasksProvider_Reconcile(t *testing.T) {
+	secret := &core.SecretMetadata{
+		ShortName:    "app",
+		ManifestPath: "apps/app/sealed.yaml",
+		Keys: []core.KeyMetadata{
+			{KeyName: "api_key", Expiry: &core.ExpiryConfig{ExpiresAt: time.Now().AddDate(0, 0, 60).Format(time.RFC3339)}},
+		},
+	}
+
+	fake := &fakeTasksServer{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	p, err := NewGoogleTasksProvider(context.Background(), "", []int{30},
+		option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := p.createTask(secret, secret.Keys[0], time.Now(), 30, time.Now())
+	fake.tasks = []*tasks.Task{
+		{Id: "t1", Title: current.Title, Notes: current.Notes, Due: "2026-05-01T00:00:00Z"},
+		{Id: "t2", Title: "🔐 removed/key - Rotate in 30 days", Notes: current.Notes, Due: "2026-05-02T00:00:00Z"},
+		{Id: "t3", Title: "🔐 done/key - Rotate in 30 days", Notes: current.Notes, Status: "completed"},
+		{Id: "t4", Title: "Buy milk"},
+	}
+
+	orphans, err := Orphans(context.Background(), p, []*core.SecretMetadata{secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || orphans[0].ref != "t2" || orphans[0].Due != "2026-05-02" {
+		t.Fatalf("orphans = %+v, want only the removed key's open task", orphans)
+	}
+
+	if errs := Prune(context.Background(), p, orphans); len(errs) != 0 {
+		t.Fatalf("Prune: %v", errs)
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != "t2" {
+		t.Errorf("deleted = %v, want t2", fake.deleted)
+	}
+}
+
+func TestGoogleTasksProvider_KeepsPassedLeadTime(t *testing.T) {
+	// 20 days out: the 30-day reminder's date has passed, but the key was
+	// not rotated, so its open task is the one that matters most
+	expiresAt := time.Now().AddDate(0, 0, 20)
+	secret := &core.SecretMetadata{
+		ShortName:    "app",
+		ManifestPath: "apps/app/sealed.yaml",
+		Keys: []core.KeyMetadata{
+			{KeyName: "api_key", Expiry: &core.ExpiryConfig{ExpiresAt: expiresAt.Format(time.RFC3339)}},
+		},
+	}
+
+	fake := &fakeTasksServer{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	p, err := NewGoogleTasksProvider(context.Background(), "", []int{30, 7},
+		option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, _ := time.Parse(time.RFC3339, secret.Keys[0].Expiry.ExpiresAt)
+	passed := p.createTask(secret, secret.Keys[0], parsed, 30, parsed.AddDate(0, 0, -30))
+	fake.tasks = []*tasks.Task{{Id: "t1", Title: passed.Title, Notes: passed.Notes, Due: passed.Due}}
+
+	orphans, err := Orphans(context.Background(), p, []*core.SecretMetadata{secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("orphans = %+v, want the passed 30-day task kept", orphans)
+	}
+}
@@ -4,6 +4,7 @@ package reminder
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/shermanhuman/waxseal/internal/core"
@@ -16,6 +17,9 @@ import (
 // Tasks created here automatically appear in Google Calendar.
 const DefaultTaskList = "@default"
 
+// taskNotesHeader starts the notes of every task waxseal creates.
+const taskNotesHeader = "Secret Expiration Reminder\n"
+
 // GoogleTasksProvider implements Provider using Google Tasks API.
 // Tasks with due dates automatically appear in Google Calendar.
 type GoogleTasksProvider struct {
@@ -26,8 +30,10 @@ type GoogleTasksProvider struct {
 
 // NewGoogleTasksProvider creates a provider using Application Default Credentials.
 // If tasklistID is empty, uses "@default" (user's primary task list).
-func NewGoogleTasksProvider(ctx context.Context, tasklistID string, leadTimeDays []int) (*GoogleTasksProvider, error) {
-	service, err := tasks.NewService(ctx, option.WithScopes(tasks.TasksScope))
+// Extra options override the client, e.g. its endpoint in tests.
+func NewGoogleTasksProvider(ctx context.Context, tasklistID string, leadTimeDays []int, opts ...option.ClientOption) (*GoogleTasksProvider, error) {
+	opts = append([]option.ClientOption{option.WithScopes(tasks.TasksScope)}, opts...)
+	service, err := tasks.NewService(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("create tasks service: %w", err)
 	}
@@ -52,71 +58,81 @@ func NewGoogleTasksProvider(ctx context.Context, tasklistID string, leadTimeDays
 func (p *GoogleTasksProvider) SyncReminders(ctx context.Context, secrets []*core.SecretMetadata) (*SyncResult, error) {
 	result := &SyncResult{}
 
-	for _, secret := range secrets {
-		if secret.IsRetired() {
-			result.Skipped++
-			continue
+	// Create reminder tasks for each lead time
+	planned, retired := planReminders(secrets, p.leadTimeDays, time.Now())
+	result.Skipped = retired
+	for _, r := range planned {
+		task := p.createTask(r.secret, r.key, r.expiresAt, r.leadDays, r.reminderTime)
+
+		// Check if task already exists by listing and matching title
+		existing, err := p.findExistingTask(ctx, task.Title)
+		if err != nil {
+			logging.Warn("failed to check for existing task",
+				"title", task.Title,
+				"error", err.Error(),
+			)
 		}
 
-		// Check each key for expiry
-		for _, key := range secret.Keys {
-			if key.Expiry == nil || key.Expiry.ExpiresAt == "" {
-				continue
+		if existing != nil {
+			// Update existing task
+			task.Id = existing.Id
+			_, err = p.service.Tasks.Update(p.tasklistID, existing.Id, task).Context(ctx).Do()
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("update task %s: %w", task.Title, err))
+			} else {
+				result.Updated++
 			}
-
-			expiresAt, err := time.Parse(time.RFC3339, key.Expiry.ExpiresAt)
+		} else {
+			// Create new task
+			_, err = p.service.Tasks.Insert(p.tasklistID, task).Context(ctx).Do()
 			if err != nil {
-				logging.Warn("invalid expiry date",
-					"secret", secret.ShortName,
-					"key", key.KeyName,
-					"expiresAt", key.Expiry.ExpiresAt,
-				)
-				continue
+				result.Errors = append(result.Errors, fmt.Errorf("create task %s: %w", task.Title, err))
+			} else {
+				result.Created++
 			}
+		}
+	}
+
+	return result, nil
+}
 
-			// Create reminder tasks for each lead time
-			for _, days := range p.leadTimeDays {
-				reminderTime := expiresAt.AddDate(0, 0, -days)
-
-				// Skip if reminder time is in the past
-				if reminderTime.Before(time.Now()) {
-					continue
-				}
-
-				task := p.createTask(secret, key, expiresAt, days, reminderTime)
-
-				// Check if task already exists by listing and matching title
-				existing, err := p.findExistingTask(ctx, task.Title)
-				if err != nil {
-					logging.Warn("failed to check for existing task",
-						"title", task.Title,
-						"error", err.Error(),
-					)
-				}
-
-				if existing != nil {
-					// Update existing task
-					task.Id = existing.Id
-					_, err = p.service.Tasks.Update(p.tasklistID, existing.Id, task).Context(ctx).Do()
-					if err != nil {
-						result.Errors = append(result.Errors, fmt.Errorf("update task %s: %w", task.Title, err))
-					} else {
-						result.Updated++
-					}
-				} else {
-					// Create new task
-					_, err = p.service.Tasks.Insert(p.tasklistID, task).Context(ctx).Do()
-					if err != nil {
-						result.Errors = append(result.Errors, fmt.Errorf("create task %s: %w", task.Title, err))
-					} else {
-						result.Created++
-					}
-				}
+// ListReminders returns the open tasks waxseal created, recognised by their
+// notes. Completed tasks are left alone.
+func (p *GoogleTasksProvider) ListReminders(ctx context.Context) ([]Reminder, error) {
+	var reminders []Reminder
+	err := p.service.Tasks.List(p.tasklistID).ShowCompleted(false).Pages(ctx, func(list *tasks.Tasks) error {
+		for _, task := range list.Items {
+			if !strings.HasPrefix(task.Notes, taskNotesHeader) {
+				continue
+			}
+			r := Reminder{ID: task.Title, Title: task.Title, ref: task.Id}
+			if len(task.Due) >= 10 {
+				r.Due = task.Due[:10]
 			}
+			reminders = append(reminders, r)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	return reminders, nil
+}
+
+// ExpectedReminders returns the titles of the tasks metadata backs, past
+// lead times included; tasks are matched by title.
+func (p *GoogleTasksProvider) ExpectedReminders(secrets []*core.SecretMetadata) map[string]bool {
+	expected := make(map[string]bool)
+	backed, _ := backedReminders(secrets, p.leadTimeDays)
+	for _, r := range backed {
+		expected[p.createTask(r.secret, r.key, r.expiresAt, r.leadDays, r.reminderTime).Title] = true
 	}
+	return expected
+}
 
-	return result, nil
+// DeleteReminder deletes one task.
+func (p *GoogleTasksProvider) DeleteReminder(ctx context.Context, r Reminder) error {
+	return p.service.Tasks.Delete(p.tasklistID, r.ref).Context(ctx).Do()
 }
 
 // DeleteReminders removes all tasks for a secret.
@@ -151,8 +167,7 @@ func (p *GoogleTasksProvider) createTask(secret *core.SecretMetadata, key core.K
 		title = fmt.Sprintf("⚠️ %s/%s - Expires TOMORROW", secret.ShortName, key.KeyName)
 	}
 
-	notes := fmt.Sprintf(`Secret Expiration Reminder
-
+	notes := fmt.Sprintf(taskNotesHeader+`
 Secret: %s
 Key: %s
 Expires: %s
@@ -198,4 +213,4 @@ func (p *GoogleTasksProvider) findExistingTask(ctx context.Context, title string
 }
 
 // Compile-time interface check
-var _ Provider = (*GoogleTasksProvider)(nil)
+var _ Reconciler = (*GoogleTasksProvider)(nil)
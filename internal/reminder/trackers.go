@@ -0,0 +1,349 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default API base URLs.
+const (
+	GitHubAPIURL = "https://api.github.com"
+	GitLabAPIURL = "https://gitlab.com/api/v4"
+)
+
+// issuesPageSize is the page size used to list issues.
+const issuesPageSize = 100
+
+// restClient sends JSON requests to an issue tracker's REST API.
+type restClient struct {
+	baseURL string
+	auth    func(*http.Request)
+	client  *http.Client
+}
+
+func newRESTClient(baseURL string, auth func(*http.Request)) *restClient {
+	return &restClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		auth:    auth,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends in as the JSON body, if not nil, and decodes the response into
+// out, if not nil.
+func (c *restClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.auth(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+// GitHubTracker manages issues in a GitHub repository.
+type GitHubTracker struct {
+	rest   *restClient
+	repo   string // owner/name
+	labels []string
+}
+
+// NewGitHubTracker creates a tracker for repo ("owner/name"). An empty
+// baseURL uses GitHubAPIURL; GitHub Enterprise uses https://host/api/v3.
+// labels are added to IssueLabel on new issues.
+func NewGitHubTracker(baseURL, repo, token string, labels []string) *GitHubTracker {
+	if baseURL == "" {
+		baseURL = GitHubAPIURL
+	}
+	return &GitHubTracker{
+		rest: newRESTClient(baseURL, func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		}),
+		repo:   repo,
+		labels: labels,
+	}
+}
+
+func (t *GitHubTracker) ListIssues(ctx context.Context) ([]TrackerIssue, error) {
+	var issues []TrackerIssue
+	for page := 1; ; page++ {
+		var items []struct {
+			Number      int       `json:"number"`
+			Title       string    `json:"title"`
+			Body        string    `json:"body"`
+			PullRequest *struct{} `json:"pull_request"`
+		}
+		path := fmt.Sprintf("/repos/%s/issues?state=open&labels=%s&per_page=%d&page=%d",
+			t.repo, url.QueryEscape(IssueLabel), issuesPageSize, page)
+		if err := t.rest.do(ctx, http.MethodGet, path, nil, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.PullRequest != nil {
+				continue // the issues API lists pull requests too
+			}
+			issues = append(issues, TrackerIssue{ID: strconv.Itoa(item.Number), Title: item.Title, Body: item.Body})
+		}
+		if len(items) < issuesPageSize {
+			return issues, nil
+		}
+	}
+}
+
+func (t *GitHubTracker) CreateIssue(ctx context.Context, title, body string) (string, error) {
+	var created struct {
+		Number int `json:"number"`
+	}
+	in := map[string]any{
+		"title":  title,
+		"body":   body,
+		"labels": append([]string{IssueLabel}, t.labels...),
+	}
+	if err := t.rest.do(ctx, http.MethodPost, "/repos/"+t.repo+"/issues", in, &created); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(created.Number), nil
+}
+
+func (t *GitHubTracker) UpdateIssue(ctx context.Context, id, title, body string) error {
+	in := map[string]any{"title": title, "body": body}
+	return t.rest.do(ctx, http.MethodPatch, "/repos/"+t.repo+"/issues/"+id, in, nil)
+}
+
+func (t *GitHubTracker) CloseIssue(ctx context.Context, id, comment string) error {
+	if err := t.rest.do(ctx, http.MethodPost, "/repos/"+t.repo+"/issues/"+id+"/comments", map[string]any{"body": comment}, nil); err != nil {
+		return err
+	}
+	in := map[string]any{"state": "closed", "state_reason": "completed"}
+	return t.rest.do(ctx, http.MethodPatch, "/repos/"+t.repo+"/issues/"+id, in, nil)
+}
+
+// GitLabTracker manages issues in a GitLab project.
+type GitLabTracker struct {
+	rest    *restClient
+	project string // URL-escaped ID or path
+	labels  []string
+}
+
+// NewGitLabTracker creates a tracker for project, a numeric ID or a
+// "group/name" path. An empty baseURL uses GitLabAPIURL; self-managed
+// instances use https://host/api/v4.
+func NewGitLabTracker(baseURL, project, token string, labels []string) *GitLabTracker {
+	if baseURL == "" {
+		baseURL = GitLabAPIURL
+	}
+	return &GitLabTracker{
+		rest: newRESTClient(baseURL, func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}),
+		project: url.PathEscape(project),
+		labels:  labels,
+	}
+}
+
+func (t *GitLabTracker) ListIssues(ctx context.Context) ([]TrackerIssue, error) {
+	var issues []TrackerIssue
+	for page := 1; ; page++ {
+		var items []struct {
+			IID         int    `json:"iid"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		path := fmt.Sprintf("/projects/%s/issues?state=opened&labels=%s&per_page=%d&page=%d",
+			t.project, url.QueryEscape(IssueLabel), issuesPageSize, page)
+		if err := t.rest.do(ctx, http.MethodGet, path, nil, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			issues = append(issues, TrackerIssue{ID: strconv.Itoa(item.IID), Title: item.Title, Body: item.Description})
+		}
+		if len(items) < issuesPageSize {
+			return issues, nil
+		}
+	}
+}
+
+func (t *GitLabTracker) CreateIssue(ctx context.Context, title, body string) (string, error) {
+	var created struct {
+		IID int `json:"iid"`
+	}
+	in := map[string]any{
+		"title":       title,
+		"description": body,
+		"labels":      strings.Join(append([]string{IssueLabel}, t.labels...), ","),
+	}
+	if err := t.rest.do(ctx, http.MethodPost, "/projects/"+t.project+"/issues", in, &created); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(created.IID), nil
+}
+
+func (t *GitLabTracker) UpdateIssue(ctx context.Context, id, title, body string) error {
+	in := map[string]any{"title": title, "description": body}
+	return t.rest.do(ctx, http.MethodPut, "/projects/"+t.project+"/issues/"+id, in, nil)
+}
+
+func (t *GitLabTracker) CloseIssue(ctx context.Context, id, comment string) error {
+	if err := t.rest.do(ctx, http.MethodPost, "/projects/"+t.project+"/issues/"+id+"/notes", map[string]any{"body": comment}, nil); err != nil {
+		return err
+	}
+	return t.rest.do(ctx, http.MethodPut, "/projects/"+t.project+"/issues/"+id, map[string]any{"state_event": "close"}, nil)
+}
+
+// JiraTracker manages issues in a Jira project through the v2 REST API,
+// whose descriptions and comments are plain text.
+type JiraTracker struct {
+	rest      *restClient
+	project   string // project key
+	issueType string
+	labels    []string
+}
+
+// NewJiraTracker creates a tracker for the project with key project. With
+// an email it authenticates with basic auth and an API token (Jira Cloud),
+// otherwise with the token as a bearer personal access token (Data Center).
+// An empty issueType uses "Task".
+func NewJiraTracker(baseURL, project, email, token, issueType string, labels []string) *JiraTracker {
+	if issueType == "" {
+		issueType = "Task"
+	}
+	return &JiraTracker{
+		rest: newRESTClient(baseURL, func(req *http.Request) {
+			if email != "" {
+				req.SetBasicAuth(email, token)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+		}),
+		project:   project,
+		issueType: issueType,
+		labels:    labels,
+	}
+}
+
+func (t *JiraTracker) ListIssues(ctx context.Context) ([]TrackerIssue, error) {
+	jql := fmt.Sprintf(`project = %q AND labels = %q AND statusCategory != Done`, t.project, IssueLabel)
+	var issues []TrackerIssue
+	for start := 0; ; {
+		var page struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Key    string `json:"key"`
+				Fields struct {
+					Summary     string `json:"summary"`
+					Description string `json:"description"`
+				} `json:"fields"`
+			} `json:"issues"`
+		}
+		path := fmt.Sprintf("/rest/api/2/search?jql=%s&fields=summary,description&startAt=%d&maxResults=%d",
+			url.QueryEscape(jql), start, issuesPageSize)
+		if err := t.rest.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Issues {
+			issues = append(issues, TrackerIssue{ID: item.Key, Title: item.Fields.Summary, Body: item.Fields.Description})
+		}
+		start += len(page.Issues)
+		if len(page.Issues) == 0 || start >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+func (t *JiraTracker) CreateIssue(ctx context.Context, title, body string) (string, error) {
+	var created struct {
+		Key string `json:"key"`
+	}
+	in := map[string]any{"fields": map[string]any{
+		"project":     map[string]string{"key": t.project},
+		"issuetype":   map[string]string{"name": t.issueType},
+		"summary":     title,
+		"description": body,
+		"labels":      append([]string{IssueLabel}, t.labels...),
+	}}
+	if err := t.rest.do(ctx, http.MethodPost, "/rest/api/2/issue", in, &created); err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+func (t *JiraTracker) UpdateIssue(ctx context.Context, id, title, body string) error {
+	in := map[string]any{"fields": map[string]any{"summary": title, "description": body}}
+	return t.rest.do(ctx, http.MethodPut, "/rest/api/2/issue/"+id, in, nil)
+}
+
+// CloseIssue comments, then applies the first transition into the Done
+// status category; workflows name their transitions differently.
+func (t *JiraTracker) CloseIssue(ctx context.Context, id, comment string) error {
+	if err := t.rest.do(ctx, http.MethodPost, "/rest/api/2/issue/"+id+"/comment", map[string]any{"body": comment}, nil); err != nil {
+		return err
+	}
+
+	var transitions struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := t.rest.do(ctx, http.MethodGet, "/rest/api/2/issue/"+id+"/transitions", nil, &transitions); err != nil {
+		return err
+	}
+	for _, tr := range transitions.Transitions {
+		if tr.To.StatusCategory.Key == "done" {
+			in := map[string]any{"transition": map[string]string{"id": tr.ID}}
+			return t.rest.do(ctx, http.MethodPost, "/rest/api/2/issue/"+id+"/transitions", in, nil)
+		}
+	}
+	return fmt.Errorf("no transition to a done status for %s", id)
+}
+
+// Compile-time interface checks
+var (
+	_ IssueTracker = (*GitHubTracker)(nil)
+	_ IssueTracker = (*GitLabTracker)(nil)
+	_ IssueTracker = (*JiraTracker)(nil)
+)
@@ -0,0 +1,258 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/logging"
+)
+
+// DefaultWebhookBodyTemplate is a Slack, Mattermost and Teams compatible body.
+const DefaultWebhookBodyTemplate = `{"text": {{json .Text}}}`
+
+// Webhook request headers.
+const (
+	WebhookSignatureHeader = "X-Waxseal-Signature-256" // "sha256=" + hex HMAC of "<timestamp>.<body>"
+	WebhookTimestampHeader = "X-Waxseal-Timestamp"     // Unix seconds
+	WebhookIdempotencyKey  = "Idempotency-Key"         // the notice ID
+)
+
+// NoticeLog records notices already delivered, so each is sent once.
+type NoticeLog interface {
+	NoticeSent(id string) bool
+	RecordNotice(id string)
+}
+
+// Notice is the data available to a webhook body template.
+type Notice struct {
+	ID           string // stable per key, expiry date and lead time
+	ShortName    string
+	KeyName      string
+	ExpiresAt    string // RFC3339
+	DaysLeft     int    // negative once expired
+	LeadDays     int    // the leadTimeDays threshold crossed
+	RotationMode string
+	ManifestPath string
+	Text         string // a one-line human summary
+}
+
+// WebhookProvider implements Provider by posting a JSON notice to a webhook
+// when a key crosses one of its lead-time thresholds. Unlike calendar
+// reminders, notices are sent when due: run 'reminders sync' on a schedule.
+type WebhookProvider struct {
+	url           string
+	body          *template.Template
+	signingSecret []byte
+	leadTimeDays  []int
+	sent          NoticeLog
+	client        *http.Client
+	now           func() time.Time
+}
+
+// NewWebhookProvider creates a webhook provider. An empty bodyTemplate uses
+// DefaultWebhookBodyTemplate; an empty signingSecret sends unsigned
+// requests. sent tracks delivered notices across runs.
+func NewWebhookProvider(url, bodyTemplate string, signingSecret []byte, leadTimeDays []int, sent NoticeLog) (*WebhookProvider, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+	if bodyTemplate == "" {
+		bodyTemplate = DefaultWebhookBodyTemplate
+	}
+	body, err := ParseWebhookTemplate(bodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(leadTimeDays) == 0 {
+		leadTimeDays = []int{30, 7, 1} // Default: 30 days, 7 days, 1 day before
+	}
+
+	return &WebhookProvider{
+		url:           url,
+		body:          body,
+		signingSecret: signingSecret,
+		leadTimeDays:  leadTimeDays,
+		sent:          sent,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		now:           time.Now,
+	}, nil
+}
+
+// ParseWebhookTemplate parses a body template. Templates render a Notice
+// and may use {{json .Field}} to emit a JSON string literal.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	t, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook body template: %w", err)
+	}
+	return t, nil
+}
+
+// SyncReminders sends a notice for every key that has crossed a lead-time
+// threshold and has not been notified for it yet. When several thresholds
+// were crossed since the last run, only the most urgent is sent.
+func (p *WebhookProvider) SyncReminders(ctx context.Context, secrets []*core.SecretMetadata) (*SyncResult, error) {
+	result := &SyncResult{}
+	now := p.now()
+
+	for _, secret := range secrets {
+		if secret.IsRetired() {
+			result.Skipped++
+			continue
+		}
+
+		for _, key := range secret.Keys {
+			if key.Expiry == nil || key.Expiry.ExpiresAt == "" {
+				continue
+			}
+
+			expiresAt, err := time.Parse(time.RFC3339, key.Expiry.ExpiresAt)
+			if err != nil {
+				logging.Warn("invalid expiry date",
+					"secret", secret.ShortName,
+					"key", key.KeyName,
+					"expiresAt", key.Expiry.ExpiresAt,
+				)
+				continue
+			}
+
+			// Thresholds crossed, most urgent first
+			var crossed []int
+			for _, days := range p.leadTimeDays {
+				if !now.Before(expiresAt.AddDate(0, 0, -days)) {
+					crossed = append(crossed, days)
+				}
+			}
+			if len(crossed) == 0 {
+				continue
+			}
+			slices.Sort(crossed)
+
+			notice := p.notice(secret, key, expiresAt, crossed[0], now)
+			if p.sent != nil && p.sent.NoticeSent(notice.ID) {
+				result.Skipped++
+				continue
+			}
+
+			if err := p.post(ctx, notice, now); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("notify %s/%s: %w", secret.ShortName, key.KeyName, err))
+				continue
+			}
+			result.Created++
+
+			// The less urgent thresholds are covered by this notice
+			if p.sent != nil {
+				for _, days := range crossed {
+					p.sent.RecordNotice(webhookNoticeID(secret.ShortName, key.KeyName, expiresAt, days))
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteReminders is a no-op: a delivered message cannot be recalled.
+func (p *WebhookProvider) DeleteReminders(ctx context.Context, shortName string) error {
+	return nil
+}
+
+func (p *WebhookProvider) notice(secret *core.SecretMetadata, key core.KeyMetadata, expiresAt time.Time, leadDays int, now time.Time) Notice {
+	daysLeft := int(expiresAt.Sub(now).Hours() / 24)
+
+	text := fmt.Sprintf("🔐 Secret %s/%s expires in %d days (%s). Rotate with: waxseal rotate %s %s",
+		secret.ShortName, key.KeyName, daysLeft, expiresAt.Format("2006-01-02"), secret.ShortName, key.KeyName)
+	switch {
+	case expiresAt.Before(now):
+		text = fmt.Sprintf("🚨 Secret %s/%s EXPIRED on %s. Rotate with: waxseal rotate %s %s",
+			secret.ShortName, key.KeyName, expiresAt.Format("2006-01-02"), secret.ShortName, key.KeyName)
+	case daysLeft == 0:
+		text = fmt.Sprintf("🚨 Secret %s/%s expires TODAY. Rotate with: waxseal rotate %s %s",
+			secret.ShortName, key.KeyName, secret.ShortName, key.KeyName)
+	}
+
+	return Notice{
+		ID:           webhookNoticeID(secret.ShortName, key.KeyName, expiresAt, leadDays),
+		ShortName:    secret.ShortName,
+		KeyName:      key.KeyName,
+		ExpiresAt:    expiresAt.UTC().Format(time.RFC3339),
+		DaysLeft:     daysLeft,
+		LeadDays:     leadDays,
+		RotationMode: getRotationMode(key),
+		ManifestPath: secret.ManifestPath,
+		Text:         text,
+	}
+}
+
+// post renders and sends one notice.
+func (p *WebhookProvider) post(ctx context.Context, notice Notice, now time.Time) error {
+	var body bytes.Buffer
+	if err := p.body.Execute(&body, notice); err != nil {
+		return fmt.Errorf("render body: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return fmt.Errorf("body template did not render valid JSON")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIdempotencyKey, notice.ID)
+	if len(p.signingSecret) > 0 {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(p.signingSecret, timestamp, body.Bytes()))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>", the
+// value of the signature header after "sha256=". Receivers recompute it to
+// check a request came from waxseal and was not replayed with a new timestamp.
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookNoticeID identifies a notice. It changes with the expiry date, so
+// a key re-dated by a rotation is notified again.
+func webhookNoticeID(shortName, keyName string, expiresAt time.Time, leadDays int) string {
+	return fmt.Sprintf("webhook:%s/%s@%s/%dd", shortName, keyName, expiresAt.UTC().Format("2006-01-02T15:04:05Z"), leadDays)
+}
+
+// Compile-time interface check
+var _ Provider = (*WebhookProvider)(nil)
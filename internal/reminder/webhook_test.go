@@ -0,0 +1,196 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// memoryNoticeLog is an in-memory NoticeLog.
+type memoryNoticeLog map[string]bool
+
+func (m memoryNoticeLog) NoticeSent(id string) bool { return m[id] }
+func (m memoryNoticeLog) RecordNotice(id string)    { m[id] = true }
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, *[]webhookRequest) {
+	t.Helper()
+	var requests []webhookRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, webhookRequest{header: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func expiringSecret(expiresAt time.Time) *core.SecretMetadata {
+	return &core.SecretMetadata{
+		ShortName:    "app",
+		ManifestPath: "apps/app/sealed.yaml",
+		Keys: []core.KeyMetadata{
+			{
+				KeyName:  "api_key",
+				Rotation: &core.RotationConfig{Mode: "external"},
+				Expiry:   &core.ExpiryConfig{ExpiresAt: expiresAt.Format(time.RFC3339)},
+			},
+			{KeyName: "username"},
+		},
+	}
+}
+
+func TestWebhookProvider_SendsOncePerThreshold(t *testing.T) {
+	srv, requests := newWebhookServer(t, http.StatusOK)
+	sent := memoryNoticeLog{}
+	p, err := NewWebhookProvider(srv.URL, "", []byte("s3cret"), []int{30, 7, 1}, sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	// 5 days out: the 30 and 7 day thresholds are crossed, one notice for 7
+	secrets := []*core.SecretMetadata{expiringSecret(now.AddDate(0, 0, 5))}
+	result, err := p.SyncReminders(context.Background(), secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 1 || len(*requests) != 1 {
+		t.Fatalf("created %d, requests %d, want 1", result.Created, len(*requests))
+	}
+
+	req := (*requests)[0]
+	var body struct{ Text string }
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if !strings.Contains(body.Text, "app/api_key") || !strings.Contains(body.Text, "5 days") {
+		t.Errorf("text = %q", body.Text)
+	}
+	if got := req.header.Get(WebhookIdempotencyKey); !strings.HasSuffix(got, "/7d") {
+		t.Errorf("idempotency key = %q, want the 7 day notice", got)
+	}
+	want := "sha256=" + SignWebhook([]byte("s3cret"), req.header.Get(WebhookTimestampHeader), req.body)
+	if got := req.header.Get(WebhookSignatureHeader); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if len(sent) != 2 {
+		t.Errorf("recorded %d notices, want 2 (30 and 7 days)", len(sent))
+	}
+
+	// Same day again: nothing new
+	result, err = p.SyncReminders(context.Background(), secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 0 || result.Skipped != 1 || len(*requests) != 1 {
+		t.Errorf("second sync created %d, skipped %d, requests %d", result.Created, result.Skipped, len(*requests))
+	}
+
+	// The 1 day threshold is a new notice
+	now = now.AddDate(0, 0, 4)
+	if _, err := p.SyncReminders(context.Background(), secrets); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("requests = %d, want a second notice at 1 day", len(*requests))
+	}
+}
+
+func TestWebhookProvider_NotDueYet(t *testing.T) {
+	srv, requests := newWebhookServer(t, http.StatusOK)
+	p, err := NewWebhookProvider(srv.URL, "", nil, []int{30, 7}, memoryNoticeLog{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := []*core.SecretMetadata{expiringSecret(time.Now().AddDate(0, 0, 60))}
+	result, err := p.SyncReminders(context.Background(), secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 0 || len(*requests) != 0 {
+		t.Errorf("sent %d notices before any threshold", len(*requests))
+	}
+}
+
+func TestWebhookProvider_FailureIsRetried(t *testing.T) {
+	srv, requests := newWebhookServer(t, http.StatusInternalServerError)
+	sent := memoryNoticeLog{}
+	p, err := NewWebhookProvider(srv.URL, "", nil, []int{7}, sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := []*core.SecretMetadata{expiringSecret(time.Now().AddDate(0, 0, 3))}
+	result, err := p.SyncReminders(context.Background(), secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || len(sent) != 0 {
+		t.Fatalf("errors %v, recorded %d; a failed notice must not be recorded", result.Errors, len(sent))
+	}
+	if (*requests)[0].header.Get(WebhookSignatureHeader) != "" {
+		t.Error("unsigned provider sent a signature")
+	}
+
+	if _, err := p.SyncReminders(context.Background(), secrets); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("requests = %d, want the failed notice retried", len(*requests))
+	}
+}
+
+func TestWebhookProvider_BodyTemplate(t *testing.T) {
+	srv, requests := newWebhookServer(t, http.StatusNoContent)
+	tmpl := `{"title": {{json .ShortName}}, "key": {{json .KeyName}}, "days": {{.DaysLeft}}, "mode": {{json .RotationMode}}}`
+	p, err := NewWebhookProvider(srv.URL, tmpl, nil, []int{7}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := []*core.SecretMetadata{expiringSecret(time.Now().Add(50 * time.Hour))}
+	if _, err := p.SyncReminders(context.Background(), secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal((*requests)[0].body, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["title"] != "app" || body["key"] != "api_key" || body["days"] != float64(2) || body["mode"] != "external" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestWebhookProvider_InvalidTemplate(t *testing.T) {
+	if _, err := NewWebhookProvider("http://localhost", "{{.Nope", nil, nil, nil); err == nil {
+		t.Error("expected error for an unparseable template")
+	}
+
+	srv, requests := newWebhookServer(t, http.StatusOK)
+	p, err := NewWebhookProvider(srv.URL, `not json {{.ShortName}}`, nil, []int{7}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.SyncReminders(context.Background(), []*core.SecretMetadata{expiringSecret(time.Now().AddDate(0, 0, 1))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || len(*requests) != 0 {
+		t.Errorf("errors %v, requests %d; invalid JSON must not be sent", result.Errors, len(*requests))
+	}
+}
@@ -0,0 +1,96 @@
+package reseal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/shermanhuman/waxseal/internal/seal"
+)
+
+// digestPrefix identifies the digest algorithm in the manifest annotation.
+const digestPrefix = "hmac-sha256:"
+
+// plaintextDigest returns a keyed digest of a key's plaintext, bound to the
+// identity the ciphertext is sealed for. The digest key never leaves the
+// store, so the annotation cannot be used to guess low-entropy values.
+func plaintextDigest(digestKey []byte, namespace, name, scope, keyName string, plaintext []byte) string {
+	mac := hmac.New(sha256.New, digestKey)
+	for _, part := range []string{scope, namespace, name, keyName} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	mac.Write(plaintext)
+	return digestPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// reusableCiphertext returns the encryptedData entries of an existing
+// manifest together with their recorded digests, if the manifest was sealed
+// with the given cert. Returns nil if nothing can be reused.
+func reusableCiphertext(existing *seal.SealedSecret, fingerprint string) (encrypted, digests map[string]string) {
+	if existing == nil || fingerprint == "" {
+		return nil, nil
+	}
+	annotations := existing.Metadata.Annotations
+	if annotations[seal.AnnotationCertFingerprint] != fingerprint {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(annotations[seal.AnnotationPlaintextDigests]), &digests); err != nil {
+		return nil, nil
+	}
+	return existing.Spec.EncryptedData, digests
+}
+
+// setStableAnnotations records the sealing cert and, if digests were
+// computed, the plaintext digests. Without digests, any stale digest
+// annotation is removed.
+func setStableAnnotations(ss *seal.SealedSecret, fingerprint string, digests map[string]string) error {
+	if ss.Metadata.Annotations == nil {
+		ss.Metadata.Annotations = make(map[string]string)
+	}
+	ss.Metadata.Annotations[seal.AnnotationCertFingerprint] = fingerprint
+	if digests == nil {
+		delete(ss.Metadata.Annotations, seal.AnnotationPlaintextDigests)
+		return nil
+	}
+
+	// encoding/json sorts map keys, keeping the annotation stable
+	encoded, err := json.Marshal(digests)
+	if err != nil {
+		return err
+	}
+	ss.Metadata.Annotations[seal.AnnotationPlaintextDigests] = string(encoded)
+	return nil
+}
+
+// clearStableAnnotations removes digests that no longer describe the
+// manifest's ciphertext, so they are never trusted on a later reseal.
+func clearStableAnnotations(ss *seal.SealedSecret) {
+	delete(ss.Metadata.Annotations, seal.AnnotationCertFingerprint)
+	delete(ss.Metadata.Annotations, seal.AnnotationPlaintextDigests)
+	if len(ss.Metadata.Annotations) == 0 {
+		ss.Metadata.Annotations = nil
+	}
+}
+
+// ForgetDigest drops the recorded digest of a key whose ciphertext was
+// replaced outside the engine, so the next reseal encrypts it again instead
+// of trusting a digest of the old value. If the new ciphertext was sealed
+// with a different cert, no entry can be trusted and both annotations go.
+func ForgetDigest(ss *seal.SealedSecret, keyName, fingerprint string) {
+	annotations := ss.Metadata.Annotations
+	if _, ok := annotations[seal.AnnotationPlaintextDigests]; !ok {
+		return
+	}
+	var digests map[string]string
+	if fingerprint == "" || annotations[seal.AnnotationCertFingerprint] != fingerprint ||
+		json.Unmarshal([]byte(annotations[seal.AnnotationPlaintextDigests]), &digests) != nil {
+		clearStableAnnotations(ss)
+		return
+	}
+	delete(digests, keyName)
+	if err := setStableAnnotations(ss, fingerprint, digests); err != nil {
+		clearStableAnnotations(ss)
+	}
+}
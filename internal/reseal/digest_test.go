@@ -0,0 +1,208 @@
+package reseal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/seal"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+const stableMetadata = `shortName: stable
+manifestPath: apps/stable/sealed-secret.yaml
+sealedSecret:
+  name: stable
+  namespace: test
+  scope: strict
+status: active
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "PASSWORD_VERSION"
+    rotation:
+      mode: static
+  - keyName: username
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/username
+      version: "1"
+    rotation:
+      mode: static
+`
+
+func writeStableMetadata(t *testing.T, dir, passwordVersion string) {
+	t.Helper()
+	metadataDir := filepath.Join(dir, ".waxseal", "metadata")
+	os.MkdirAll(metadataDir, 0o755)
+	data := strings.Replace(stableMetadata, "PASSWORD_VERSION", passwordVersion, 1)
+	if err := os.WriteFile(filepath.Join(metadataDir, "stable.yaml"), []byte(data), 0o644); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+}
+
+func readStableManifest(t *testing.T, dir string) *seal.SealedSecret {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "apps", "stable", "sealed-secret.yaml"))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	ss, err := seal.ParseSealedSecret(data)
+	if err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	return ss
+}
+
+func newTestCertSealer(t *testing.T) *seal.CertSealer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	sealer, err := seal.NewCertSealerFromPEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	if err != nil {
+		t.Fatalf("create sealer: %v", err)
+	}
+	return sealer
+}
+
+func TestEngine_StableCiphertext(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeStableMetadata(t, dir, "1")
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("pw-1"))
+	fakeStore.SetVersion("projects/test/secrets/password", "2", []byte("pw-2"))
+	fakeStore.SetVersion("projects/test/secrets/username", "1", []byte("admin"))
+
+	sealer := newTestCertSealer(t)
+	digestKey := []byte("0123456789abcdef0123456789abcdef")
+
+	newEngine := func(s seal.Sealer) *Engine {
+		e := NewEngine(fakeStore, s, dir, false)
+		e.SetDigestKey(digestKey)
+		return e
+	}
+
+	// First reseal seals everything and records digests
+	first, err := newEngine(sealer).ResealOne(ctx, "stable")
+	if err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+	if first.KeysResealed != 2 || first.Unchanged {
+		t.Errorf("first reseal = %+v, want 2 keys resealed", first)
+	}
+	original := readStableManifest(t, dir)
+	if original.Metadata.Annotations[seal.AnnotationCertFingerprint] != sealer.GetCertFingerprint() {
+		t.Error("manifest should record the sealing cert fingerprint")
+	}
+	digests := original.Metadata.Annotations[seal.AnnotationPlaintextDigests]
+	if strings.Contains(digests, "pw-1") || strings.Contains(digests, "admin") {
+		t.Error("digest annotation leaks plaintext")
+	}
+
+	// Nothing changed: the file is left untouched
+	second, err := newEngine(sealer).ResealOne(ctx, "stable")
+	if err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+	if !second.Unchanged || second.KeysResealed != 0 {
+		t.Errorf("second reseal = %+v, want unchanged", second)
+	}
+
+	// One value changed: only that key gets new ciphertext
+	writeStableMetadata(t, dir, "2")
+	third, err := newEngine(sealer).ResealOne(ctx, "stable")
+	if err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+	if third.KeysResealed != 1 || third.KeysUnchanged != 1 {
+		t.Errorf("third reseal = %+v, want 1 resealed and 1 unchanged", third)
+	}
+	updated := readStableManifest(t, dir)
+	if updated.Spec.EncryptedData["username"] != original.Spec.EncryptedData["username"] {
+		t.Error("unchanged key should keep its ciphertext")
+	}
+	if updated.Spec.EncryptedData["password"] == original.Spec.EncryptedData["password"] {
+		t.Error("changed key should be re-encrypted")
+	}
+
+	// A new cert invalidates every entry
+	fourth, err := newEngine(newTestCertSealer(t)).ResealOne(ctx, "stable")
+	if err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+	if fourth.KeysResealed != 2 {
+		t.Errorf("reseal after cert change = %+v, want 2 keys resealed", fourth)
+	}
+}
+
+func TestEngine_NoDigestKeyAlwaysReseals(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeStableMetadata(t, dir, "1")
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("pw-1"))
+	fakeStore.SetVersion("projects/test/secrets/username", "1", []byte("admin"))
+
+	engine := NewEngine(fakeStore, newTestCertSealer(t), dir, false)
+	engine.ResealOne(ctx, "stable")
+	result, err := engine.ResealOne(ctx, "stable")
+	if err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+	if result.Unchanged || result.KeysResealed != 2 {
+		t.Errorf("result = %+v, want 2 keys resealed", result)
+	}
+	if _, ok := readStableManifest(t, dir).Metadata.Annotations[seal.AnnotationPlaintextDigests]; ok {
+		t.Error("digests should not be recorded without a digest key")
+	}
+}
+
+func TestPlaintextDigest_BindsIdentity(t *testing.T) {
+	key := []byte("k")
+	base := plaintextDigest(key, "ns", "name", "strict", "password", []byte("v"))
+
+	variants := []string{
+		plaintextDigest([]byte("other"), "ns", "name", "strict", "password", []byte("v")),
+		plaintextDigest(key, "ns2", "name", "strict", "password", []byte("v")),
+		plaintextDigest(key, "ns", "name2", "strict", "password", []byte("v")),
+		plaintextDigest(key, "ns", "name", "cluster-wide", "password", []byte("v")),
+		plaintextDigest(key, "ns", "name", "strict", "token", []byte("v")),
+		plaintextDigest(key, "ns", "name", "strict", "password", []byte("w")),
+	}
+	for i, v := range variants {
+		if v == base {
+			t.Errorf("variant %d should produce a different digest", i)
+		}
+	}
+	if !strings.HasPrefix(base, digestPrefix) {
+		t.Errorf("digest %q missing %q prefix", base, digestPrefix)
+	}
+}
@@ -2,13 +2,18 @@
 package reseal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/shermanhuman/waxseal/internal/core"
 	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/kustomize"
 	"github.com/shermanhuman/waxseal/internal/logging"
 	"github.com/shermanhuman/waxseal/internal/seal"
 	"github.com/shermanhuman/waxseal/internal/store"
@@ -17,28 +22,76 @@ import (
 
 // Engine performs reseal operations.
 type Engine struct {
-	store   store.Store
-	sealer  seal.Sealer
-	repoDir string
-	dryRun  bool
+	store       store.Store
+	sealer      seal.Sealer
+	repoDir     string
+	dryRun      bool
+	concurrency int
+	digestKey   []byte
+	env         string
+	staleOnly   bool
+
+	// pathLocks serializes writes to the same manifest file when several
+	// secrets are resealed in parallel.
+	pathLocks sync.Map // manifest path -> *sync.Mutex
+
+	// kustomizations is indexed once, on first use.
+	kustomizationsOnce sync.Once
+	kustomizations     *kustomize.Index
+	kustomizationsErr  error
 }
 
 // NewEngine creates a new reseal engine.
 func NewEngine(store store.Store, sealer seal.Sealer, repoDir string, dryRun bool) *Engine {
 	return &Engine{
-		store:   store,
-		sealer:  sealer,
-		repoDir: repoDir,
-		dryRun:  dryRun,
+		store:       store,
+		sealer:      sealer,
+		repoDir:     repoDir,
+		dryRun:      dryRun,
+		concurrency: 1,
 	}
 }
 
+// SetConcurrency sets how many secrets ResealAll processes in parallel.
+// Values below 1 are treated as 1 (sequential). The store and sealer must be
+// safe for concurrent use when n > 1.
+func (e *Engine) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	e.concurrency = n
+}
+
+// SetEnvironment selects the environment to reseal for. Only secrets that
+// target it are resealed, and their manifest path is resolved for it.
+// The sealer must use that environment's certificate.
+func (e *Engine) SetEnvironment(env string) {
+	e.env = env
+}
+
+// SetDigestKey enables stable ciphertext. With a key set, the engine records
+// a keyed digest of each plaintext in the manifest next to the sealing cert
+// fingerprint, and keeps existing encryptedData entries whose digest and
+// cert still match instead of re-encrypting them.
+func (e *Engine) SetDigestKey(key []byte) {
+	e.digestKey = key
+}
+
 // Result represents the result of a reseal operation.
 type Result struct {
-	ShortName    string
-	KeysResealed int
-	Error        error
-	DryRun       bool
+	ShortName     string
+	Env           string // environment resealed for ("" for the top-level profile)
+	KeysResealed  int    // keys encrypted afresh
+	KeysUnchanged int    // keys whose existing ciphertext was kept
+	Unchanged     bool   // manifest already up to date; not rewritten
+	Error         error
+	DryRun        bool
+
+	// DerivedExpiry is the expiry read from the values of keys with
+	// expiry.source derived, by key name; ExpiryErrors holds the keys whose
+	// value carries no readable expiry. The engine does not write metadata.
+	DerivedExpiry map[string]time.Time
+	ExpiryErrors  map[string]error
 }
 
 // ResealOne reseals a single secret by its short name.
@@ -54,6 +107,10 @@ func (e *Engine) ResealOne(ctx context.Context, shortName string) (*Result, erro
 		return nil, fmt.Errorf("%s: %w", shortName, core.ErrRetired)
 	}
 
+	if !metadata.TargetsEnv(e.env) {
+		return nil, core.NewValidationError("environment", e.notTargetedMessage(metadata))
+	}
+
 	return e.resealFromMetadata(ctx, metadata)
 }
 
@@ -72,34 +129,88 @@ func (e *Engine) ResealAll(ctx context.Context) ([]*Result, error) {
 		results = append(results, &Result{Error: err})
 	}
 
+	var active []*core.SecretMetadata
 	for _, metadata := range allSecrets {
 		// Skip retired secrets silently
 		if metadata.IsRetired() {
 			logging.Info("skipping retired secret", "shortName", metadata.ShortName)
 			continue
 		}
+		if !metadata.TargetsEnv(e.env) {
+			continue
+		}
+		active = append(active, metadata)
+	}
 
-		result, err := e.resealFromMetadata(ctx, metadata)
+	if e.staleOnly {
+		fingerprint, err := e.currentFingerprint()
 		if err != nil {
-			// Record error but continue with other secrets
-			results = append(results, &Result{
-				ShortName: metadata.ShortName,
-				Error:     err,
-			})
-			continue
+			return nil, err
 		}
-		results = append(results, result)
+		stale := active[:0]
+		for _, metadata := range active {
+			if _, current := e.staleness(metadata, fingerprint); current {
+				logging.Info("skipping secret sealed with the current cert", "shortName", metadata.ShortName)
+				continue
+			}
+			stale = append(stale, metadata)
+		}
+		active = stale
+	}
+
+	// Each worker writes only its own slot, so results keep metadata order
+	// regardless of completion order.
+	resealed := make([]*Result, len(active))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	workers := min(e.concurrency, len(active))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				resealed[i] = e.resealForResult(ctx, active[i])
+			}
+		}()
+	}
+	for i := range active {
+		indexes <- i
 	}
+	close(indexes)
+	wg.Wait()
 
-	return results, nil
+	return append(results, resealed...), nil
 }
 
-func (e *Engine) resealFromMetadata(ctx context.Context, metadata *core.SecretMetadata) (*Result, error) {
-	logging.Info("resealing secret",
-		"shortName", metadata.ShortName,
-		"keyCount", len(metadata.Keys),
-	)
+// resealForResult reseals one secret, recording any error in the Result so
+// that other secrets continue.
+func (e *Engine) resealForResult(ctx context.Context, metadata *core.SecretMetadata) *Result {
+	result, err := e.resealFromMetadata(ctx, metadata)
+	if err != nil {
+		return &Result{
+			ShortName: metadata.ShortName,
+			Env:       e.env,
+			Error:     err,
+		}
+	}
+	return result
+}
+
+// notTargetedMessage explains why a secret is not resealed for e.env.
+func (e *Engine) notTargetedMessage(metadata *core.SecretMetadata) string {
+	if e.env == "" {
+		return fmt.Sprintf("%s targets environments %v; choose one with --env", metadata.ShortName, metadata.Environments)
+	}
+	if len(metadata.Environments) == 0 {
+		return fmt.Sprintf("%s does not target environments; reseal it without --env", metadata.ShortName)
+	}
+	return fmt.Sprintf("%s does not target environment %q (targets %v)", metadata.ShortName, e.env, metadata.Environments)
+}
 
+// resolveValues fetches every key's plaintext from the store, evaluating
+// computed keys after the values they depend on.
+func (e *Engine) resolveValues(ctx context.Context, metadata *core.SecretMetadata) (map[string]string, error) {
 	// Fetch all GSM values first
 	keyValues := make(map[string]string)
 	for _, key := range metadata.Keys {
@@ -173,37 +284,175 @@ func (e *Engine) resealFromMetadata(ctx context.Context, metadata *core.SecretMe
 		}
 	}
 
+	return keyValues, nil
+}
+
+// kustomizeTransform resolves the kustomize transform for a manifest
+// against the repo's kustomizations, indexed on first use.
+func (e *Engine) kustomizeTransform(manifestPath string) (kustomize.Transform, error) {
+	e.kustomizationsOnce.Do(func() {
+		e.kustomizations, e.kustomizationsErr = kustomize.LoadIndex(e.repoDir)
+	})
+	if e.kustomizationsErr != nil {
+		return kustomize.Transform{}, e.kustomizationsErr
+	}
+	return e.kustomizations.ForFile(manifestPath)
+}
+
+// readSealedSecret reads a secret's manifest for the engine's environment
+// and returns its SealedSecret document.
+func (e *Engine) readSealedSecret(metadata *core.SecretMetadata) (*seal.SealedSecret, error) {
+	relPath := metadata.ManifestPathFor(e.env)
+	manifestPath := relPath
+	if !filepath.IsAbs(manifestPath) {
+		manifestPath = filepath.Join(e.repoDir, manifestPath)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	transform, err := e.kustomizeTransform(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve kustomization for %s: %w", relPath, err)
+	}
+	fileNamespace, fileName := transform.Unapply(metadata.SealedSecret.Namespace, metadata.SealedSecret.Name)
+	located, err := seal.FindSealedSecret(data, fileNamespace, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", relPath, err)
+	}
+	if located == nil {
+		return nil, fmt.Errorf("no SealedSecret %s/%s in %s",
+			metadata.SealedSecret.Namespace, metadata.SealedSecret.Name, relPath)
+	}
+	return located.SealedSecret, nil
+}
+
+// lockPath returns the mutex guarding writes to a manifest path.
+func (e *Engine) lockPath(path string) *sync.Mutex {
+	mu, _ := e.pathLocks.LoadOrStore(path, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+func (e *Engine) resealFromMetadata(ctx context.Context, metadata *core.SecretMetadata) (*Result, error) {
+	logging.Info("resealing secret",
+		"shortName", metadata.ShortName,
+		"keyCount", len(metadata.Keys),
+	)
+
+	keyValues, err := e.resolveValues(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	derivedExpiry, expiryErrors := deriveExpiries(metadata, keyValues)
+
+	manifestPath := metadata.ManifestPathFor(e.env)
+	if !filepath.IsAbs(manifestPath) {
+		manifestPath = filepath.Join(e.repoDir, manifestPath)
+	}
+
+	// Hold the manifest lock across read and write so parallel reseals of
+	// secrets sharing a file never interleave.
+	mu := e.lockPath(manifestPath)
+	mu.Lock()
+	defer mu.Unlock()
+
+	existingData, err := os.ReadFile(manifestPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	// Record the sealing cert; with a digest key, also reuse ciphertext
+	// whose plaintext digest and sealing cert are unchanged
+	var fingerprint string
+	if fp, ok := e.sealer.(seal.Fingerprinter); ok {
+		fingerprint = fp.GetCertFingerprint()
+	}
+	// Metadata holds the effective identity (used for the sealing label);
+	// the manifest holds it before the kustomizations that build it rename it
+	transform, err := e.kustomizeTransform(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve kustomization for %s: %w", metadata.ManifestPathFor(e.env), err)
+	}
+	fileNamespace, fileName := transform.Unapply(metadata.SealedSecret.Namespace, metadata.SealedSecret.Name)
+
+	// The manifest file may bundle other documents; only ours is replaced
+	located, err := seal.FindSealedSecret(existingData, fileNamespace, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("parse existing manifest %s: %w", metadata.ManifestPathFor(e.env), err)
+	}
+	var existing *seal.SealedSecret
+	if located != nil {
+		existing = located.SealedSecret
+	}
+	var reusable, oldDigests, digests map[string]string
+	if e.digestKey != nil && fingerprint != "" {
+		reusable, oldDigests = reusableCiphertext(existing, fingerprint)
+		digests = make(map[string]string)
+	}
+
 	// Seal all keys
 	encryptedData := make(map[string]string)
 	scope := metadata.SealedSecret.Scope
 	name := metadata.SealedSecret.Name
 	namespace := metadata.SealedSecret.Namespace
+	var keysResealed, keysUnchanged int
 
 	for keyName, plaintext := range keyValues {
+		if digests != nil {
+			digests[keyName] = plaintextDigest(e.digestKey, namespace, name, scope, keyName, []byte(plaintext))
+			if old, ok := reusable[keyName]; ok && old != "" && oldDigests[keyName] == digests[keyName] {
+				encryptedData[keyName] = old
+				keysUnchanged++
+				continue
+			}
+		}
+
 		encrypted, err := e.sealer.Seal(name, namespace, keyName, []byte(plaintext), scope)
 		if err != nil {
 			return nil, fmt.Errorf("seal %s/%s: %w", metadata.ShortName, keyName, err)
 		}
 		encryptedData[keyName] = encrypted
+		keysResealed++
 	}
 
 	// Build the SealedSecret manifest
-	ss := seal.NewSealedSecret(
-		metadata.SealedSecret.Name,
-		metadata.SealedSecret.Namespace,
-		metadata.SealedSecret.Scope,
-		metadata.SealedSecret.Type,
-		encryptedData,
-	)
-	manifest, err := ss.ToYAML()
+	ss := buildManifest(existing, metadata, transform, encryptedData)
+	if fingerprint != "" {
+		if err := setStableAnnotations(ss, fingerprint, digests); err != nil {
+			return nil, fmt.Errorf("record sealing cert for %s: %w", metadata.ShortName, err)
+		}
+	} else {
+		clearStableAnnotations(ss)
+	}
+	document, err := ss.ToYAML()
 	if err != nil {
 		return nil, fmt.Errorf("serialize manifest for %s: %w", metadata.ShortName, err)
 	}
+	var manifest []byte
+	if located != nil {
+		manifest = files.ReplaceYAMLDocument(existingData, located.Document, document)
+	} else {
+		manifest = files.AppendYAMLDocument(existingData, document)
+	}
 
-	// Write the manifest
-	manifestPath := metadata.ManifestPath
-	if !filepath.IsAbs(manifestPath) {
-		manifestPath = filepath.Join(e.repoDir, manifestPath)
+	result := &Result{
+		ShortName:     metadata.ShortName,
+		Env:           e.env,
+		KeysResealed:  keysResealed,
+		KeysUnchanged: keysUnchanged,
+		DryRun:        e.dryRun,
+		DerivedExpiry: derivedExpiry,
+		ExpiryErrors:  expiryErrors,
+	}
+
+	// Leave the file alone if nothing changed, so Git sees no diff
+	if bytes.Equal(manifest, existingData) {
+		result.KeysResealed = 0
+		result.KeysUnchanged = len(encryptedData)
+		result.Unchanged = true
+		logging.Info("manifest unchanged", "path", manifestPath)
+		return result, nil
 	}
 
 	if e.dryRun {
@@ -211,11 +460,7 @@ func (e *Engine) resealFromMetadata(ctx context.Context, metadata *core.SecretMe
 			"path", manifestPath,
 			"keys", len(encryptedData),
 		)
-		return &Result{
-			ShortName:    metadata.ShortName,
-			KeysResealed: len(encryptedData),
-			DryRun:       true,
-		}, nil
+		return result, nil
 	}
 
 	writer := files.NewAtomicWriter(files.YAMLKindValidator("SealedSecret"))
@@ -226,12 +471,42 @@ func (e *Engine) resealFromMetadata(ctx context.Context, metadata *core.SecretMe
 	logging.Info("wrote sealed manifest",
 		"path", manifestPath,
 		"keys", len(encryptedData),
+		"resealed", keysResealed,
 	)
 
-	return &Result{
-		ShortName:    metadata.ShortName,
-		KeysResealed: len(encryptedData),
-	}, nil
+	return result, nil
+}
+
+// buildManifest returns the manifest to write. An existing manifest is kept
+// as-is (labels, annotations, the whole template and any other fields) with
+// only the identity, encryptedData, scope and type replaced; otherwise a
+// fresh one is built. The identity written is the one before kustomize
+// transforms it.
+func buildManifest(existing *seal.SealedSecret, metadata *core.SecretMetadata, transform kustomize.Transform, encryptedData map[string]string) *seal.SealedSecret {
+	namespace, name := transform.Unapply(metadata.SealedSecret.Namespace, metadata.SealedSecret.Name)
+
+	if existing == nil {
+		if namespace == "" {
+			namespace = metadata.SealedSecret.Namespace
+		}
+		return seal.NewSealedSecret(
+			name,
+			namespace,
+			metadata.SealedSecret.Scope,
+			metadata.SealedSecret.Type,
+			encryptedData,
+		)
+	}
+
+	ss := existing
+	ss.Metadata.Name = name
+	if namespace != "" {
+		ss.Metadata.Namespace = namespace
+	}
+	ss.Spec.EncryptedData = encryptedData
+	ss.SetScope(metadata.SealedSecret.Scope)
+	ss.SetSecretType(metadata.SealedSecret.Type)
+	return ss
 }
 
 func (e *Engine) evaluateComputed(config *core.ComputedConfig, keyValues map[string]string, allKeys []core.KeyMetadata) (string, error) {
@@ -2,10 +2,17 @@ package reseal
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/kustomize"
 	"github.com/shermanhuman/waxseal/internal/seal"
 	"github.com/shermanhuman/waxseal/internal/store"
 )
@@ -250,6 +257,94 @@ keys:
 	}
 }
 
+// trackingSealer records the peak number of concurrent Seal calls.
+type trackingSealer struct {
+	seal.FakeSealer
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (s *trackingSealer) Seal(name, namespace, key string, value []byte, scope string) (string, error) {
+	s.mu.Lock()
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+	return s.FakeSealer.Seal(name, namespace, key, value, scope)
+}
+
+func TestEngine_ResealAll_Concurrent(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := dir + "/.waxseal/metadata"
+	_ = os.MkdirAll(metadataDir, 0o755)
+
+	fakeStore := store.NewFakeStore()
+	var want []string
+	for i := range 12 {
+		name := fmt.Sprintf("secret-%02d", i)
+		want = append(want, name)
+		metadata := fmt.Sprintf(`shortName: %[1]s
+manifestPath: apps/%[1]s/sealed-secret.yaml
+sealedSecret:
+  name: %[1]s
+  namespace: test
+  scope: strict
+status: active
+keys:
+  - keyName: value
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/%[1]s
+      version: "1"
+    rotation:
+      mode: static
+`, name)
+		_ = os.WriteFile(metadataDir+"/"+name+".yaml", []byte(metadata), 0o644)
+
+		// Every third secret is missing from the store and must fail alone
+		if i%3 != 0 {
+			fakeStore.SetVersion("projects/test/secrets/"+name, "1", []byte("v"))
+		}
+	}
+
+	sealer := &trackingSealer{FakeSealer: *seal.NewFakeSealer()}
+	engine := NewEngine(fakeStore, sealer, dir, false)
+	engine.SetConcurrency(4)
+
+	results, err := engine.ResealAll(ctx)
+	if err != nil {
+		t.Fatalf("ResealAll failed: %v", err)
+	}
+
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, r := range results {
+		if r.ShortName != want[i] {
+			t.Errorf("results[%d] = %q, want %q (ordering must be deterministic)", i, r.ShortName, want[i])
+		}
+		if failed := r.Error != nil; failed != (i%3 == 0) {
+			t.Errorf("%s: error = %v", r.ShortName, r.Error)
+		}
+	}
+
+	if sealer.peak > 4 {
+		t.Errorf("peak concurrency = %d, want <= 4", sealer.peak)
+	}
+	if sealer.peak < 2 {
+		t.Errorf("peak concurrency = %d, expected parallel sealing", sealer.peak)
+	}
+}
+
 func containsStr(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if s[i:i+len(substr)] == substr {
@@ -258,3 +353,504 @@ func containsStr(s, substr string) bool {
 	}
 	return false
 }
+
+func TestEngine_PreservesExistingManifestMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := dir + "/.waxseal/metadata"
+	_ = os.MkdirAll(metadataDir, 0o755)
+	_ = os.MkdirAll(dir+"/apps/test", 0o755)
+
+	metadata := `shortName: app
+manifestPath: apps/test/sealed-secret.yaml
+sealedSecret:
+  name: app
+  namespace: test
+  scope: namespace-wide
+  type: kubernetes.io/basic-auth
+status: active
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+`
+	_ = os.WriteFile(metadataDir+"/app.yaml", []byte(metadata), 0o644)
+
+	existing := `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: app
+  namespace: test
+  labels:
+    app.kubernetes.io/name: app
+  annotations:
+    argocd.argoproj.io/sync-wave: "-1"
+spec:
+  encryptedData:
+    password: OLD
+    removed: OLD
+  template:
+    metadata:
+      labels:
+        team: payments
+      annotations:
+        reloader.stakater.com/match: "true"
+    data:
+      config.yaml: |
+        user: {{ index . "password" }}
+`
+	_ = os.WriteFile(dir+"/apps/test/sealed-secret.yaml", []byte(existing), 0o644)
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("secret"))
+
+	engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+	if _, err := engine.ResealOne(ctx, "app"); err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(dir + "/apps/test/sealed-secret.yaml")
+	ss, err := seal.ParseSealedSecret(content)
+	if err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+
+	if ss.Metadata.Labels["app.kubernetes.io/name"] != "app" {
+		t.Error("labels should be preserved")
+	}
+	if ss.Metadata.Annotations["argocd.argoproj.io/sync-wave"] != "-1" {
+		t.Error("custom annotations should be preserved")
+	}
+	if ss.GetScope() != seal.ScopeNamespaceWide {
+		t.Errorf("scope = %q, want %q", ss.GetScope(), seal.ScopeNamespaceWide)
+	}
+	if ss.GetSecretType() != "kubernetes.io/basic-auth" {
+		t.Errorf("type = %q, want %q", ss.GetSecretType(), "kubernetes.io/basic-auth")
+	}
+	tmpl := ss.Spec.Template
+	if tmpl == nil || tmpl.Metadata == nil || tmpl.Metadata.Labels["team"] != "payments" ||
+		tmpl.Metadata.Annotations["reloader.stakater.com/match"] != "true" {
+		t.Errorf("template metadata should be preserved, got %+v", tmpl)
+	}
+	if tmpl == nil || !containsStr(tmpl.Data["config.yaml"], `index . "password"`) {
+		t.Error("template data should be preserved")
+	}
+
+	// encryptedData is replaced wholesale, not merged
+	if len(ss.Spec.EncryptedData) != 1 || ss.Spec.EncryptedData["password"] == "OLD" {
+		t.Errorf("encryptedData = %v, want only the resealed password", ss.Spec.EncryptedData)
+	}
+}
+
+func TestEngine_PreservesTemplateExactly(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := dir + "/.waxseal/metadata"
+	_ = os.MkdirAll(metadataDir, 0o755)
+	_ = os.MkdirAll(dir+"/apps/test", 0o755)
+
+	metadata := `shortName: app
+manifestPath: apps/test/sealed-secret.yaml
+sealedSecret:
+  name: app
+  namespace: test
+  scope: strict
+status: active
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+`
+	_ = os.WriteFile(metadataDir+"/app.yaml", []byte(metadata), 0o644)
+
+	// The template has only labels, plus fields waxseal does not model
+	template := `    immutable: true
+    metadata:
+      finalizers:
+      - example.com/cleanup
+      labels:
+        team: payments
+`
+	existing := `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: app
+  namespace: test
+  ownerReferences:
+  - apiVersion: v1
+    kind: ConfigMap
+    name: owner
+    uid: 1234
+spec:
+  encryptedData:
+    password: OLD
+  template:
+` + template
+	manifestPath := dir + "/apps/test/sealed-secret.yaml"
+	_ = os.WriteFile(manifestPath, []byte(existing), 0o644)
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("secret"))
+
+	engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+	if _, err := engine.ResealOne(ctx, "app"); err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(manifestPath)
+	if !strings.HasSuffix(string(content), "  template:\n"+template) {
+		t.Errorf("template not preserved exactly:\n%s", content)
+	}
+	if !strings.Contains(string(content), "ownerReferences:") {
+		t.Errorf("unmodelled metadata should be preserved:\n%s", content)
+	}
+	if strings.Contains(string(content), `name: ""`) || strings.Contains(string(content), `namespace: ""`) {
+		t.Errorf("empty name/namespace written into template:\n%s", content)
+	}
+	if strings.Contains(string(content), "OLD") {
+		t.Errorf("encryptedData should be resealed:\n%s", content)
+	}
+}
+
+func TestEngine_MultiDocumentManifest(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := dir + "/.waxseal/metadata"
+	_ = os.MkdirAll(metadataDir, 0o755)
+	_ = os.MkdirAll(dir+"/apps/test", 0o755)
+
+	metadata := `shortName: bundled
+manifestPath: apps/test/bundle.yaml
+sealedSecret:
+  name: bundled
+  namespace: test
+  scope: strict
+status: active
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+`
+	_ = os.WriteFile(metadataDir+"/bundled.yaml", []byte(metadata), 0o644)
+
+	head := `# App bundle
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bundled # inline comment
+---
+`
+	tail := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: bundled
+`
+	bundle := head + `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: bundled
+  namespace: test
+spec:
+  encryptedData:
+    password: OLD
+` + tail
+	manifestPath := dir + "/apps/test/bundle.yaml"
+	_ = os.WriteFile(manifestPath, []byte(bundle), 0o644)
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("secret"))
+
+	engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+	if _, err := engine.ResealOne(ctx, "bundled"); err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(manifestPath)
+	out := string(content)
+	if out[:len(head)] != head {
+		t.Errorf("documents before the SealedSecret changed:\n%s", out)
+	}
+	if out[len(out)-len(tail):] != tail {
+		t.Errorf("documents after the SealedSecret changed:\n%s", out)
+	}
+	if containsStr(out, "password: OLD") || !containsStr(out, "SEALED:test/bundled/password=secret") {
+		t.Errorf("SealedSecret document was not resealed:\n%s", out)
+	}
+}
+
+func TestEngine_KustomizeIdentity(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := dir + "/.waxseal/metadata"
+	_ = os.MkdirAll(metadataDir, 0o755)
+	_ = os.MkdirAll(dir+"/apps/billing", 0o755)
+
+	// Effective identity after kustomize: prod/billing-db
+	metadata := `shortName: prod-billing-db
+manifestPath: apps/billing/sealed-secret.yaml
+sealedSecret:
+  name: billing-db
+  namespace: prod
+  scope: strict
+status: active
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+`
+	_ = os.WriteFile(metadataDir+"/prod-billing-db.yaml", []byte(metadata), 0o644)
+	_ = os.WriteFile(dir+"/apps/billing/kustomization.yaml", []byte("namespace: prod\nnamePrefix: billing-\nresources:\n  - sealed-secret.yaml\n"), 0o644)
+	_ = os.WriteFile(dir+"/apps/billing/sealed-secret.yaml", []byte(`apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: db
+spec:
+  encryptedData:
+    password: OLD
+`), 0o644)
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("secret"))
+
+	engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+	if _, err := engine.ResealOne(ctx, "prod-billing-db"); err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(dir + "/apps/billing/sealed-secret.yaml")
+	found, err := seal.FindSealedSecrets(content)
+	if err != nil || len(found) != 1 {
+		t.Fatalf("FindSealedSecrets = %v, %v", found, err)
+	}
+	ss := found[0].SealedSecret
+
+	// The manifest keeps its pre-kustomize identity...
+	if ss.Metadata.Name != "db" || ss.Metadata.Namespace != "" {
+		t.Errorf("manifest identity = %q/%q, want \"\"/db", ss.Metadata.Namespace, ss.Metadata.Name)
+	}
+	// ...but is sealed for the effective one
+	if ss.Spec.EncryptedData["password"] != "SEALED:prod/billing-db/password=secret" {
+		t.Errorf("encryptedData = %v, want sealed for prod/billing-db", ss.Spec.EncryptedData)
+	}
+}
+
+func TestEngine_KustomizeOverlays(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := dir + "/.waxseal/metadata"
+	_ = os.MkdirAll(metadataDir, 0o755)
+	_ = os.MkdirAll(dir+"/app/base", 0o755)
+	_ = os.MkdirAll(dir+"/app/overlays/prod", 0o755)
+
+	metadata := `shortName: prod-db
+manifestPath: app/base/sealed-secret.yaml
+sealedSecret:
+  name: db
+  namespace: prod
+  scope: strict
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+`
+	_ = os.WriteFile(metadataDir+"/prod-db.yaml", []byte(metadata), 0o644)
+	_ = os.WriteFile(dir+"/app/base/kustomization.yaml", []byte("resources:\n  - sealed-secret.yaml\n"), 0o644)
+	_ = os.WriteFile(dir+"/app/overlays/prod/kustomization.yaml", []byte("namespace: prod\nresources:\n  - ../../base\n"), 0o644)
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("secret"))
+
+	engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+	if _, err := engine.ResealOne(ctx, "prod-db"); err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+	content, _ := os.ReadFile(dir + "/app/base/sealed-secret.yaml")
+	if !strings.Contains(string(content), "SEALED:prod/db/password=secret") {
+		t.Errorf("manifest should be sealed for the overlay identity:\n%s", content)
+	}
+
+	// A second overlay with another namespace makes the identity ambiguous
+	_ = os.MkdirAll(dir+"/app/overlays/staging", 0o755)
+	_ = os.WriteFile(dir+"/app/overlays/staging/kustomization.yaml", []byte("namespace: staging\nresources:\n  - ../../base\n"), 0o644)
+	engine = NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+	if _, err := engine.ResealOne(ctx, "prod-db"); !errors.Is(err, kustomize.ErrAmbiguous) {
+		t.Errorf("expected ErrAmbiguous, got %v", err)
+	}
+}
+
+func TestEngine_Environments(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := filepath.Join(dir, ".waxseal", "metadata")
+	_ = os.MkdirAll(metadataDir, 0o755)
+
+	write := func(name, extra, manifestPath string) {
+		metadata := fmt.Sprintf(`shortName: %[1]s
+manifestPath: %[3]s
+%[2]s
+sealedSecret:
+  name: %[1]s
+  namespace: test
+  scope: strict
+keys:
+  - keyName: value
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/%[1]s
+      version: "1"
+    rotation:
+      mode: static
+`, name, extra, manifestPath)
+		if err := os.WriteFile(filepath.Join(metadataDir, name+".yaml"), []byte(metadata), 0o644); err != nil {
+			t.Fatalf("write metadata: %v", err)
+		}
+	}
+	write("shared", "environments: [prod, staging]", "apps/shared/{env}/sealed-secret.yaml")
+	write("prod-only", "environments: [prod]", "apps/prod-only/sealed-secret.yaml")
+	write("plain", "", "apps/plain/sealed-secret.yaml")
+
+	fakeStore := store.NewFakeStore()
+	for _, name := range []string{"shared", "prod-only", "plain"} {
+		fakeStore.SetVersion("projects/test/secrets/"+name, "1", []byte("v"))
+	}
+
+	resealEnv := func(env string) []string {
+		engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+		engine.SetEnvironment(env)
+		results, err := engine.ResealAll(ctx)
+		if err != nil {
+			t.Fatalf("ResealAll(%q) failed: %v", env, err)
+		}
+		var names []string
+		for _, r := range results {
+			if r.Error != nil {
+				t.Errorf("%s [%s]: %v", r.ShortName, env, r.Error)
+			}
+			if r.Env != env {
+				t.Errorf("%s: Env = %q, want %q", r.ShortName, r.Env, env)
+			}
+			names = append(names, r.ShortName)
+		}
+		return names
+	}
+
+	if got := resealEnv(""); len(got) != 1 || got[0] != "plain" {
+		t.Errorf("top-level profile resealed %v, want [plain]", got)
+	}
+	if got := resealEnv("prod"); len(got) != 2 {
+		t.Errorf("prod resealed %v, want [prod-only shared]", got)
+	}
+	if got := resealEnv("staging"); len(got) != 1 || got[0] != "shared" {
+		t.Errorf("staging resealed %v, want [shared]", got)
+	}
+
+	for _, path := range []string{
+		"apps/shared/prod/sealed-secret.yaml",
+		"apps/shared/staging/sealed-secret.yaml",
+		"apps/prod-only/sealed-secret.yaml",
+		"apps/plain/sealed-secret.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("expected manifest %s: %v", path, err)
+		}
+	}
+
+	// Resealing a secret for an environment it does not target is an error
+	engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, false)
+	engine.SetEnvironment("staging")
+	if _, err := engine.ResealOne(ctx, "prod-only"); err == nil {
+		t.Error("expected error resealing prod-only for staging")
+	}
+}
+
+func TestEngine_DerivedExpiry(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	metadataDir := dir + "/.waxseal/metadata"
+	if err := os.MkdirAll(metadataDir, 0o755); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+	metadata := `shortName: webhook
+manifestPath: apps/test/sealed-secret.yaml
+sealedSecret:
+  name: webhook
+  namespace: test
+  scope: strict
+status: active
+keys:
+  - keyName: tls.crt
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/crt
+      version: "1"
+    expiry:
+      source: derived
+  - keyName: token
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/token
+      version: "1"
+    expiry:
+      source: derived
+`
+	if err := os.WriteFile(metadataDir+"/webhook.yaml", []byte(metadata), 0o644); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+
+	cert, err := core.Generate(&core.GeneratorConfig{Kind: "tls", CommonName: "webhook", Validity: "30d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/crt", "1", cert.Outputs["cert"])
+	fakeStore.SetVersion("projects/test/secrets/token", "1", []byte("not-a-jwt"))
+
+	engine := NewEngine(fakeStore, seal.NewFakeSealer(), dir, true)
+	result, err := engine.ResealOne(ctx, "webhook")
+	if err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+
+	if got := result.DerivedExpiry["tls.crt"]; !got.Equal(cert.NotAfter) {
+		t.Errorf("derived expiry = %v, want %v", got, cert.NotAfter)
+	}
+	if result.ExpiryErrors["token"] == nil {
+		t.Error("expected an expiry error for a value without expiry")
+	}
+}
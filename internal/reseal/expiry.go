@@ -0,0 +1,38 @@
+package reseal
+
+import (
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/logging"
+)
+
+// deriveExpiries reads the expiry of every key with expiry.source derived
+// from its resolved plaintext.
+func deriveExpiries(metadata *core.SecretMetadata, keyValues map[string]string) (map[string]time.Time, map[string]error) {
+	var expiries map[string]time.Time
+	var errs map[string]error
+	for _, key := range metadata.Keys {
+		if !key.Expiry.IsDerived() {
+			continue
+		}
+		value, ok := keyValues[key.KeyName]
+		if !ok {
+			continue
+		}
+		expiresAt, err := core.DeriveExpiry([]byte(value))
+		if err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[key.KeyName] = err
+			logging.Warn("cannot derive expiry", "shortName", metadata.ShortName, "key", key.KeyName, "error", err)
+			continue
+		}
+		if expiries == nil {
+			expiries = make(map[string]time.Time)
+		}
+		expiries[key.KeyName] = expiresAt
+	}
+	return expiries, errs
+}
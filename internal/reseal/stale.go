@@ -0,0 +1,77 @@
+package reseal
+
+import (
+	"fmt"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/seal"
+)
+
+// StaleSecret is a secret whose manifest was not sealed with the current
+// certificate.
+type StaleSecret struct {
+	ShortName    string
+	Env          string
+	ManifestPath string // as written in metadata, resolved for Env
+	SealedBy     string // recorded cert fingerprint; "" if none was recorded
+	Error        error  // the manifest could not be read
+}
+
+// SetStaleOnly restricts ResealAll to secrets whose manifest records a
+// sealing cert other than the sealer's (see FindStale). The sealer must
+// implement seal.Fingerprinter.
+func (e *Engine) SetStaleOnly(staleOnly bool) {
+	e.staleOnly = staleOnly
+}
+
+// FindStale returns the active secrets targeting the engine's environment
+// whose manifest records a sealing cert fingerprint other than fingerprint,
+// or none at all. Secrets whose manifest cannot be read are included with
+// Error set.
+func (e *Engine) FindStale(fingerprint string) ([]StaleSecret, error) {
+	allSecrets, loadErrs := files.LoadAllMetadataCollectErrors(e.repoDir)
+	if len(allSecrets) == 0 && len(loadErrs) > 0 {
+		return nil, loadErrs[0]
+	}
+
+	var stale []StaleSecret
+	for _, metadata := range allSecrets {
+		if metadata.IsRetired() || !metadata.TargetsEnv(e.env) {
+			continue
+		}
+		s, ok := e.staleness(metadata, fingerprint)
+		if ok {
+			continue
+		}
+		stale = append(stale, s)
+	}
+	return stale, nil
+}
+
+// staleness reports whether a secret's manifest was sealed with fingerprint,
+// and describes it if not.
+func (e *Engine) staleness(metadata *core.SecretMetadata, fingerprint string) (StaleSecret, bool) {
+	s := StaleSecret{
+		ShortName:    metadata.ShortName,
+		Env:          e.env,
+		ManifestPath: metadata.ManifestPathFor(e.env),
+	}
+	ss, err := e.readSealedSecret(metadata)
+	if err != nil {
+		s.Error = err
+		return s, false
+	}
+	s.SealedBy = ss.Metadata.Annotations[seal.AnnotationCertFingerprint]
+	return s, s.SealedBy == fingerprint
+}
+
+// currentFingerprint returns the fingerprint of the sealer's cert, for
+// stale-only reseals.
+func (e *Engine) currentFingerprint() (string, error) {
+	fp, ok := e.sealer.(seal.Fingerprinter)
+	if !ok || fp.GetCertFingerprint() == "" {
+		return "", fmt.Errorf("stale-only reseal needs the sealer's cert fingerprint")
+	}
+	return fp.GetCertFingerprint(), nil
+}
@@ -0,0 +1,89 @@
+package reseal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shermanhuman/waxseal/internal/seal"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+func TestEngine_StaleOnly(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeStableMetadata(t, dir, "1")
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("pw-1"))
+	fakeStore.SetVersion("projects/test/secrets/username", "1", []byte("admin"))
+
+	oldSealer := newTestCertSealer(t)
+	newSealer := newTestCertSealer(t)
+
+	// Without a digest key the sealing cert is still recorded
+	if _, err := NewEngine(fakeStore, oldSealer, dir, false).ResealAll(ctx); err != nil {
+		t.Fatalf("ResealAll failed: %v", err)
+	}
+	ss := readStableManifest(t, dir)
+	if got := ss.Metadata.Annotations[seal.AnnotationCertFingerprint]; got != oldSealer.GetCertFingerprint() {
+		t.Fatalf("cert fingerprint annotation = %q, want the old cert's", got)
+	}
+	if _, ok := ss.Metadata.Annotations[seal.AnnotationPlaintextDigests]; ok {
+		t.Error("digests should only be recorded with a digest key")
+	}
+
+	engine := NewEngine(fakeStore, newSealer, dir, false)
+	stale, err := engine.FindStale(newSealer.GetCertFingerprint())
+	if err != nil {
+		t.Fatalf("FindStale failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0].ShortName != "stable" || stale[0].SealedBy != oldSealer.GetCertFingerprint() {
+		t.Fatalf("FindStale = %+v, want stable sealed by the old cert", stale)
+	}
+
+	engine.SetStaleOnly(true)
+	results, err := engine.ResealAll(ctx)
+	if err != nil {
+		t.Fatalf("ResealAll failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Error != nil || results[0].KeysResealed != 2 {
+		t.Fatalf("results = %+v, want stable resealed", results)
+	}
+
+	// Now current: nothing is stale and a stale-only reseal skips it
+	if stale, _ := engine.FindStale(newSealer.GetCertFingerprint()); len(stale) != 0 {
+		t.Errorf("FindStale after reseal = %+v, want none", stale)
+	}
+	results, err = engine.ResealAll(ctx)
+	if err != nil || len(results) != 0 {
+		t.Errorf("stale-only ResealAll = %+v, %v; want no secrets", results, err)
+	}
+}
+
+func TestEngine_FindStale_NoFingerprintRecorded(t *testing.T) {
+	dir := t.TempDir()
+	writeStableMetadata(t, dir, "1")
+	manifestDir := filepath.Join(dir, "apps", "stable")
+	os.MkdirAll(manifestDir, 0o755)
+	manifest := "apiVersion: bitnami.com/v1alpha1\nkind: SealedSecret\nmetadata:\n  name: stable\n  namespace: test\nspec:\n  encryptedData:\n    password: AgB...\n"
+	if err := os.WriteFile(filepath.Join(manifestDir, "sealed-secret.yaml"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := NewEngine(nil, nil, dir, false).FindStale("abc")
+	if err != nil {
+		t.Fatalf("FindStale failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0].SealedBy != "" || stale[0].Error != nil {
+		t.Errorf("FindStale = %+v, want stable with no fingerprint", stale)
+	}
+
+	// A stale-only reseal needs the sealer's fingerprint
+	engine := NewEngine(store.NewFakeStore(), seal.NewFakeSealer(), dir, false)
+	engine.SetStaleOnly(true)
+	if _, err := engine.ResealAll(context.Background()); err == nil {
+		t.Error("expected error for a sealer without a cert fingerprint")
+	}
+}
@@ -0,0 +1,171 @@
+package reseal
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"sort"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/logging"
+	"github.com/shermanhuman/waxseal/internal/seal"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+// Key verification outcomes.
+const (
+	KeyMatch         = "match"         // decrypts to the store value
+	KeyMismatch      = "mismatch"      // decrypts, but to another value
+	KeyMissing       = "missing"       // in metadata, not in the manifest
+	KeyUndecryptable = "undecryptable" // no backup key decrypts it for this identity
+	KeyUnmanaged     = "unmanaged"     // in the manifest, not in metadata
+)
+
+// KeyVerification is the outcome of verifying one key.
+type KeyVerification struct {
+	KeyName string
+	Status  string
+}
+
+// VerifyResult is the outcome of verifying one secret's manifest.
+type VerifyResult struct {
+	ShortName string
+	Env       string // environment verified for ("" for the top-level profile)
+	Keys      []KeyVerification
+	Error     error
+}
+
+// OK reports whether every key decrypted to its store value.
+func (r *VerifyResult) OK() bool {
+	if r.Error != nil {
+		return false
+	}
+	for _, k := range r.Keys {
+		if k.Status != KeyMatch {
+			return false
+		}
+	}
+	return true
+}
+
+// Verifier checks committed manifests against the store offline: it
+// decrypts each encryptedData value with the controller's private keys and
+// compares its digest with the digest of the version pinned in metadata.
+// Plaintext is never returned or logged.
+type Verifier struct {
+	engine   *Engine
+	unsealer *seal.Unsealer
+}
+
+// NewVerifier creates a verifier for the secrets in repoDir.
+func NewVerifier(store store.Store, unsealer *seal.Unsealer, repoDir string) *Verifier {
+	return &Verifier{
+		engine:   NewEngine(store, nil, repoDir, false),
+		unsealer: unsealer,
+	}
+}
+
+// SetEnvironment selects the environment to verify, as Engine.SetEnvironment.
+// The unsealer must hold that environment's controller keys.
+func (v *Verifier) SetEnvironment(env string) {
+	v.engine.SetEnvironment(env)
+}
+
+// VerifyOne verifies a single secret by short name.
+func (v *Verifier) VerifyOne(ctx context.Context, shortName string) (*VerifyResult, error) {
+	metadata, err := files.LoadMetadata(v.engine.repoDir, shortName)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.IsRetired() {
+		return nil, fmt.Errorf("%s: %w", shortName, core.ErrRetired)
+	}
+	if !metadata.TargetsEnv(v.engine.env) {
+		return nil, core.NewValidationError("environment", v.engine.notTargetedMessage(metadata))
+	}
+	return v.verifyForResult(ctx, metadata), nil
+}
+
+// VerifyAll verifies every active secret that targets the environment.
+func (v *Verifier) VerifyAll(ctx context.Context) ([]*VerifyResult, error) {
+	allSecrets, loadErrs := files.LoadAllMetadataCollectErrors(v.engine.repoDir)
+	if len(allSecrets) == 0 && len(loadErrs) > 0 {
+		return nil, loadErrs[0]
+	}
+
+	var results []*VerifyResult
+	for _, err := range loadErrs {
+		results = append(results, &VerifyResult{Error: err})
+	}
+	for _, metadata := range allSecrets {
+		if metadata.IsRetired() || !metadata.TargetsEnv(v.engine.env) {
+			continue
+		}
+		results = append(results, v.verifyForResult(ctx, metadata))
+	}
+	return results, nil
+}
+
+func (v *Verifier) verifyForResult(ctx context.Context, metadata *core.SecretMetadata) *VerifyResult {
+	result, err := v.verify(ctx, metadata)
+	if err != nil {
+		return &VerifyResult{ShortName: metadata.ShortName, Env: v.engine.env, Error: err}
+	}
+	return result
+}
+
+func (v *Verifier) verify(ctx context.Context, metadata *core.SecretMetadata) (*VerifyResult, error) {
+	e := v.engine
+	ss, err := e.readSealedSecret(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := e.resolveValues(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// The controller unseals with the identity after kustomize, and the
+	// scope written in the manifest
+	name, namespace := metadata.SealedSecret.Name, metadata.SealedSecret.Namespace
+	scope := ss.GetScope()
+
+	result := &VerifyResult{ShortName: metadata.ShortName, Env: e.env}
+	managed := make(map[string]bool, len(metadata.Keys))
+	for _, key := range metadata.Keys {
+		managed[key.KeyName] = true
+		status := KeyMatch
+		if sealed, ok := ss.Spec.EncryptedData[key.KeyName]; !ok {
+			status = KeyMissing
+		} else if plaintext, err := v.unsealer.Unseal(name, namespace, scope, sealed); err != nil {
+			status = KeyUndecryptable
+		} else if !digestsEqual(plaintext, []byte(values[key.KeyName])) {
+			status = KeyMismatch
+		}
+		result.Keys = append(result.Keys, KeyVerification{KeyName: key.KeyName, Status: status})
+	}
+
+	// Keys sealed in the manifest that metadata does not know about
+	var unmanaged []string
+	for keyName := range ss.Spec.EncryptedData {
+		if !managed[keyName] {
+			unmanaged = append(unmanaged, keyName)
+		}
+	}
+	sort.Strings(unmanaged)
+	for _, keyName := range unmanaged {
+		result.Keys = append(result.Keys, KeyVerification{KeyName: keyName, Status: KeyUnmanaged})
+	}
+
+	logging.Info("verified secret", "shortName", metadata.ShortName, "ok", result.OK())
+	return result, nil
+}
+
+// digestsEqual compares two values by SHA-256 digest in constant time.
+func digestsEqual(a, b []byte) bool {
+	da, db := sha256.Sum256(a), sha256.Sum256(b)
+	return subtle.ConstantTimeCompare(da[:], db[:]) == 1
+}
@@ -0,0 +1,136 @@
+package reseal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/seal"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+// newTestKeyPair returns a CertSealer and the matching Unsealer.
+func newTestKeyPair(t *testing.T) (*seal.CertSealer, *seal.Unsealer) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	sealer, err := seal.NewCertSealerFromPEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsealer, err := seal.NewUnsealerFromPEM(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealer, unsealer
+}
+
+func TestVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	metadataDir := filepath.Join(dir, ".waxseal", "metadata")
+	_ = os.MkdirAll(metadataDir, 0o755)
+	metadata := `shortName: app
+manifestPath: apps/app/sealed-secret.yaml
+sealedSecret:
+  name: app
+  namespace: test
+  scope: strict
+keys:
+  - keyName: password
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/password
+      version: "1"
+    rotation:
+      mode: static
+  - keyName: token
+    source:
+      kind: gsm
+    gsm:
+      secretResource: projects/test/secrets/token
+      version: "1"
+    rotation:
+      mode: static
+`
+	_ = os.WriteFile(filepath.Join(metadataDir, "app.yaml"), []byte(metadata), 0o644)
+
+	fakeStore := store.NewFakeStore()
+	fakeStore.SetVersion("projects/test/secrets/password", "1", []byte("hunter2"))
+	fakeStore.SetVersion("projects/test/secrets/token", "1", []byte("abc"))
+
+	sealer, unsealer := newTestKeyPair(t)
+	if _, err := NewEngine(fakeStore, sealer, dir, false).ResealOne(ctx, "app"); err != nil {
+		t.Fatalf("ResealOne failed: %v", err)
+	}
+
+	verifier := NewVerifier(fakeStore, unsealer, dir)
+	result, err := verifier.VerifyOne(ctx, "app")
+	if err != nil {
+		t.Fatalf("VerifyOne failed: %v", err)
+	}
+	if !result.OK() {
+		t.Errorf("freshly sealed manifest should verify, got %+v", result.Keys)
+	}
+
+	// Drift: the manifest holds another value for password, drops token and
+	// carries a key metadata doesn't know
+	manifestPath := filepath.Join(dir, "apps", "app", "sealed-secret.yaml")
+	data, _ := os.ReadFile(manifestPath)
+	ss, err := seal.ParseSealedSecret(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, _ := sealer.Seal("app", "test", "password", []byte("old-value"), seal.ScopeStrict)
+	ss.Spec.EncryptedData["password"] = stale
+	delete(ss.Spec.EncryptedData, "token")
+	ss.Spec.EncryptedData["extra"] = stale
+	data, _ = ss.ToYAML()
+	_ = os.WriteFile(manifestPath, data, 0o644)
+
+	results, err := verifier.VerifyAll(ctx)
+	if err != nil {
+		t.Fatalf("VerifyAll failed: %v", err)
+	}
+	if len(results) != 1 || results[0].OK() {
+		t.Fatalf("drifted manifest should fail verification, got %+v", results)
+	}
+	want := map[string]string{"password": KeyMismatch, "token": KeyMissing, "extra": KeyUnmanaged}
+	for _, k := range results[0].Keys {
+		if want[k.KeyName] != k.Status {
+			t.Errorf("%s: status = %q, want %q", k.KeyName, k.Status, want[k.KeyName])
+		}
+	}
+
+	// A backup of another controller's key decrypts nothing
+	_, otherUnsealer := newTestKeyPair(t)
+	result, err = NewVerifier(fakeStore, otherUnsealer, dir).VerifyOne(ctx, "app")
+	if err != nil {
+		t.Fatalf("VerifyOne failed: %v", err)
+	}
+	if result.Keys[0].Status != KeyUndecryptable {
+		t.Errorf("status = %q, want %q", result.Keys[0].Status, KeyUndecryptable)
+	}
+}
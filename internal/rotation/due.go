@@ -0,0 +1,140 @@
+// Package rotation evaluates rotation policies: when each key with a
+// rotation.interval was last rotated and whether it is overdue.
+//
+// The last rotation is the creation time of the store version pinned in
+// metadata, for stores that record it (store.VersionTimer). Otherwise it is
+// the time of the audit log entry that created that version.
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+// Sources of a last-rotation time.
+const (
+	SourceStore = "store"
+	SourceAudit = "audit"
+)
+
+// Status is the rotation state of one key with a rotation interval.
+type Status struct {
+	ShortName   string
+	KeyName     string
+	Mode        string // rotation.mode
+	Interval    time.Duration
+	LastRotated time.Time // zero if unknown
+	Source      string    // SourceStore or SourceAudit; empty if unknown
+	Err         error     // why the last rotation is unknown
+}
+
+// Known reports whether the last rotation time is known.
+func (s *Status) Known() bool {
+	return !s.LastRotated.IsZero()
+}
+
+// DueAt returns when the key is next due for rotation.
+// Only meaningful when Known.
+func (s *Status) DueAt() time.Time {
+	return s.LastRotated.Add(s.Interval)
+}
+
+// Overdue reports whether the key is past its rotation interval at now.
+// A key whose last rotation is unknown is not reported overdue.
+func (s *Status) Overdue(now time.Time) bool {
+	return s.Known() && !now.Before(s.DueAt())
+}
+
+// DueWithin reports whether the key falls due before now+window.
+func (s *Status) DueWithin(now time.Time, window time.Duration) bool {
+	return s.Known() && now.Add(window).After(s.DueAt())
+}
+
+// Tracker finds the last rotation of keys.
+type Tracker struct {
+	store   store.Store // may be nil: audit log only
+	entries []audit.Entry
+}
+
+// NewTracker creates a tracker that asks st for version creation times and
+// falls back to the audit log entries. st may be nil.
+func NewTracker(st store.Store, entries []audit.Entry) *Tracker {
+	return &Tracker{store: st, entries: entries}
+}
+
+// Statuses returns the rotation status of every key of m that has a
+// rotation interval. Keys with an invalid interval are skipped; metadata
+// validation reports them.
+func (t *Tracker) Statuses(ctx context.Context, m *core.SecretMetadata) []Status {
+	var statuses []Status
+	for _, k := range m.Keys {
+		if k.Rotation == nil || k.Rotation.Interval == "" {
+			continue
+		}
+		interval, err := core.ParseInterval(k.Rotation.Interval)
+		if err != nil {
+			continue
+		}
+
+		s := Status{
+			ShortName: m.ShortName,
+			KeyName:   k.KeyName,
+			Mode:      k.Rotation.Mode,
+			Interval:  interval,
+		}
+		s.LastRotated, s.Source, s.Err = t.LastRotated(ctx, m.ShortName, k)
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// LastRotated returns when a key's pinned version was created, and where
+// that time came from.
+func (t *Tracker) LastRotated(ctx context.Context, shortName string, k core.KeyMetadata) (time.Time, string, error) {
+	ref := storeRef(k)
+
+	var storeErr error
+	if timer, ok := t.store.(store.VersionTimer); ok && ref != nil {
+		created, err := timer.VersionCreateTime(ctx, ref.SecretResource, ref.Version)
+		if err == nil {
+			return created, SourceStore, nil
+		}
+		storeErr = err
+	}
+
+	// Latest audit entry that wrote the pinned version (any version if the
+	// key has no store reference)
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		e := t.entries[i]
+		if e.ShortName != shortName || e.KeyName != k.KeyName || e.NewVersion == "" {
+			continue
+		}
+		if ref != nil && e.NewVersion != ref.Version {
+			continue
+		}
+		if at, err := time.Parse(time.RFC3339, e.Time); err == nil {
+			return at, SourceAudit, nil
+		}
+	}
+
+	if storeErr != nil {
+		return time.Time{}, "", fmt.Errorf("version creation time: %w", storeErr)
+	}
+	return time.Time{}, "", fmt.Errorf("no version creation time in the store or the audit log")
+}
+
+// storeRef returns the store reference of a key's value.
+func storeRef(k core.KeyMetadata) *core.StoreRef {
+	if k.GSM != nil {
+		return k.GSM
+	}
+	if k.Computed != nil && k.Computed.GSM != nil {
+		return k.Computed.GSM
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package rotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/audit"
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/store"
+)
+
+func testMetadata() *core.SecretMetadata {
+	return &core.SecretMetadata{
+		ShortName: "app",
+		Keys: []core.KeyMetadata{
+			{
+				KeyName:  "password",
+				Source:   core.SourceConfig{Kind: "gsm"},
+				GSM:      &core.GSMRef{SecretResource: "projects/p/secrets/app-password", Version: "2"},
+				Rotation: &core.RotationConfig{Mode: "generated", Interval: "90d"},
+			},
+			{
+				KeyName:  "username",
+				Source:   core.SourceConfig{Kind: "gsm"},
+				GSM:      &core.GSMRef{SecretResource: "projects/p/secrets/app-username", Version: "1"},
+				Rotation: &core.RotationConfig{Mode: "static"},
+			},
+		},
+	}
+}
+
+func TestTracker_FromStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	st := store.NewFakeStore()
+	st.SetVersion("projects/p/secrets/app-password", "2", []byte("x"))
+	st.SetVersionCreateTime("projects/p/secrets/app-password", "2", now.Add(-100*24*time.Hour))
+
+	statuses := NewTracker(st, nil).Statuses(ctx, testMetadata())
+	if len(statuses) != 1 {
+		t.Fatalf("got %d statuses, want 1 (only keys with an interval)", len(statuses))
+	}
+	s := statuses[0]
+	if s.KeyName != "password" || s.Source != SourceStore || s.Interval != 90*24*time.Hour {
+		t.Errorf("unexpected status: %+v", s)
+	}
+	if !s.Overdue(now) {
+		t.Error("key rotated 100 days ago with a 90d interval should be overdue")
+	}
+
+	st.SetVersionCreateTime("projects/p/secrets/app-password", "2", now.Add(-80*24*time.Hour))
+	s = NewTracker(st, nil).Statuses(ctx, testMetadata())[0]
+	if s.Overdue(now) {
+		t.Error("key rotated 80 days ago should not be overdue")
+	}
+	if !s.DueWithin(now, 30*24*time.Hour) {
+		t.Error("key due in 10 days should be due within 30 days")
+	}
+}
+
+func TestTracker_FallsBackToAudit(t *testing.T) {
+	ctx := context.Background()
+	rotatedAt := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	entries := []audit.Entry{
+		{ShortName: "app", KeyName: "password", NewVersion: "1", Time: "2025-06-01T00:00:00Z"},
+		{ShortName: "app", KeyName: "password", NewVersion: "2", Time: rotatedAt.Format(time.RFC3339)},
+		{ShortName: "other", KeyName: "password", NewVersion: "2", Time: "2026-03-01T00:00:00Z"},
+	}
+
+	// The store has no record of the secret
+	s := NewTracker(store.NewFakeStore(), entries).Statuses(ctx, testMetadata())[0]
+	if s.Source != SourceAudit || !s.LastRotated.Equal(rotatedAt) {
+		t.Errorf("LastRotated = %v from %q, want %v from audit", s.LastRotated, s.Source, rotatedAt)
+	}
+}
+
+func TestTracker_Unknown(t *testing.T) {
+	s := NewTracker(nil, nil).Statuses(context.Background(), testMetadata())[0]
+	if s.Known() || s.Err == nil {
+		t.Errorf("expected unknown last rotation with an error, got %+v", s)
+	}
+	if s.Overdue(time.Now()) {
+		t.Error("unknown last rotation should not be reported overdue")
+	}
+}
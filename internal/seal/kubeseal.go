@@ -58,10 +58,18 @@ func (s *KubesealSealer) Seal(name, namespace, key string, value []byte, scope s
 	return strings.TrimSpace(stdout.String()), nil
 }
 
-// GetCertFingerprint returns a placeholder since we don't parse the cert.
+// GetCertFingerprint returns the SHA256 fingerprint of the certificate file
+// kubeseal is pointed at, or "" if it cannot be read.
 func (s *KubesealSealer) GetCertFingerprint() string {
-	return "kubeseal-binary"
+	certSealer, err := NewCertSealerFromFile(s.certPath)
+	if err != nil {
+		return ""
+	}
+	return certSealer.GetCertFingerprint()
 }
 
-// Compile-time check
-var _ Sealer = (*KubesealSealer)(nil)
+// Compile-time checks
+var (
+	_ Sealer        = (*KubesealSealer)(nil)
+	_ Fingerprinter = (*KubesealSealer)(nil)
+)
@@ -3,6 +3,7 @@ package seal
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -49,9 +50,19 @@ func TestKubesealSealer_ScopeMapping(t *testing.T) {
 // TestKubesealSealer_GetCertFingerprint tests the fingerprint method.
 func TestKubesealSealer_GetCertFingerprint(t *testing.T) {
 	sealer := NewKubesealSealer("/path/to/cert.pem")
-	fingerprint := sealer.GetCertFingerprint()
-	if fingerprint != "kubeseal-binary" {
-		t.Errorf("expected 'kubeseal-binary', got %q", fingerprint)
+	if fingerprint := sealer.GetCertFingerprint(); fingerprint != "" {
+		t.Errorf("expected empty fingerprint for missing cert, got %q", fingerprint)
+	}
+
+	// Matches the native sealer for the same certificate
+	certPath := filepath.Join(t.TempDir(), "cert.pem")
+	pemData := generateTestCertPEM(t)
+	os.WriteFile(certPath, pemData, 0o644)
+	certSealer, _ := NewCertSealerFromPEM(pemData)
+
+	sealer = NewKubesealSealer(certPath)
+	if got, want := sealer.GetCertFingerprint(), certSealer.GetCertFingerprint(); got != want {
+		t.Errorf("fingerprint = %q, want %q", got, want)
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/files"
 	"sigs.k8s.io/yaml"
 )
 
@@ -13,12 +14,16 @@ type SealedSecret struct {
 	Kind       string           `json:"kind"`
 	Metadata   ObjectMeta       `json:"metadata"`
 	Spec       SealedSecretSpec `json:"spec"`
+
+	// raw is the parsed document, so that ToYAML keeps fields the struct
+	// does not model (ownerReferences, spec.template finalizers, ...).
+	raw map[string]any
 }
 
 // ObjectMeta contains standard Kubernetes metadata.
 type ObjectMeta struct {
-	Name        string            `json:"name"`
-	Namespace   string            `json:"namespace"`
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 	Labels      map[string]string `json:"labels,omitempty"`
 }
@@ -31,9 +36,10 @@ type SealedSecretSpec struct {
 
 // SecretTemplateSpec mirrors the target Secret structure.
 type SecretTemplateSpec struct {
-	Type     string            `json:"type,omitempty"`
-	Metadata *ObjectMeta       `json:"metadata,omitempty"`
-	Data     map[string]string `json:"data,omitempty"`
+	Type      string            `json:"type,omitempty"`
+	Immutable *bool             `json:"immutable,omitempty"`
+	Metadata  *ObjectMeta       `json:"metadata,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
 }
 
 // Scope constants for SealedSecrets.
@@ -50,6 +56,17 @@ const (
 	AnnotationName      = "sealedsecrets.bitnami.com/name"
 )
 
+// Annotation keys written by waxseal to keep ciphertext stable across reseals.
+const (
+	// AnnotationCertFingerprint is the SHA256 fingerprint of the cert that
+	// sealed the current encryptedData.
+	AnnotationCertFingerprint = "waxseal.io/cert-fingerprint"
+
+	// AnnotationPlaintextDigests is a JSON object of key name to keyed
+	// plaintext digest, used to detect unchanged values without decrypting.
+	AnnotationPlaintextDigests = "waxseal.io/plaintext-digests"
+)
+
 // NewSealedSecret constructs a SealedSecret with the correct annotations.
 // This is the single authoritative builder — all manifest creation goes through here.
 func NewSealedSecret(name, namespace, scope, secretType string, encryptedData map[string]string) *SealedSecret {
@@ -65,25 +82,64 @@ func NewSealedSecret(name, namespace, scope, secretType string, encryptedData ma
 		},
 	}
 
-	// Add scope annotation if not strict (strict is the default)
-	if scope != ScopeStrict && scope != "" {
-		ss.Metadata.Annotations = map[string]string{
-			AnnotationScope: scope,
+	ss.SetScope(scope)
+	ss.SetSecretType(secretType)
+
+	return ss
+}
+
+// SetScope sets or clears the scope annotation. Strict is the default and is
+// represented by the absence of the annotation.
+func (ss *SealedSecret) SetScope(scope string) {
+	if scope == ScopeStrict || scope == "" {
+		delete(ss.Metadata.Annotations, AnnotationScope)
+		if len(ss.Metadata.Annotations) == 0 {
+			ss.Metadata.Annotations = nil
 		}
+		return
+	}
+	if ss.Metadata.Annotations == nil {
+		ss.Metadata.Annotations = make(map[string]string)
 	}
+	ss.Metadata.Annotations[AnnotationScope] = scope
+}
 
-	// Add template with type if not Opaque
-	if secretType != "" && secretType != "Opaque" {
-		ss.Spec.Template = &SecretTemplateSpec{
-			Type: secretType,
+// SetSecretType sets the target Secret type. Opaque is the default and is
+// represented by an empty template type; the template is dropped entirely
+// only if nothing else (metadata or data) is left in it.
+func (ss *SealedSecret) SetSecretType(secretType string) {
+	if secretType == "Opaque" {
+		secretType = ""
+	}
+	if ss.Spec.Template == nil {
+		if secretType == "" {
+			return
 		}
+		ss.Spec.Template = &SecretTemplateSpec{}
+	}
+	ss.Spec.Template.Type = secretType
+	if t := ss.Spec.Template; t.Type == "" && t.Immutable == nil && t.Metadata == nil && len(t.Data) == 0 {
+		ss.Spec.Template = nil
 	}
-
-	return ss
 }
 
 // ParseSealedSecret parses a SealedSecret from YAML bytes.
 func ParseSealedSecret(data []byte) (*SealedSecret, error) {
+	ss, err := parseSealedSecretDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if ss.Metadata.Namespace == "" {
+		return nil, core.NewValidationError("metadata.namespace", "required")
+	}
+
+	return ss, nil
+}
+
+// parseSealedSecretDocument parses a SealedSecret that may omit its
+// namespace, as base manifests do when a kustomization sets it.
+func parseSealedSecretDocument(data []byte) (*SealedSecret, error) {
 	var ss SealedSecret
 	if err := yaml.Unmarshal(data, &ss); err != nil {
 		return nil, core.WrapValidation("sealedsecret", err)
@@ -97,13 +153,61 @@ func ParseSealedSecret(data []byte) (*SealedSecret, error) {
 		return nil, core.NewValidationError("metadata.name", "required")
 	}
 
-	if ss.Metadata.Namespace == "" {
-		return nil, core.NewValidationError("metadata.namespace", "required")
+	if err := yaml.Unmarshal(data, &ss.raw); err != nil {
+		return nil, core.WrapValidation("sealedsecret", err)
 	}
 
 	return &ss, nil
 }
 
+// LocatedSealedSecret is a SealedSecret found inside a YAML file, with the
+// location of its document so it can be replaced in place.
+type LocatedSealedSecret struct {
+	SealedSecret *SealedSecret
+	Document     files.YAMLDocument
+}
+
+// FindSealedSecrets parses every SealedSecret document in a YAML file that
+// may contain several "---"-separated documents. Documents of other kinds
+// are skipped; a SealedSecret document that fails to parse is an error.
+// The namespace may be empty, since a kustomization can supply it.
+func FindSealedSecrets(data []byte) ([]LocatedSealedSecret, error) {
+	var found []LocatedSealedSecret
+	for _, doc := range files.SplitYAMLDocuments(data) {
+		if doc.Kind != "SealedSecret" {
+			continue
+		}
+		ss, err := parseSealedSecretDocument(doc.Content(data))
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", doc.Index+1, err)
+		}
+		found = append(found, LocatedSealedSecret{SealedSecret: ss, Document: doc})
+	}
+	return found, nil
+}
+
+// FindSealedSecret returns the SealedSecret named namespace/name in a YAML
+// file; an empty namespace matches any. If no document matches by name but
+// the file is a single SealedSecret document, that one is returned (the
+// secret was renamed). Returns nil if the file contains no matching
+// SealedSecret.
+func FindSealedSecret(data []byte, namespace, name string) (*LocatedSealedSecret, error) {
+	found, err := FindSealedSecrets(data)
+	if err != nil {
+		return nil, err
+	}
+	for i := range found {
+		meta := found[i].SealedSecret.Metadata
+		if (namespace == "" || meta.Namespace == namespace) && meta.Name == name {
+			return &found[i], nil
+		}
+	}
+	if len(found) == 1 && len(files.SplitYAMLDocuments(data)) == 1 {
+		return &found[0], nil
+	}
+	return nil, nil
+}
+
 // GetScope returns the scope of the SealedSecret based on annotations.
 // Returns "strict" if no scope annotation is present.
 func (ss *SealedSecret) GetScope() string {
@@ -139,6 +243,73 @@ func (ss *SealedSecret) GetSecretType() string {
 }
 
 // ToYAML serializes the SealedSecret to YAML.
+// A parsed manifest keeps every field the struct does not model.
 func (ss *SealedSecret) ToYAML() ([]byte, error) {
-	return yaml.Marshal(ss)
+	if ss.raw == nil {
+		return yaml.Marshal(ss)
+	}
+
+	// Round-trip the struct to a generic map and lay it over the parsed
+	// document: modelled fields come from the struct, everything else is
+	// kept as parsed.
+	typedData, err := yaml.Marshal(ss)
+	if err != nil {
+		return nil, err
+	}
+	var typed map[string]any
+	if err := yaml.Unmarshal(typedData, &typed); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(mergeModelled(ss.raw, typed, sealedSecretFields))
+}
+
+// modelledFields lists the fields of an object that the SealedSecret struct
+// models. A nil entry is replaced wholesale; a nested set is merged field by
+// field.
+type modelledFields map[string]modelledFields
+
+var objectMetaFields = modelledFields{
+	"name":        nil,
+	"namespace":   nil,
+	"annotations": nil,
+	"labels":      nil,
+}
+
+var sealedSecretFields = modelledFields{
+	"apiVersion": nil,
+	"kind":       nil,
+	"metadata":   objectMetaFields,
+	"spec": {
+		"encryptedData": nil,
+		"template": {
+			"type":      nil,
+			"immutable": nil,
+			"metadata":  objectMetaFields,
+			"data":      nil,
+		},
+	},
+}
+
+// mergeModelled returns raw with its modelled fields taken from typed.
+// A modelled field missing from typed was cleared and is removed.
+func mergeModelled(raw, typed map[string]any, fields modelledFields) map[string]any {
+	merged := make(map[string]any, len(raw))
+	for k, v := range raw {
+		merged[k] = v
+	}
+	for key, nested := range fields {
+		value, ok := typed[key]
+		if !ok {
+			delete(merged, key)
+			continue
+		}
+		rawMap, rawIsMap := raw[key].(map[string]any)
+		typedMap, typedIsMap := value.(map[string]any)
+		if nested != nil && rawIsMap && typedIsMap {
+			merged[key] = mergeModelled(rawMap, typedMap, nested)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
 }
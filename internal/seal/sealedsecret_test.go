@@ -2,6 +2,7 @@ package seal
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/shermanhuman/waxseal/internal/core"
@@ -228,3 +229,155 @@ spec:
 		t.Errorf("type = %q, want %q", ss.GetSecretType(), "kubernetes.io/dockerconfigjson")
 	}
 }
+
+func TestSealedSecret_ToYAMLKeepsUnmodelledFields(t *testing.T) {
+	yaml := `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  annotations:
+    sealedsecrets.bitnami.com/scope: namespace-wide
+  name: app
+  namespace: default
+spec:
+  encryptedData:
+    password: OLD
+  template:
+    immutable: true
+    metadata:
+      finalizers:
+      - example.com/cleanup
+      labels:
+        team: payments
+`
+	ss, err := ParseSealedSecret([]byte(yaml))
+	if err != nil {
+		t.Fatalf("ParseSealedSecret failed: %v", err)
+	}
+	if ss.Spec.Template.Immutable == nil || !*ss.Spec.Template.Immutable {
+		t.Error("template.immutable should be parsed")
+	}
+
+	ss.SetScope(ScopeStrict)
+	ss.Spec.EncryptedData = map[string]string{"password": "NEW"}
+	out, err := ss.ToYAML()
+	if err != nil {
+		t.Fatalf("ToYAML failed: %v", err)
+	}
+
+	want := strings.Replace(strings.Replace(yaml,
+		"  annotations:\n    sealedsecrets.bitnami.com/scope: namespace-wide\n", "", 1),
+		"password: OLD", "password: NEW", 1)
+	if string(out) != want {
+		t.Errorf("ToYAML =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestSealedSecret_SetScope(t *testing.T) {
+	ss := NewSealedSecret("s", "ns", ScopeClusterWide, "", nil)
+	ss.Metadata.Annotations["custom"] = "keep"
+
+	ss.SetScope(ScopeNamespaceWide)
+	if ss.GetScope() != ScopeNamespaceWide {
+		t.Errorf("scope = %q, want %q", ss.GetScope(), ScopeNamespaceWide)
+	}
+
+	ss.SetScope(ScopeStrict)
+	if _, ok := ss.Metadata.Annotations[AnnotationScope]; ok {
+		t.Error("strict scope should remove the annotation")
+	}
+	if ss.Metadata.Annotations["custom"] != "keep" {
+		t.Error("other annotations should be kept")
+	}
+}
+
+func TestSealedSecret_SetSecretType(t *testing.T) {
+	ss := NewSealedSecret("s", "ns", ScopeStrict, "kubernetes.io/tls", nil)
+	if ss.GetSecretType() != "kubernetes.io/tls" {
+		t.Fatalf("type = %q, want kubernetes.io/tls", ss.GetSecretType())
+	}
+
+	// Opaque drops an otherwise empty template
+	ss.SetSecretType("Opaque")
+	if ss.Spec.Template != nil {
+		t.Errorf("template = %+v, want nil", ss.Spec.Template)
+	}
+
+	// Template data is kept when the type is reset
+	ss.Spec.Template = &SecretTemplateSpec{Type: "kubernetes.io/tls", Data: map[string]string{"a": "b"}}
+	ss.SetSecretType("Opaque")
+	if ss.Spec.Template == nil || ss.Spec.Template.Data["a"] != "b" || ss.Spec.Template.Type != "" {
+		t.Errorf("template = %+v, want data kept and type cleared", ss.Spec.Template)
+	}
+}
+
+func TestFindSealedSecrets_MultiDocument(t *testing.T) {
+	data := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: app-db
+  namespace: prod
+spec:
+  encryptedData:
+    password: AgB...
+---
+apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: app-api
+  namespace: prod
+spec:
+  encryptedData:
+    token: AgC...
+`)
+
+	found, err := FindSealedSecrets(data)
+	if err != nil {
+		t.Fatalf("FindSealedSecrets failed: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("found %d SealedSecrets, want 2", len(found))
+	}
+	if found[0].SealedSecret.Metadata.Name != "app-db" || found[0].Document.Index != 1 {
+		t.Errorf("found[0] = %s at %d", found[0].SealedSecret.Metadata.Name, found[0].Document.Index)
+	}
+
+	located, err := FindSealedSecret(data, "prod", "app-api")
+	if err != nil || located == nil {
+		t.Fatalf("FindSealedSecret = %v, %v", located, err)
+	}
+	if located.Document.Index != 2 {
+		t.Errorf("index = %d, want 2", located.Document.Index)
+	}
+
+	// No fallback to another secret's document in a bundle
+	if located, _ := FindSealedSecret(data, "prod", "missing"); located != nil {
+		t.Errorf("expected nil, got %s", located.SealedSecret.Metadata.Name)
+	}
+}
+
+func TestFindSealedSecret_SingleDocumentRename(t *testing.T) {
+	data := []byte("apiVersion: bitnami.com/v1alpha1\nkind: SealedSecret\nmetadata:\n  name: old\n  namespace: ns\n")
+	located, err := FindSealedSecret(data, "ns", "new")
+	if err != nil || located == nil {
+		t.Fatalf("FindSealedSecret = %v, %v; want the single document", located, err)
+	}
+}
+
+func TestFindSealedSecrets_InvalidDocument(t *testing.T) {
+	data := []byte("kind: Deployment\n---\nkind: SealedSecret\nmetadata:\n  namespace: no-name\n")
+	if _, err := FindSealedSecrets(data); err == nil {
+		t.Error("expected error for invalid SealedSecret document")
+	}
+
+	// A missing namespace is allowed; a kustomization may set it
+	data = []byte("kind: SealedSecret\nmetadata:\n  name: base\n")
+	found, err := FindSealedSecrets(data)
+	if err != nil || len(found) != 1 {
+		t.Errorf("FindSealedSecrets = %v, %v; want the namespace-less document", found, err)
+	}
+}
@@ -1,7 +1,6 @@
 package seal
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -9,6 +8,7 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/pem"
 	"fmt"
 	"io"
@@ -25,6 +25,12 @@ type Sealer interface {
 	Seal(name, namespace, key string, value []byte, scope string) (string, error)
 }
 
+// Fingerprinter is implemented by sealers that can identify the certificate
+// they seal with. An empty fingerprint means the certificate is unknown.
+type Fingerprinter interface {
+	GetCertFingerprint() string
+}
+
 // CertSealer seals secrets using a PEM-encoded certificate.
 type CertSealer struct {
 	cert *x509.Certificate
@@ -64,26 +70,11 @@ func NewCertSealerFromPEM(pemData []byte) (*CertSealer, error) {
 }
 
 // Seal encrypts a value for the given secret metadata.
-// The scope affects how the label is constructed for encryption.
+// The output is byte-compatible with the sealed-secrets controller and with
+// "kubeseal --raw": the scope selects the encryption label that binds the
+// ciphertext to the secret's identity.
 func (s *CertSealer) Seal(name, namespace, key string, value []byte, scope string) (string, error) {
-	// Construct the label based on scope
-	// This label is used in the encryption process to bind the ciphertext
-	// to specific metadata, preventing reuse across different contexts
-	var label []byte
-	switch scope {
-	case ScopeStrict:
-		// Bound to namespace/name/key
-		label = []byte(fmt.Sprintf("%s/%s/%s", namespace, name, key))
-	case ScopeNamespaceWide:
-		// Bound to namespace only
-		label = []byte(namespace)
-	case ScopeClusterWide:
-		// No binding
-		label = []byte{}
-	default:
-		// Default to strict
-		label = []byte(fmt.Sprintf("%s/%s/%s", namespace, name, key))
-	}
+	label := EncryptionLabel(namespace, name, scope)
 
 	// Use hybrid encryption (RSA-OAEP + AES-GCM)
 	encrypted, err := hybridEncrypt(s.cert, value, label)
@@ -94,6 +85,21 @@ func (s *CertSealer) Seal(name, namespace, key string, value []byte, scope strin
 	return encrypted, nil
 }
 
+// EncryptionLabel returns the OAEP label the controller expects for a scope.
+// Strict binds to namespace/name, namespace-wide to the namespace, and
+// cluster-wide uses an empty label. The key name is never part of the label.
+func EncryptionLabel(namespace, name, scope string) []byte {
+	switch scope {
+	case ScopeClusterWide:
+		return []byte{}
+	case ScopeNamespaceWide:
+		return []byte(namespace)
+	default:
+		// Strict (and unknown scopes, matching the controller)
+		return []byte(namespace + "/" + name)
+	}
+}
+
 // GetCertFingerprint returns the SHA256 fingerprint of the certificate.
 func (s *CertSealer) GetCertFingerprint() string {
 	return fmt.Sprintf("%x", sha256Sum(s.cert.Raw))
@@ -156,32 +162,34 @@ func (s *FakeSealer) Seal(name, namespace, key string, value []byte, scope strin
 
 // Compile-time interface checks
 var (
-	_ Sealer = (*CertSealer)(nil)
-	_ Sealer = (*FakeSealer)(nil)
+	_ Sealer        = (*CertSealer)(nil)
+	_ Fingerprinter = (*CertSealer)(nil)
+	_ Sealer        = (*FakeSealer)(nil)
 )
 
-// hybridEncrypt performs RSA-OAEP + AES-GCM hybrid encryption.
+// sessionKeyBytes is the AES-256 session key length used by the controller.
+const sessionKeyBytes = 32
+
+// hybridEncrypt performs RSA-OAEP + AES-GCM hybrid encryption in the
+// controller's wire format:
+//
+//	uint16(len(rsaCiphertext)) || rsaCiphertext || aesGCM(plaintext)
+//
+// The session key is used exactly once, so the controller uses an all-zero
+// GCM nonce and does not store it in the output.
 func hybridEncrypt(cert *x509.Certificate, plaintext, label []byte) (string, error) {
 	pubKey, ok := cert.PublicKey.(*rsa.PublicKey)
 	if !ok {
 		return "", fmt.Errorf("certificate does not contain RSA public key")
 	}
 
-	// Generate a random AES key
-	aesKey := make([]byte, 32) // AES-256
-	if _, err := io.ReadFull(rand.Reader, aesKey); err != nil {
+	// Generate a random AES session key
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rand.Reader, sessionKey); err != nil {
 		return "", fmt.Errorf("generate AES key: %w", err)
 	}
 
-	// Encrypt the AES key with RSA-OAEP
-	hash := sha256.New()
-	encryptedKey, err := rsa.EncryptOAEP(hash, rand.Reader, pubKey, aesKey, label)
-	if err != nil {
-		return "", fmt.Errorf("RSA encrypt: %w", err)
-	}
-
-	// Encrypt the plaintext with AES-GCM
-	block, err := aes.NewCipher(aesKey)
+	block, err := aes.NewCipher(sessionKey)
 	if err != nil {
 		return "", fmt.Errorf("create AES cipher: %w", err)
 	}
@@ -191,22 +199,53 @@ func hybridEncrypt(cert *x509.Certificate, plaintext, label []byte) (string, err
 		return "", fmt.Errorf("create GCM: %w", err)
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", fmt.Errorf("generate nonce: %w", err)
+	// Encrypt the session key with RSA-OAEP
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pubKey, sessionKey, label)
+	if err != nil {
+		return "", fmt.Errorf("RSA encrypt: %w", err)
+	}
+
+	result := make([]byte, 2, 2+len(encryptedKey)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(result, uint16(len(encryptedKey)))
+	result = append(result, encryptedKey...)
+
+	zeroNonce := make([]byte, gcm.NonceSize())
+	result = gcm.Seal(result, zeroNonce, plaintext, nil)
+
+	return base64.StdEncoding.EncodeToString(result), nil
+}
+
+// hybridDecrypt reverses hybridEncrypt with the controller's private key,
+// as the controller's crypto.HybridDecrypt does.
+func hybridDecrypt(key *rsa.PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	if len(ciphertext) < rsaLen+2 {
+		return nil, fmt.Errorf("ciphertext too short")
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:rsaLen+2], label)
+	if err != nil {
+		return nil, fmt.Errorf("RSA decrypt: %w", err)
+	}
 
-	// Combine: len(encryptedKey) || encryptedKey || ciphertext
-	var result bytes.Buffer
-	keyLen := uint16(len(encryptedKey))
-	result.WriteByte(byte(keyLen >> 8))
-	result.WriteByte(byte(keyLen))
-	result.Write(encryptedKey)
-	result.Write(ciphertext)
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("create AES cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create GCM: %w", err)
+	}
 
-	return base64.StdEncoding.EncodeToString(result.Bytes()), nil
+	zeroNonce := make([]byte, gcm.NonceSize())
+	plaintext, err := gcm.Open(nil, zeroNonce, ciphertext[rsaLen+2:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("AES decrypt: %w", err)
+	}
+	return plaintext, nil
 }
 
 func sha256Sum(data []byte) []byte {
@@ -1,11 +1,17 @@
 package seal
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/pem"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -61,7 +67,7 @@ func TestCertSealer_Seal(t *testing.T) {
 		t.Error("encrypted value should not be empty")
 	}
 
-	// Each call should produce different ciphertext (due to random nonce)
+	// Each call should produce different ciphertext (due to random session key)
 	encrypted2, _ := sealer.Seal("my-secret", "default", "password", []byte("secret-value"), ScopeStrict)
 	if encrypted == encrypted2 {
 		t.Error("repeated sealing should produce different ciphertext")
@@ -116,9 +122,126 @@ func TestFakeSealer(t *testing.T) {
 	}
 }
 
+// TestCertSealer_ControllerRoundTrip decrypts CertSealer output with the
+// controller's algorithm, under the label the controller derives per scope.
+func TestCertSealer_ControllerRoundTrip(t *testing.T) {
+	privateKey, pemData := generateTestKeyAndCertPEM(t)
+	sealer, _ := NewCertSealerFromPEM(pemData)
+
+	tests := []struct {
+		scope string
+		label string
+	}{
+		{ScopeStrict, "ns/my-secret"},
+		{ScopeNamespaceWide, "ns"},
+		{ScopeClusterWide, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			sealed, err := sealer.Seal("my-secret", "ns", "password", []byte("hunter2"), tt.scope)
+			if err != nil {
+				t.Fatalf("Seal failed: %v", err)
+			}
+			ciphertext, err := base64.StdEncoding.DecodeString(sealed)
+			if err != nil {
+				t.Fatalf("output is not standard base64: %v", err)
+			}
+
+			plaintext, err := controllerHybridDecrypt(privateKey, ciphertext, []byte(tt.label))
+			if err != nil {
+				t.Fatalf("controller decrypt failed: %v", err)
+			}
+			if string(plaintext) != "hunter2" {
+				t.Errorf("plaintext = %q, want %q", plaintext, "hunter2")
+			}
+
+			// A different identity must not decrypt
+			if _, err := controllerHybridDecrypt(privateKey, ciphertext, []byte("other/label")); err == nil {
+				t.Error("decrypt with wrong label should fail")
+			}
+		})
+	}
+}
+
+// TestCertSealer_WireFormat pins the byte layout: 2-byte length, RSA block,
+// then AES-GCM output with no nonce prefix.
+func TestCertSealer_WireFormat(t *testing.T) {
+	_, pemData := generateTestKeyAndCertPEM(t)
+	sealer, _ := NewCertSealerFromPEM(pemData)
+
+	plaintext := []byte("0123456789")
+	sealed, _ := sealer.Seal("s", "ns", "k", plaintext, ScopeStrict)
+	ciphertext, _ := base64.StdEncoding.DecodeString(sealed)
+
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	if rsaLen != 256 {
+		t.Errorf("RSA ciphertext length = %d, want 256 for a 2048-bit key", rsaLen)
+	}
+	if want := 2 + rsaLen + len(plaintext) + 16; len(ciphertext) != want {
+		t.Errorf("total length = %d, want %d", len(ciphertext), want)
+	}
+}
+
+func TestEncryptionLabel(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  string
+	}{
+		{ScopeStrict, "ns/name"},
+		{ScopeNamespaceWide, "ns"},
+		{ScopeClusterWide, ""},
+		{"", "ns/name"},
+	}
+	for _, tt := range tests {
+		if got := string(EncryptionLabel("ns", "name", tt.scope)); got != tt.want {
+			t.Errorf("EncryptionLabel(%q) = %q, want %q", tt.scope, got, tt.want)
+		}
+	}
+}
+
+// controllerHybridDecrypt mirrors the sealed-secrets controller's
+// crypto.HybridDecrypt for a single private key.
+func controllerHybridDecrypt(privKey *rsa.PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, errors.New("ciphertext too short")
+	}
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	if len(ciphertext) < rsaLen+2 {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	rsaCiphertext := ciphertext[2 : rsaLen+2]
+	aesCiphertext := ciphertext[rsaLen+2:]
+
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privKey, rsaCiphertext, label)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aed, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	zeroNonce := make([]byte, aed.NonceSize())
+	return aed.Open(nil, zeroNonce, aesCiphertext, nil)
+}
+
 // generateTestCertPEM generates a self-signed RSA certificate for testing.
 func generateTestCertPEM(t *testing.T) []byte {
 	t.Helper()
+	_, pemData := generateTestKeyAndCertPEM(t)
+	return pemData
+}
+
+// generateTestKeyAndCertPEM generates an RSA key and a self-signed certificate for it.
+func generateTestKeyAndCertPEM(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
 
 	// Generate RSA key
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -146,7 +269,7 @@ func generateTestCertPEM(t *testing.T) []byte {
 	}
 
 	// Encode to PEM
-	return pem.EncodeToMemory(&pem.Block{
+	return privateKey, pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: certDER,
 	})
@@ -0,0 +1,157 @@
+package seal
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/files"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrNoMatchingKey is returned when none of an Unsealer's keys decrypts a
+// value: it was sealed for another controller or another identity.
+var ErrNoMatchingKey = errors.New("no private key decrypts the value")
+
+// Unsealer decrypts sealed values with the controller's private keys, taken
+// from a backup of its sealing key Secrets. It is the offline inverse of
+// CertSealer and is meant for verification only.
+type Unsealer struct {
+	keys []*rsa.PrivateKey
+}
+
+// NewUnsealerFromFile loads private keys from a backup file.
+// See NewUnsealerFromPEM for the accepted formats.
+func NewUnsealerFromFile(path string) (*Unsealer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.WrapNotFound(path, err)
+		}
+		return nil, fmt.Errorf("read private key backup: %w", err)
+	}
+	return NewUnsealerFromPEM(data)
+}
+
+// NewUnsealerFromPEM loads private keys from PEM data ("RSA PRIVATE KEY" or
+// "PRIVATE KEY" blocks, any number of them) or from the YAML the controller
+// documents for backups:
+//
+//	kubectl get secret -n kube-system -l sealedsecrets.bitnami.com/sealed-secrets-key -o yaml
+//
+// which is a List of kubernetes.io/tls Secrets holding the key in tls.key.
+func NewUnsealerFromPEM(data []byte) (*Unsealer, error) {
+	keys, err := parsePrivateKeys(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		keys, err = parseKeySecrets(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(keys) == 0 {
+		return nil, core.NewValidationError("private key", "no RSA private keys found")
+	}
+	return &Unsealer{keys: keys}, nil
+}
+
+// parsePrivateKeys returns the RSA private keys in the PEM blocks of data.
+// Other block types (such as the certificate) are skipped.
+func parsePrivateKeys(data []byte) ([]*rsa.PrivateKey, error) {
+	var keys []*rsa.PrivateKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return keys, nil
+		}
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, core.WrapValidation("private key", err)
+			}
+			keys = append(keys, key)
+		case "PRIVATE KEY":
+			parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, core.WrapValidation("private key", err)
+			}
+			key, ok := parsed.(*rsa.PrivateKey)
+			if !ok {
+				return nil, core.NewValidationError("private key", "must be an RSA key")
+			}
+			keys = append(keys, key)
+		}
+	}
+}
+
+// keySecret is the part of a sealing key Secret (or a List of them) that
+// holds the key.
+type keySecret struct {
+	Kind  string            `json:"kind"`
+	Data  map[string]string `json:"data,omitempty"`
+	Items []keySecret       `json:"items,omitempty"`
+}
+
+// parseKeySecrets reads tls.key from Secret documents, including Lists.
+func parseKeySecrets(data []byte) ([]*rsa.PrivateKey, error) {
+	var keys []*rsa.PrivateKey
+	var collect func(s keySecret) error
+	collect = func(s keySecret) error {
+		for _, item := range s.Items {
+			if err := collect(item); err != nil {
+				return err
+			}
+		}
+		encoded, ok := s.Data["tls.key"]
+		if !ok {
+			return nil
+		}
+		pemData, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return core.WrapValidation("tls.key", err)
+		}
+		found, err := parsePrivateKeys(pemData)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, found...)
+		return nil
+	}
+
+	for _, doc := range files.SplitYAMLDocuments(data) {
+		var s keySecret
+		if err := yaml.Unmarshal(doc.Content(data), &s); err != nil {
+			return nil, core.WrapValidation("private key backup", err)
+		}
+		if err := collect(s); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Unseal decrypts a base64 encryptedData value sealed for the given
+// identity and scope. Every key is tried, since the controller keeps old
+// keys after rotating.
+func (u *Unsealer) Unseal(name, namespace, scope, sealed string) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, core.WrapValidation("encryptedData", err)
+	}
+	label := EncryptionLabel(namespace, name, scope)
+	for _, key := range u.keys {
+		if plaintext, err := hybridDecrypt(key, ciphertext, label); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrNoMatchingKey
+}
@@ -0,0 +1,90 @@
+package seal
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestUnsealer_RoundTrip(t *testing.T) {
+	privateKey, certPEM := generateTestKeyAndCertPEM(t)
+	sealer, _ := NewCertSealerFromPEM(certPEM)
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	// A backup may hold the cert next to the key; it is skipped
+	unsealer, err := NewUnsealerFromPEM(append(certPEM, keyPEM...))
+	if err != nil {
+		t.Fatalf("NewUnsealerFromPEM failed: %v", err)
+	}
+
+	for _, scope := range []string{ScopeStrict, ScopeNamespaceWide, ScopeClusterWide} {
+		sealed, _ := sealer.Seal("app", "ns", "password", []byte("hunter2"), scope)
+		plaintext, err := unsealer.Unseal("app", "ns", scope, sealed)
+		if err != nil {
+			t.Fatalf("%s: Unseal failed: %v", scope, err)
+		}
+		if string(plaintext) != "hunter2" {
+			t.Errorf("%s: plaintext = %q, want hunter2", scope, plaintext)
+		}
+	}
+
+	// Sealed for another identity
+	sealed, _ := sealer.Seal("app", "ns", "password", []byte("hunter2"), ScopeStrict)
+	if _, err := unsealer.Unseal("other", "ns", ScopeStrict, sealed); !errors.Is(err, ErrNoMatchingKey) {
+		t.Errorf("expected ErrNoMatchingKey, got %v", err)
+	}
+}
+
+func TestUnsealer_KeySecretList(t *testing.T) {
+	oldKey, _ := generateTestKeyAndCertPEM(t)
+	newKey, certPEM := generateTestKeyAndCertPEM(t)
+	sealer, _ := NewCertSealerFromPEM(certPEM)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(block *pem.Block) string {
+		return base64.StdEncoding.EncodeToString(pem.EncodeToMemory(block))
+	}
+	backup := fmt.Sprintf(`apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Secret
+  type: kubernetes.io/tls
+  data:
+    tls.crt: %s
+    tls.key: %s
+- apiVersion: v1
+  kind: Secret
+  type: kubernetes.io/tls
+  data:
+    tls.key: %s
+`, base64.StdEncoding.EncodeToString(certPEM),
+		encode(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(oldKey)}),
+		encode(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+	unsealer, err := NewUnsealerFromPEM([]byte(backup))
+	if err != nil {
+		t.Fatalf("NewUnsealerFromPEM failed: %v", err)
+	}
+	if len(unsealer.keys) != 2 {
+		t.Fatalf("loaded %d keys, want 2", len(unsealer.keys))
+	}
+
+	// Sealed under the newer key; the older one is tried first
+	sealed, _ := sealer.Seal("app", "ns", "k", []byte("v"), ScopeStrict)
+	if plaintext, err := unsealer.Unseal("app", "ns", ScopeStrict, sealed); err != nil || string(plaintext) != "v" {
+		t.Errorf("Unseal = %q, %v", plaintext, err)
+	}
+}
+
+func TestNewUnsealerFromPEM_NoKeys(t *testing.T) {
+	if _, err := NewUnsealerFromPEM(generateTestCertPEM(t)); err == nil {
+		t.Error("expected error for a backup without private keys")
+	}
+}
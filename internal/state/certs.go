@@ -0,0 +1,135 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/files"
+	"github.com/shermanhuman/waxseal/internal/seal"
+	"sigs.k8s.io/yaml"
+)
+
+// CertsDir is the directory, relative to the repo, that keeps every
+// controller certificate waxseal has sealed with.
+const CertsDir = ".waxseal/certs"
+
+const certIndexFileName = "index.yaml"
+
+// CertHistory is the history of controller certificates, stored in
+// .waxseal/certs/index.yaml next to a copy of each certificate.
+type CertHistory struct {
+	Certs []CertRecord `json:"certs,omitempty"`
+}
+
+// CertRecord describes one controller certificate.
+type CertRecord struct {
+	Fingerprint  string `json:"fingerprintSha256"`
+	Env          string `json:"env,omitempty"`          // "" for the top-level profile
+	File         string `json:"file"`                   // PEM copy, relative to CertsDir
+	NotBefore    string `json:"notBefore"`              // RFC3339
+	NotAfter     string `json:"notAfter"`               // RFC3339
+	AddedAt      string `json:"addedAt"`                // RFC3339
+	SupersededAt string `json:"supersededAt,omitempty"` // RFC3339; empty for the current cert
+}
+
+// Superseded reports whether a newer cert replaced this one.
+func (r *CertRecord) Superseded() bool {
+	return r.SupersededAt != ""
+}
+
+// LoadCertHistory reads the cert history of a repo.
+// Returns an empty history if there is none yet.
+func LoadCertHistory(repoPath string) (*CertHistory, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, CertsDir, certIndexFileName))
+	if os.IsNotExist(err) {
+		return &CertHistory{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var h CertHistory
+	if err := yaml.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("parse cert history: %w", err)
+	}
+	return &h, nil
+}
+
+// Save writes the cert history index.
+func (h *CertHistory) Save(repoPath string) error {
+	data, err := yaml.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return files.NewAtomicWriter().Write(filepath.Join(repoPath, CertsDir, certIndexFileName), data)
+}
+
+// Record adds a certificate as the current one for env, superseding the
+// previous current cert of that env, and saves a copy of it under
+// CertsDir. Recording the current cert again is a no-op. Returns the record
+// and whether the history changed; call Save to persist the index.
+func (h *CertHistory) Record(repoPath, env string, certPEM []byte) (*CertRecord, bool, error) {
+	cert, err := seal.NewCertSealerFromPEM(certPEM)
+	if err != nil {
+		return nil, false, err
+	}
+	fingerprint := cert.GetCertFingerprint()
+
+	current := h.Current(env)
+	if current != nil && current.Fingerprint == fingerprint {
+		return current, false, nil
+	}
+
+	// The file name is shared by every env that uses the same cert
+	file := fingerprint[:16] + ".pem"
+	if err := files.NewAtomicWriter().Write(filepath.Join(repoPath, CertsDir, file), certPEM); err != nil {
+		return nil, false, fmt.Errorf("save cert copy: %w", err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if current != nil {
+		current.SupersededAt = now
+	}
+
+	// A cert that was current before (rolled back to) becomes current again
+	for i := range h.Certs {
+		r := &h.Certs[i]
+		if r.Env == env && r.Fingerprint == fingerprint {
+			r.SupersededAt = ""
+			return r, true, nil
+		}
+	}
+
+	h.Certs = append(h.Certs, CertRecord{
+		Fingerprint: fingerprint,
+		Env:         env,
+		File:        file,
+		NotBefore:   cert.GetCertNotBefore().UTC().Format(time.RFC3339),
+		NotAfter:    cert.GetCertNotAfter().UTC().Format(time.RFC3339),
+		AddedAt:     now,
+	})
+	return &h.Certs[len(h.Certs)-1], true, nil
+}
+
+// Current returns the current cert of env, or nil if none is recorded.
+func (h *CertHistory) Current(env string) *CertRecord {
+	for i := len(h.Certs) - 1; i >= 0; i-- {
+		if r := &h.Certs[i]; r.Env == env && !r.Superseded() {
+			return r
+		}
+	}
+	return nil
+}
+
+// Lookup returns the record of a fingerprint in env, or nil if the cert was
+// never recorded for it.
+func (h *CertHistory) Lookup(env, fingerprint string) *CertRecord {
+	for i := range h.Certs {
+		if r := &h.Certs[i]; r.Env == env && r.Fingerprint == fingerprint {
+			return r
+		}
+	}
+	return nil
+}
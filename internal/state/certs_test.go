@@ -0,0 +1,98 @@
+package state
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/seal"
+)
+
+func testCertPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func fingerprintOf(t *testing.T, certPEM []byte) string {
+	t.Helper()
+	s, err := seal.NewCertSealerFromPEM(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.GetCertFingerprint()
+}
+
+func TestCertHistory_Record(t *testing.T) {
+	dir := t.TempDir()
+	first, second := testCertPEM(t), testCertPEM(t)
+
+	h, err := LoadCertHistory(dir)
+	if err != nil {
+		t.Fatalf("LoadCertHistory failed: %v", err)
+	}
+	if h.Current("") != nil {
+		t.Fatal("empty history should have no current cert")
+	}
+
+	if _, changed, err := h.Record(dir, "", first); err != nil || !changed {
+		t.Fatalf("Record(first) = %v, %v", changed, err)
+	}
+	if _, changed, _ := h.Record(dir, "", first); changed {
+		t.Error("recording the current cert again should be a no-op")
+	}
+	if _, _, err := h.Record(dir, "prod", first); err != nil {
+		t.Fatal(err)
+	}
+	rec, _, err := h.Record(dir, "", second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadCertHistory(dir)
+	if err != nil {
+		t.Fatalf("LoadCertHistory failed: %v", err)
+	}
+	if cur := loaded.Current(""); cur == nil || cur.Fingerprint != rec.Fingerprint {
+		t.Errorf("current = %+v, want the second cert", cur)
+	}
+	old := loaded.Lookup("", fingerprintOf(t, first))
+	if old == nil || !old.Superseded() {
+		t.Errorf("first cert = %+v, want superseded", old)
+	}
+	if prod := loaded.Current("prod"); prod == nil || prod.Fingerprint != old.Fingerprint {
+		t.Errorf("prod current = %+v, want the first cert (environments are independent)", prod)
+	}
+	if old.NotAfter == "" || old.NotBefore == "" {
+		t.Errorf("validity window not recorded: %+v", old)
+	}
+
+	// Each cert is kept as a PEM copy
+	saved, err := os.ReadFile(filepath.Join(dir, CertsDir, old.File))
+	if err != nil || string(saved) != string(first) {
+		t.Errorf("saved copy of first cert = %q, %v", saved, err)
+	}
+}
@@ -1,8 +1,11 @@
 // Package state manages persistent state for waxseal operations.
 // State is stored in .waxseal/state.yaml and tracks:
 // - Last certificate fingerprint (for cert rotation detection)
+// - Controller certificate history (in .waxseal/certs)
 // - Rotation audit trail
 // - Retirement audit trail
+// - Rotation hook results
+// - Reminder notices already sent
 package state
 
 import (
@@ -26,6 +29,12 @@ type State struct {
 
 	// Retirements is an audit trail of secret retirements.
 	Retirements []Retirement `json:"retirements,omitempty"`
+
+	// HookRuns records the results of rotation hooks.
+	HookRuns []HookRun `json:"hookRuns,omitempty"`
+
+	// Notices records reminder notices already sent, so each is sent once.
+	Notices []Notice `json:"notices,omitempty"`
 }
 
 // Rotation records a rotation or reseal operation.
@@ -45,6 +54,27 @@ type Retirement struct {
 	ReplacedBy string `json:"replacedBy,omitempty"`
 }
 
+// HookRun records one run of a rotation hook.
+type HookRun struct {
+	ShortName  string `json:"shortName"`
+	Phase      string `json:"phase"` // "preRotate" or "postRotate"
+	Hook       string `json:"hook"`
+	RanAt      string `json:"ranAt"` // RFC3339
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Notice records a sent reminder notice.
+type Notice struct {
+	ID     string `json:"id"`
+	SentAt string `json:"sentAt"` // RFC3339
+}
+
+// maxNotices bounds the notice log. Notice IDs include the expiry date, so
+// only notices for long-past expiries are dropped.
+const maxNotices = 1000
+
 const stateFileName = "state.yaml"
 
 // Load reads state from the .waxseal directory.
@@ -115,6 +145,54 @@ func (s *State) AddRetirement(shortName, reason, replacedBy string) {
 	s.Retirements = append(s.Retirements, r)
 }
 
+// AddHookRun adds a hook result to the state.
+// Keeps at most 100 entries to avoid unbounded growth.
+func (s *State) AddHookRun(shortName, phase, hook string, duration time.Duration, err error) {
+	h := HookRun{
+		ShortName:  shortName,
+		Phase:      phase,
+		Hook:       hook,
+		RanAt:      time.Now().UTC().Format(time.RFC3339),
+		OK:         err == nil,
+		DurationMs: duration.Milliseconds(),
+	}
+	if err != nil {
+		h.Error = err.Error()
+	}
+	s.HookRuns = append(s.HookRuns, h)
+
+	// Keep at most 100 entries
+	if len(s.HookRuns) > 100 {
+		s.HookRuns = s.HookRuns[len(s.HookRuns)-100:]
+	}
+}
+
+// NoticeSent reports whether a notice with this ID was sent.
+func (s *State) NoticeSent(id string) bool {
+	for _, n := range s.Notices {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordNotice records a sent notice.
+// Keeps at most maxNotices entries to avoid unbounded growth.
+func (s *State) RecordNotice(id string) {
+	if s.NoticeSent(id) {
+		return
+	}
+	s.Notices = append(s.Notices, Notice{
+		ID:     id,
+		SentAt: time.Now().UTC().Format(time.RFC3339),
+	})
+
+	if len(s.Notices) > maxNotices {
+		s.Notices = s.Notices[len(s.Notices)-maxNotices:]
+	}
+}
+
 // UpdateCertFingerprint updates the last known cert fingerprint.
 func (s *State) UpdateCertFingerprint(fingerprint string) {
 	s.LastCertFingerprint = fingerprint
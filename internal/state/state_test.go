@@ -1,9 +1,11 @@
 package state
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoad_NonexistentFile(t *testing.T) {
@@ -76,6 +78,45 @@ func TestAddRotation_KeepsMax100(t *testing.T) {
 	}
 }
 
+func TestAddHookRun(t *testing.T) {
+	s := &State{}
+	s.AddHookRun("secret", "postRotate", "reload", 1500*time.Millisecond, nil)
+	s.AddHookRun("secret", "postRotate", "verify", time.Second, errors.New("exit status 1"))
+
+	if len(s.HookRuns) != 2 {
+		t.Fatalf("expected 2 hook runs, got %d", len(s.HookRuns))
+	}
+	if !s.HookRuns[0].OK || s.HookRuns[0].DurationMs != 1500 {
+		t.Errorf("first run = %+v", s.HookRuns[0])
+	}
+	if s.HookRuns[1].OK || s.HookRuns[1].Error != "exit status 1" {
+		t.Errorf("second run = %+v", s.HookRuns[1])
+	}
+
+	for i := 0; i < 110; i++ {
+		s.AddHookRun("secret", "preRotate", "check", 0, nil)
+	}
+	if len(s.HookRuns) != 100 {
+		t.Errorf("expected 100 hook runs, got %d", len(s.HookRuns))
+	}
+}
+
+func TestRecordNotice(t *testing.T) {
+	s := &State{}
+	if s.NoticeSent("webhook:app/password@2026-01-01T00:00:00Z/7d") {
+		t.Fatal("empty state reports a sent notice")
+	}
+
+	s.RecordNotice("webhook:app/password@2026-01-01T00:00:00Z/7d")
+	s.RecordNotice("webhook:app/password@2026-01-01T00:00:00Z/7d")
+	if len(s.Notices) != 1 {
+		t.Errorf("expected 1 notice, got %d", len(s.Notices))
+	}
+	if !s.NoticeSent("webhook:app/password@2026-01-01T00:00:00Z/7d") {
+		t.Error("recorded notice not reported as sent")
+	}
+}
+
 func TestUpdateCertFingerprint(t *testing.T) {
 	s := &State{}
 	s.UpdateCertFingerprint("new-fingerprint-123")
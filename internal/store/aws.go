@@ -0,0 +1,555 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// AWSStore implements Store using AWS Secrets Manager.
+//
+// Secret resources keep the "projects/<project>/secrets/<secretId>" form and
+// map onto the AWS secret name "<project>/<secretId>".
+//
+// AWS has no numeric versions, so waxseal assigns them deterministically:
+// version N is written with ClientRequestToken (and therefore VersionId)
+// AWSVersionID(N). Reading version N is a GetSecretValue by that VersionId,
+// and the next version number is one past the highest waxseal VersionId on
+// the secret. AWS moves the AWSCURRENT stage to each new version as usual.
+//
+// Versions without a staging label are deprecated by AWS and may be removed
+// once a secret has more than 100 versions; pinned metadata versions older
+// than that can no longer be resealed.
+type AWSStore struct {
+	client      *http.Client
+	endpoint    string
+	region      string
+	credentials AWSCredentials
+	now         func() time.Time
+}
+
+// AWSOptions configures an AWSStore.
+type AWSOptions struct {
+	Region      string // e.g. "us-east-1"
+	Endpoint    string // default: https://secretsmanager.<region>.amazonaws.com
+	Credentials AWSCredentials
+}
+
+// AWSCredentials are static AWS credentials used for request signing.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // optional, for temporary credentials
+}
+
+// LoadAWSCredentials resolves static credentials the way the AWS CLI does
+// for its first two sources: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+// (plus AWS_SESSION_TOKEN), then the shared credentials file profile named
+// by AWS_PROFILE ("default" if unset). The file is
+// $AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials. SSO, assumed roles and
+// instance metadata are not resolved.
+func LoadAWSCredentials() (AWSCredentials, error) {
+	creds := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+		return creds, nil
+	}
+
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return AWSCredentials{}, fmt.Errorf("locate AWS credentials file: %w", err)
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return AWSCredentials{}, core.NewValidationError("aws.credentials", "required (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or AWS_PROFILE)")
+		}
+		return AWSCredentials{}, fmt.Errorf("read AWS credentials file: %w", err)
+	}
+	creds = parseAWSCredentialsFile(data, profile)
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AWSCredentials{}, core.NewValidationError("aws.credentials",
+			fmt.Sprintf("profile %q has no aws_access_key_id/aws_secret_access_key in %s", profile, path))
+	}
+	return creds, nil
+}
+
+// parseAWSCredentialsFile reads one profile from an INI-style shared
+// credentials file.
+func parseAWSCredentialsFile(data []byte, profile string) AWSCredentials {
+	var creds AWSCredentials
+	inProfile := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inProfile = strings.TrimSpace(line[1:len(line)-1]) == profile
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = value
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = value
+		case "aws_session_token":
+			creds.SessionToken = value
+		}
+	}
+	return creds
+}
+
+// awsVersionPrefix prefixes every waxseal-assigned VersionId.
+// ClientRequestToken must be 32-64 characters; the zero-padded number keeps
+// the ID at exactly 32 and makes lexical order match numeric order.
+const awsVersionPrefix = "waxseal-"
+
+// AWSVersionID returns the VersionId that waxseal uses for numeric version n.
+func AWSVersionID(n int) string {
+	return fmt.Sprintf("%s%024d", awsVersionPrefix, n)
+}
+
+// parseAWSVersionID returns the numeric version encoded in a waxseal
+// VersionId, or 0 if the ID was not assigned by waxseal.
+func parseAWSVersionID(id string) int {
+	if !strings.HasPrefix(id, awsVersionPrefix) {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(id, awsVersionPrefix))
+	if err != nil || n < 1 {
+		return 0
+	}
+	return n
+}
+
+// NewAWSStore creates a new AWS Secrets Manager store.
+func NewAWSStore(opts AWSOptions) (*AWSStore, error) {
+	if opts.Region == "" {
+		return nil, core.NewValidationError("aws.region", "required (set store.aws.region or AWS_REGION)")
+	}
+	if opts.Credentials.AccessKeyID == "" || opts.Credentials.SecretAccessKey == "" {
+		return nil, core.NewValidationError("aws.credentials", "required (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + opts.Region + ".amazonaws.com"
+	}
+
+	return &AWSStore{
+		client:      &http.Client{Timeout: 30 * time.Second},
+		endpoint:    strings.TrimRight(endpoint, "/"),
+		region:      opts.Region,
+		credentials: opts.Credentials,
+		now:         time.Now,
+	}, nil
+}
+
+// awsSecretValue is the GetSecretValue response (and the payload half of
+// CreateSecret/PutSecretValue requests).
+type awsSecretValue struct {
+	SecretString *string `json:"SecretString,omitempty"`
+	SecretBinary []byte  `json:"SecretBinary,omitempty"` // base64 on the wire
+}
+
+// AccessVersion retrieves a specific version of a secret.
+func (a *AWSStore) AccessVersion(ctx context.Context, secretResource string, version string) ([]byte, error) {
+	n, err := parseNumericVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	name, err := awsSecretName(secretResource)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := secretResource + "/versions/" + version
+	var resp awsSecretValue
+	req := map[string]string{"SecretId": name, "VersionId": AWSVersionID(n)}
+	if err := a.call(ctx, "GetSecretValue", req, &resp, resource); err != nil {
+		return nil, err
+	}
+
+	if resp.SecretString != nil {
+		return []byte(*resp.SecretString), nil
+	}
+	return resp.SecretBinary, nil
+}
+
+// AddVersion adds a new version to an existing secret.
+func (a *AWSStore) AddVersion(ctx context.Context, secretResource string, data []byte) (string, error) {
+	name, err := awsSecretName(secretResource)
+	if err != nil {
+		return "", err
+	}
+
+	versions, err := a.listVersionIDs(ctx, name, secretResource)
+	if err != nil {
+		return "", err
+	}
+
+	latest := 0
+	for _, id := range versions {
+		if n := parseAWSVersionID(id); n > latest {
+			latest = n
+		}
+	}
+	next := latest + 1
+
+	req := struct {
+		SecretID           string `json:"SecretId"`
+		ClientRequestToken string `json:"ClientRequestToken"`
+		awsSecretValue
+	}{name, AWSVersionID(next), awsPayload(data)}
+
+	if err := a.call(ctx, "PutSecretValue", req, nil, secretResource); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(next), nil
+}
+
+// CreateSecret creates a new secret with an initial version.
+func (a *AWSStore) CreateSecret(ctx context.Context, secretResource string, data []byte) (string, error) {
+	name, err := awsSecretName(secretResource)
+	if err != nil {
+		return "", err
+	}
+
+	req := struct {
+		Name               string `json:"Name"`
+		ClientRequestToken string `json:"ClientRequestToken"`
+		awsSecretValue
+	}{name, AWSVersionID(1), awsPayload(data)}
+
+	if err := a.call(ctx, "CreateSecret", req, nil, secretResource); err != nil {
+		return "", err
+	}
+	return "1", nil
+}
+
+// CreateSecretVersion creates a secret if it doesn't exist and adds a version.
+func (a *AWSStore) CreateSecretVersion(ctx context.Context, secretResource string, data []byte) (string, error) {
+	version, err := a.AddVersion(ctx, secretResource, data)
+	if err == nil {
+		return version, nil
+	}
+
+	if core.IsNotFound(err) {
+		return a.CreateSecret(ctx, secretResource, data)
+	}
+
+	return "", err
+}
+
+// SecretExists checks if a secret exists.
+func (a *AWSStore) SecretExists(ctx context.Context, secretResource string) (bool, error) {
+	name, err := awsSecretName(secretResource)
+	if err != nil {
+		return false, err
+	}
+
+	err = a.call(ctx, "DescribeSecret", map[string]string{"SecretId": name}, nil, secretResource)
+	if err != nil {
+		if core.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// SecretVersionExists checks if a specific version of a secret exists.
+// Returns (exists, stateEnabled, error). AWS versions have no disabled state,
+// so an existing version is always enabled.
+func (a *AWSStore) SecretVersionExists(ctx context.Context, secretResource string, version string) (bool, bool, error) {
+	n, err := parseNumericVersion(version)
+	if err != nil {
+		return false, false, err
+	}
+	name, err := awsSecretName(secretResource)
+	if err != nil {
+		return false, false, err
+	}
+
+	versions, err := a.listVersionIDs(ctx, name, secretResource)
+	if err != nil {
+		if core.IsNotFound(err) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	want := AWSVersionID(n)
+	for _, id := range versions {
+		if id == want {
+			return true, true, nil
+		}
+	}
+	return false, false, nil
+}
+
+// listVersionIDs returns every VersionId on a secret, including deprecated
+// (unlabeled) versions, following pagination.
+func (a *AWSStore) listVersionIDs(ctx context.Context, name, resource string) ([]string, error) {
+	var ids []string
+	var nextToken string
+	for {
+		req := map[string]any{"SecretId": name, "IncludeDeprecated": true}
+		if nextToken != "" {
+			req["NextToken"] = nextToken
+		}
+
+		var resp struct {
+			Versions []struct {
+				VersionID string `json:"VersionId"`
+			} `json:"Versions"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := a.call(ctx, "ListSecretVersionIds", req, &resp, resource); err != nil {
+			return nil, err
+		}
+		for _, v := range resp.Versions {
+			ids = append(ids, v.VersionID)
+		}
+		if resp.NextToken == "" {
+			return ids, nil
+		}
+		nextToken = resp.NextToken
+	}
+}
+
+// call performs a signed Secrets Manager JSON API request.
+func (a *AWSStore) call(ctx context.Context, action string, in any, out any, resource string) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encode %s request: %w", action, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create %s request: %w", action, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager."+action)
+	signAWSRequestV4(req, body, a.credentials, a.region, "secretsmanager", a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("secretsmanager %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read %s response: %w", action, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return wrapAWSError(action, respBody, resource)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("decode %s response: %w", action, err)
+		}
+	}
+	return nil
+}
+
+// wrapAWSError maps Secrets Manager error types onto domain error types.
+func wrapAWSError(action string, body []byte, resource string) error {
+	var apiErr struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+		Msg     string `json:"Message"`
+	}
+	_ = json.Unmarshal(body, &apiErr)
+
+	// __type may be namespaced, e.g. "com.amazonaws.secretsmanager#ResourceNotFoundException"
+	errType := apiErr.Type
+	if i := strings.LastIndex(errType, "#"); i >= 0 {
+		errType = errType[i+1:]
+	}
+	msg := apiErr.Message
+	if msg == "" {
+		msg = apiErr.Msg
+	}
+	detail := fmt.Errorf("secretsmanager %s: %s: %s", action, errType, msg)
+
+	switch errType {
+	case "ResourceNotFoundException":
+		return core.WrapNotFound(resource, detail)
+	case "ResourceExistsException":
+		return fmt.Errorf("%s: %w: %v", resource, core.ErrAlreadyExists, detail)
+	case "AccessDeniedException":
+		return core.WrapPermissionDenied(resource, detail)
+	case "UnrecognizedClientException", "InvalidSignatureException", "ExpiredTokenException", "IncompleteSignature":
+		return fmt.Errorf("%s: %w — check AWS credentials: %v", resource, core.ErrUnauthenticated, detail)
+	case "InvalidParameterException", "InvalidRequestException", "ValidationException":
+		return core.WrapValidation(action, detail)
+	}
+	return fmt.Errorf("%s: %w", resource, detail)
+}
+
+// awsSecretName maps a secret resource onto an AWS secret name.
+func awsSecretName(secretResource string) (string, error) {
+	project, secretID := splitSecretResource(secretResource)
+	if project == "" || secretID == "" {
+		return "", core.NewValidationError("secretResource", "must be projects/<project>/secrets/<secretId>")
+	}
+	return project + "/" + secretID, nil
+}
+
+// awsPayload stores UTF-8 data as SecretString (readable in the AWS console
+// and by other tooling) and anything else as SecretBinary.
+func awsPayload(data []byte) awsSecretValue {
+	if utf8.Valid(data) {
+		s := string(data)
+		return awsSecretValue{SecretString: &s}
+	}
+	return awsSecretValue{SecretBinary: data}
+}
+
+// parseNumericVersion validates and parses a numeric version string.
+func parseNumericVersion(version string) (int, error) {
+	if err := ValidateNumericVersion(version); err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(version)
+	if n < 1 {
+		return 0, core.NewValidationError("version", "must be at least 1")
+	}
+	return n, nil
+}
+
+// signAWSRequestV4 signs req in place using AWS Signature Version 4.
+// Signed headers are host, x-amz-date, and any of content-type, x-amz-target
+// and x-amz-security-token that are present.
+func signAWSRequestV4(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for _, h := range []string{"Content-Type", "X-Amz-Date", "X-Amz-Target", "X-Amz-Security-Token"} {
+		if v := req.Header.Get(h); v != "" {
+			headers[strings.ToLower(h)] = strings.TrimSpace(v)
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters sorted by key, as SigV4 requires.
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, awsURIEscape(k)+"="+awsURIEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEscape percent-encodes everything except RFC 3986 unreserved characters.
+func awsURIEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// Compile-time checks that AWSStore implements Store and VersionChecker.
+var (
+	_ Store          = (*AWSStore)(nil)
+	_ VersionChecker = (*AWSStore)(nil)
+)
@@ -0,0 +1,431 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// fakeSecretsManager is a minimal in-process stand-in for the AWS Secrets
+// Manager JSON API.
+type fakeSecretsManager struct {
+	mu       sync.Mutex
+	secrets  map[string]*fakeAWSSecret
+	requests []string // X-Amz-Target of each request
+}
+
+type fakeAWSSecret struct {
+	versions []fakeAWSVersion
+}
+
+type fakeAWSVersion struct {
+	id     string
+	value  awsSecretValue
+	stages []string
+}
+
+func newFakeSecretsManager(t *testing.T) (*httptest.Server, *fakeSecretsManager) {
+	t.Helper()
+	sm := &fakeSecretsManager{secrets: make(map[string]*fakeAWSSecret)}
+	srv := httptest.NewServer(sm)
+	t.Cleanup(srv.Close)
+	return srv, sm
+}
+
+func (sm *fakeSecretsManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/") {
+		writeAWSError(w, "UnrecognizedClientException", "missing or invalid signature")
+		return
+	}
+
+	target := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "secretsmanager.")
+	sm.requests = append(sm.requests, target)
+
+	var req struct {
+		Name               string
+		SecretId           string
+		VersionId          string
+		ClientRequestToken string
+		awsSecretValue
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAWSError(w, "InvalidRequestException", err.Error())
+		return
+	}
+
+	switch target {
+	case "CreateSecret":
+		if _, ok := sm.secrets[req.Name]; ok {
+			writeAWSError(w, "ResourceExistsException", "exists")
+			return
+		}
+		sm.secrets[req.Name] = &fakeAWSSecret{versions: []fakeAWSVersion{
+			{id: req.ClientRequestToken, value: req.awsSecretValue, stages: []string{"AWSCURRENT"}},
+		}}
+		writeAWSJSON(w, map[string]any{"Name": req.Name, "VersionId": req.ClientRequestToken})
+
+	case "PutSecretValue":
+		secret, ok := sm.secrets[req.SecretId]
+		if !ok {
+			writeAWSError(w, "ResourceNotFoundException", "not found")
+			return
+		}
+		for i := range secret.versions {
+			if secret.versions[i].id == req.ClientRequestToken {
+				writeAWSError(w, "ResourceExistsException", "version exists")
+				return
+			}
+			secret.versions[i].stages = nil
+		}
+		secret.versions = append(secret.versions, fakeAWSVersion{
+			id: req.ClientRequestToken, value: req.awsSecretValue, stages: []string{"AWSCURRENT"},
+		})
+		writeAWSJSON(w, map[string]any{"VersionId": req.ClientRequestToken})
+
+	case "GetSecretValue":
+		secret, ok := sm.secrets[req.SecretId]
+		if !ok {
+			writeAWSError(w, "ResourceNotFoundException", "not found")
+			return
+		}
+		for _, v := range secret.versions {
+			if v.id == req.VersionId {
+				writeAWSJSON(w, v.value)
+				return
+			}
+		}
+		writeAWSError(w, "ResourceNotFoundException", "version not found")
+
+	case "DescribeSecret":
+		if _, ok := sm.secrets[req.SecretId]; !ok {
+			writeAWSError(w, "ResourceNotFoundException", "not found")
+			return
+		}
+		writeAWSJSON(w, map[string]any{"Name": req.SecretId})
+
+	case "ListSecretVersionIds":
+		secret, ok := sm.secrets[req.SecretId]
+		if !ok {
+			writeAWSError(w, "ResourceNotFoundException", "not found")
+			return
+		}
+		var versions []map[string]any
+		for _, v := range secret.versions {
+			versions = append(versions, map[string]any{"VersionId": v.id, "VersionStages": v.stages})
+		}
+		writeAWSJSON(w, map[string]any{"Versions": versions})
+
+	default:
+		writeAWSError(w, "InvalidAction", target)
+	}
+}
+
+func writeAWSJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeAWSError(w http.ResponseWriter, errType, msg string) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"__type": errType, "message": msg})
+}
+
+func newTestAWSStore(t *testing.T) (*AWSStore, *fakeSecretsManager) {
+	t.Helper()
+	srv, sm := newFakeSecretsManager(t)
+	s, err := NewAWSStore(AWSOptions{
+		Region:      "us-east-1",
+		Endpoint:    srv.URL,
+		Credentials: AWSCredentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("NewAWSStore failed: %v", err)
+	}
+	return s, sm
+}
+
+func TestAWSVersionID(t *testing.T) {
+	testCases := []struct {
+		n    int
+		want string
+	}{
+		{1, "waxseal-000000000000000000000001"},
+		{42, "waxseal-000000000000000000000042"},
+	}
+	for _, tc := range testCases {
+		got := AWSVersionID(tc.n)
+		if got != tc.want {
+			t.Errorf("AWSVersionID(%d) = %q, want %q", tc.n, got, tc.want)
+		}
+		if len(got) < 32 || len(got) > 64 {
+			t.Errorf("AWSVersionID(%d) length %d outside ClientRequestToken bounds", tc.n, len(got))
+		}
+		if back := parseAWSVersionID(got); back != tc.n {
+			t.Errorf("parseAWSVersionID(%q) = %d, want %d", got, back, tc.n)
+		}
+	}
+
+	if n := parseAWSVersionID("a1b2c3d4-5678-90ab-cdef-EXAMPLE11111"); n != 0 {
+		t.Errorf("non-waxseal VersionId parsed as %d, want 0", n)
+	}
+}
+
+func TestAWSStore_CreateAndAccess(t *testing.T) {
+	ctx := context.Background()
+	s, sm := newTestAWSStore(t)
+
+	resource := "projects/test/secrets/my-secret"
+	version, err := s.CreateSecret(ctx, resource, []byte("secret-value"))
+	if err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+	if version != "1" {
+		t.Errorf("version = %q, want %q", version, "1")
+	}
+
+	// Resource maps onto AWS secret name <project>/<secretId>
+	if _, ok := sm.secrets["test/my-secret"]; !ok {
+		t.Errorf("expected secret test/my-secret, have %v", sm.secrets)
+	}
+
+	got, err := s.AccessVersion(ctx, resource, "1")
+	if err != nil {
+		t.Fatalf("AccessVersion failed: %v", err)
+	}
+	if string(got) != "secret-value" {
+		t.Errorf("got %q, want %q", got, "secret-value")
+	}
+}
+
+func TestAWSStore_AddVersionMapsDeterministically(t *testing.T) {
+	ctx := context.Background()
+	s, sm := newTestAWSStore(t)
+
+	resource := "projects/test/secrets/my-secret"
+	if _, err := s.CreateSecret(ctx, resource, []byte("v1-value")); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+	for i, want := range []string{"2", "3"} {
+		version, err := s.AddVersion(ctx, resource, []byte("v"+want+"-value"))
+		if err != nil {
+			t.Fatalf("AddVersion #%d failed: %v", i, err)
+		}
+		if version != want {
+			t.Errorf("version = %q, want %q", version, want)
+		}
+	}
+
+	secret := sm.secrets["test/my-secret"]
+	if secret.versions[2].id != AWSVersionID(3) {
+		t.Errorf("VersionId = %q, want %q", secret.versions[2].id, AWSVersionID(3))
+	}
+
+	for _, v := range []string{"1", "2", "3"} {
+		got, err := s.AccessVersion(ctx, resource, v)
+		if err != nil {
+			t.Fatalf("AccessVersion(%s) failed: %v", v, err)
+		}
+		if string(got) != "v"+v+"-value" {
+			t.Errorf("version %s = %q, want %q", v, got, "v"+v+"-value")
+		}
+	}
+}
+
+func TestAWSStore_IgnoresForeignVersions(t *testing.T) {
+	ctx := context.Background()
+	s, sm := newTestAWSStore(t)
+
+	// A secret created outside waxseal with an AWS-generated VersionId
+	value := "external"
+	sm.secrets["test/external"] = &fakeAWSSecret{versions: []fakeAWSVersion{
+		{id: "a1b2c3d4-5678-90ab-cdef-EXAMPLE11111", value: awsSecretValue{SecretString: &value}},
+	}}
+
+	version, err := s.AddVersion(ctx, "projects/test/secrets/external", []byte("managed"))
+	if err != nil {
+		t.Fatalf("AddVersion failed: %v", err)
+	}
+	if version != "1" {
+		t.Errorf("first waxseal version = %q, want %q", version, "1")
+	}
+}
+
+func TestAWSStore_BinaryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, sm := newTestAWSStore(t)
+
+	resource := "projects/test/secrets/binary"
+	data := []byte{0x00, 0xff, 0xfe, 0x80}
+	if _, err := s.CreateSecret(ctx, resource, data); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+	if sm.secrets["test/binary"].versions[0].value.SecretString != nil {
+		t.Error("non-UTF-8 data should be stored as SecretBinary")
+	}
+
+	got, err := s.AccessVersion(ctx, resource, "1")
+	if err != nil {
+		t.Fatalf("AccessVersion failed: %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("got %v, want %v", got, data)
+	}
+}
+
+func TestAWSStore_Errors(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestAWSStore(t)
+
+	_, err := s.AccessVersion(ctx, "projects/test/secrets/missing", "1")
+	if !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("AccessVersion: expected ErrNotFound, got %v", err)
+	}
+
+	_, err = s.AddVersion(ctx, "projects/test/secrets/missing", []byte("x"))
+	if !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("AddVersion: expected ErrNotFound, got %v", err)
+	}
+
+	s.CreateSecret(ctx, "projects/test/secrets/dup", []byte("x"))
+	_, err = s.CreateSecret(ctx, "projects/test/secrets/dup", []byte("y"))
+	if !errors.Is(err, core.ErrAlreadyExists) {
+		t.Errorf("CreateSecret: expected ErrAlreadyExists, got %v", err)
+	}
+
+	_, err = s.AccessVersion(ctx, "projects/test/secrets/dup", "latest")
+	if !errors.Is(err, core.ErrValidation) {
+		t.Errorf("alias version: expected ErrValidation, got %v", err)
+	}
+}
+
+func TestAWSStore_Unauthenticated(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := newFakeSecretsManager(t)
+	s, _ := NewAWSStore(AWSOptions{
+		Region:      "us-east-1",
+		Endpoint:    srv.URL,
+		Credentials: AWSCredentials{AccessKeyID: "AKIDOTHER", SecretAccessKey: "secret"},
+	})
+
+	_, err := s.AccessVersion(ctx, "projects/test/secrets/x", "1")
+	if !errors.Is(err, core.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestLoadAWSCredentials(t *testing.T) {
+	credsFile := filepath.Join(t.TempDir(), "credentials")
+	data := `[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+# team profile
+[team]
+aws_access_key_id=AKIDTEAM
+aws_secret_access_key=team-secret
+aws_session_token=team-token
+`
+	if err := os.WriteFile(credsFile, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	t.Setenv("AWS_PROFILE", "")
+	creds, err := LoadAWSCredentials()
+	if err != nil || creds.AccessKeyID != "AKIDDEFAULT" || creds.SecretAccessKey != "default-secret" {
+		t.Errorf("default profile = %+v, %v", creds, err)
+	}
+
+	t.Setenv("AWS_PROFILE", "team")
+	creds, err = LoadAWSCredentials()
+	if err != nil || creds.AccessKeyID != "AKIDTEAM" || creds.SessionToken != "team-token" {
+		t.Errorf("team profile = %+v, %v", creds, err)
+	}
+
+	t.Setenv("AWS_PROFILE", "missing")
+	if _, err := LoadAWSCredentials(); !errors.Is(err, core.ErrValidation) {
+		t.Errorf("missing profile: expected ErrValidation, got %v", err)
+	}
+
+	// Environment variables take precedence over the file
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	creds, err = LoadAWSCredentials()
+	if err != nil || creds.AccessKeyID != "AKIDENV" {
+		t.Errorf("env credentials = %+v, %v", creds, err)
+	}
+}
+
+func TestAWSStore_CreateSecretVersionAndExists(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestAWSStore(t)
+
+	resource := "projects/test/secrets/idempotent"
+	exists, err := s.SecretExists(ctx, resource)
+	if err != nil || exists {
+		t.Fatalf("SecretExists = %v, %v; want false, nil", exists, err)
+	}
+
+	v1, err := s.CreateSecretVersion(ctx, resource, []byte("first"))
+	if err != nil {
+		t.Fatalf("CreateSecretVersion (create) failed: %v", err)
+	}
+	v2, err := s.CreateSecretVersion(ctx, resource, []byte("second"))
+	if err != nil {
+		t.Fatalf("CreateSecretVersion (add) failed: %v", err)
+	}
+	if v1 != "1" || v2 != "2" {
+		t.Errorf("versions = %q, %q, want 1, 2", v1, v2)
+	}
+
+	exists, err = s.SecretExists(ctx, resource)
+	if err != nil || !exists {
+		t.Errorf("SecretExists = %v, %v; want true, nil", exists, err)
+	}
+
+	found, enabled, err := s.SecretVersionExists(ctx, resource, "2")
+	if err != nil || !found || !enabled {
+		t.Errorf("SecretVersionExists(2) = %v, %v, %v; want true, true, nil", found, enabled, err)
+	}
+	found, _, err = s.SecretVersionExists(ctx, resource, "3")
+	if err != nil || found {
+		t.Errorf("SecretVersionExists(3) = %v, %v; want false, nil", found, err)
+	}
+}
+
+// TestSignAWSRequestV4 checks the signer against the "get-vanilla" case from
+// the AWS Signature Version 4 test suite.
+func TestSignAWSRequestV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	signAWSRequestV4(req, nil, creds, "us-east-1", "service", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/shermanhuman/waxseal/internal/core"
 )
@@ -17,6 +18,7 @@ type FakeStore struct {
 
 type fakeSecret struct {
 	versions map[string][]byte
+	created  map[string]time.Time
 	latest   int
 }
 
@@ -65,6 +67,7 @@ func (f *FakeStore) AddVersion(ctx context.Context, secretResource string, data
 	stored := make([]byte, len(data))
 	copy(stored, data)
 	secret.versions[version] = stored
+	secret.created[version] = time.Now()
 
 	return version, nil
 }
@@ -84,6 +87,7 @@ func (f *FakeStore) CreateSecret(ctx context.Context, secretResource string, dat
 
 	f.secrets[secretResource] = &fakeSecret{
 		versions: map[string][]byte{"1": stored},
+		created:  map[string]time.Time{"1": time.Now()},
 		latest:   1,
 	}
 
@@ -124,6 +128,7 @@ func (f *FakeStore) SetVersion(secretResource, version string, data []byte) {
 	if !ok {
 		secret = &fakeSecret{
 			versions: make(map[string][]byte),
+			created:  make(map[string]time.Time),
 			latest:   0,
 		}
 		f.secrets[secretResource] = secret
@@ -133,6 +138,7 @@ func (f *FakeStore) SetVersion(secretResource, version string, data []byte) {
 	stored := make([]byte, len(data))
 	copy(stored, data)
 	secret.versions[version] = stored
+	secret.created[version] = time.Now()
 
 	// Update latest if this is a higher version
 	if v, err := strconv.Atoi(version); err == nil && v > secret.latest {
@@ -140,6 +146,32 @@ func (f *FakeStore) SetVersion(secretResource, version string, data []byte) {
 	}
 }
 
+// VersionCreateTime returns when a version was added.
+func (f *FakeStore) VersionCreateTime(ctx context.Context, secretResource string, version string) (time.Time, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	secret, ok := f.secrets[secretResource]
+	if !ok {
+		return time.Time{}, core.WrapNotFound(secretResource, nil)
+	}
+	created, ok := secret.created[version]
+	if !ok {
+		return time.Time{}, core.WrapNotFound(fmt.Sprintf("%s/versions/%s", secretResource, version), nil)
+	}
+	return created, nil
+}
+
+// SetVersionCreateTime overrides when a version was created, for testing.
+func (f *FakeStore) SetVersionCreateTime(secretResource, version string, created time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if secret, ok := f.secrets[secretResource]; ok {
+		secret.created[version] = created
+	}
+}
+
 // Clear removes all secrets from the store.
 func (f *FakeStore) Clear() {
 	f.mu.Lock()
@@ -147,5 +179,8 @@ func (f *FakeStore) Clear() {
 	f.secrets = make(map[string]*fakeSecret)
 }
 
-// Compile-time check that FakeStore implements Store.
-var _ Store = (*FakeStore)(nil)
+// Compile-time checks that FakeStore implements Store and VersionTimer.
+var (
+	_ Store        = (*FakeStore)(nil)
+	_ VersionTimer = (*FakeStore)(nil)
+)
@@ -0,0 +1,316 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/shermanhuman/waxseal/internal/core"
+	"github.com/shermanhuman/waxseal/internal/files"
+)
+
+// FileStore implements Store as a single age-encrypted file on disk, for
+// offline and air-gapped repos (default: .waxseal/vault.age).
+//
+// The file is ASCII-armored so it can be committed alongside the repo, and
+// can be inspected with the stock age CLI ("age -d"). It is encrypted either
+// to a passphrase (scrypt) or to one or more age X25519 recipients.
+//
+// Every operation decrypts the whole file and every write re-encrypts it and
+// replaces it atomically. Writers in separate processes are not coordinated.
+type FileStore struct {
+	mu         sync.Mutex
+	path       string
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+// FileStoreOptions configures a FileStore. Exactly one of Passphrase or
+// Recipients must be set.
+type FileStoreOptions struct {
+	Path         string   // encrypted store file
+	Passphrase   string   // passphrase mode
+	Recipients   []string // age X25519 recipients ("age1...")
+	IdentityFile string   // age identity file for recipient mode (needed to read)
+
+	scryptWorkFactor int // overrides age's default scrypt cost (tests only)
+}
+
+// fileStoreSchemaVersion is the version of the decrypted JSON document.
+const fileStoreSchemaVersion = 1
+
+// fileStoreData is the decrypted content of the store file.
+type fileStoreData struct {
+	SchemaVersion int                         `json:"schemaVersion"`
+	Secrets       map[string]*fileStoreSecret `json:"secrets"`
+}
+
+// fileStoreSecret holds every version of one secret.
+type fileStoreSecret struct {
+	Latest   int               `json:"latest"`
+	Versions map[string][]byte `json:"versions"` // base64 in JSON
+}
+
+// NewFileStore creates an encrypted local-file store.
+func NewFileStore(opts FileStoreOptions) (*FileStore, error) {
+	if opts.Path == "" {
+		return nil, core.NewValidationError("file.path", "required")
+	}
+
+	s := &FileStore{path: opts.Path}
+
+	switch {
+	case opts.Passphrase != "" && len(opts.Recipients) > 0:
+		return nil, core.NewValidationError("file", "use either a passphrase or recipients, not both")
+
+	case opts.Passphrase != "":
+		r, err := age.NewScryptRecipient(opts.Passphrase)
+		if err != nil {
+			return nil, core.WrapValidation("file.passphrase", err)
+		}
+		if opts.scryptWorkFactor > 0 {
+			r.SetWorkFactor(opts.scryptWorkFactor)
+		}
+		id, err := age.NewScryptIdentity(opts.Passphrase)
+		if err != nil {
+			return nil, core.WrapValidation("file.passphrase", err)
+		}
+		s.recipients = []age.Recipient{r}
+		s.identities = []age.Identity{id}
+
+	case len(opts.Recipients) > 0:
+		for _, rs := range opts.Recipients {
+			r, err := age.ParseX25519Recipient(rs)
+			if err != nil {
+				return nil, core.WrapValidation("file.recipients", err)
+			}
+			s.recipients = append(s.recipients, r)
+		}
+		if opts.IdentityFile != "" {
+			f, err := os.Open(opts.IdentityFile)
+			if err != nil {
+				return nil, fmt.Errorf("open identity file: %w", err)
+			}
+			defer f.Close()
+			ids, err := age.ParseIdentities(f)
+			if err != nil {
+				return nil, core.WrapValidation("file.identityFile", err)
+			}
+			s.identities = ids
+		}
+
+	default:
+		return nil, core.NewValidationError("file", "a passphrase or at least one recipient is required")
+	}
+
+	return s, nil
+}
+
+// AccessVersion retrieves a specific version of a secret.
+func (s *FileStore) AccessVersion(ctx context.Context, secretResource string, version string) ([]byte, error) {
+	if !numericVersionPattern.MatchString(version) {
+		return nil, core.NewValidationError("version", "must be numeric (aliases like 'latest' are not supported)")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, ok := data.Secrets[secretResource]
+	if !ok {
+		return nil, core.WrapNotFound(secretResource, nil)
+	}
+	value, ok := secret.Versions[version]
+	if !ok {
+		return nil, core.WrapNotFound(secretResource+"/versions/"+version, nil)
+	}
+	return value, nil
+}
+
+// AddVersion adds a new version to an existing secret.
+func (s *FileStore) AddVersion(ctx context.Context, secretResource string, value []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := data.Secrets[secretResource]
+	if !ok {
+		return "", core.WrapNotFound(secretResource, nil)
+	}
+
+	secret.Latest++
+	version := strconv.Itoa(secret.Latest)
+	secret.Versions[version] = append([]byte(nil), value...)
+
+	if err := s.save(data); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// CreateSecret creates a new secret with an initial version.
+func (s *FileStore) CreateSecret(ctx context.Context, secretResource string, value []byte) (string, error) {
+	if project, secretID := splitSecretResource(secretResource); project == "" || secretID == "" {
+		return "", core.NewValidationError("secretResource", "must be projects/<project>/secrets/<secretId>")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := data.Secrets[secretResource]; ok {
+		return "", fmt.Errorf("%s: %w", secretResource, core.ErrAlreadyExists)
+	}
+
+	data.Secrets[secretResource] = &fileStoreSecret{
+		Latest:   1,
+		Versions: map[string][]byte{"1": append([]byte(nil), value...)},
+	}
+
+	if err := s.save(data); err != nil {
+		return "", err
+	}
+	return "1", nil
+}
+
+// CreateSecretVersion creates a secret if needed and adds a version.
+func (s *FileStore) CreateSecretVersion(ctx context.Context, secretResource string, value []byte) (string, error) {
+	version, err := s.AddVersion(ctx, secretResource, value)
+	if err == nil {
+		return version, nil
+	}
+
+	if core.IsNotFound(err) {
+		return s.CreateSecret(ctx, secretResource, value)
+	}
+
+	return "", err
+}
+
+// SecretExists checks if a secret exists.
+func (s *FileStore) SecretExists(ctx context.Context, secretResource string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	_, ok := data.Secrets[secretResource]
+	return ok, nil
+}
+
+// SecretVersionExists checks if a specific version of a secret exists.
+// Returns (exists, stateEnabled, error). File store versions are always enabled.
+func (s *FileStore) SecretVersionExists(ctx context.Context, secretResource string, version string) (bool, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return false, false, err
+	}
+	secret, ok := data.Secrets[secretResource]
+	if !ok {
+		return false, false, nil
+	}
+	_, ok = secret.Versions[version]
+	return ok, ok, nil
+}
+
+// load decrypts and parses the store file. A missing file is an empty store.
+func (s *FileStore) load() (*fileStoreData, error) {
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &fileStoreData{
+				SchemaVersion: fileStoreSchemaVersion,
+				Secrets:       make(map[string]*fileStoreSecret),
+			}, nil
+		}
+		return nil, fmt.Errorf("read store file: %w", err)
+	}
+
+	if len(s.identities) == 0 {
+		return nil, fmt.Errorf("%s: %w — no age identity configured to decrypt the store", s.path, core.ErrUnauthenticated)
+	}
+
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(raw)), s.identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) || strings.Contains(err.Error(), "incorrect passphrase") {
+			return nil, fmt.Errorf("%s: %w — wrong passphrase or identity: %v", s.path, core.ErrUnauthenticated, err)
+		}
+		return nil, fmt.Errorf("decrypt store file: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt store file: %w", err)
+	}
+
+	var data fileStoreData
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, core.WrapValidation("store file", err)
+	}
+	if data.SchemaVersion != fileStoreSchemaVersion {
+		return nil, core.NewValidationError("store file", fmt.Sprintf("unsupported schemaVersion %d", data.SchemaVersion))
+	}
+	if data.Secrets == nil {
+		data.Secrets = make(map[string]*fileStoreSecret)
+	}
+	return &data, nil
+}
+
+// save encrypts the store and atomically replaces the file.
+func (s *FileStore) save(data *fileStoreData) error {
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode store file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, s.recipients...)
+	if err != nil {
+		return fmt.Errorf("encrypt store file: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return fmt.Errorf("encrypt store file: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("encrypt store file: %w", err)
+	}
+	if err := aw.Close(); err != nil {
+		return fmt.Errorf("encrypt store file: %w", err)
+	}
+
+	return files.NewAtomicWriter().Write(s.path, buf.Bytes())
+}
+
+// Compile-time checks that FileStore implements Store and VersionChecker.
+var (
+	_ Store          = (*FileStore)(nil)
+	_ VersionChecker = (*FileStore)(nil)
+)
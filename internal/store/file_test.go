@@ -0,0 +1,184 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+func newTestFileStore(t *testing.T, path string) *FileStore {
+	t.Helper()
+	s, err := NewFileStore(FileStoreOptions{Path: path, Passphrase: "correct horse battery staple", scryptWorkFactor: 10})
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	return s
+}
+
+func TestFileStore_CreateAccessAndPersist(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), ".waxseal", "vault.age")
+	s := newTestFileStore(t, path)
+
+	resource := "projects/test/secrets/my-secret"
+	if _, err := s.CreateSecret(ctx, resource, []byte("v1-value")); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+	version, err := s.AddVersion(ctx, resource, []byte("v2-value"))
+	if err != nil {
+		t.Fatalf("AddVersion failed: %v", err)
+	}
+	if version != "2" {
+		t.Errorf("version = %q, want %q", version, "2")
+	}
+
+	// File on disk is armored ciphertext, never plaintext
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !bytes.HasPrefix(raw, []byte("-----BEGIN AGE ENCRYPTED FILE-----")) {
+		t.Errorf("store file is not ASCII-armored age: %q", raw[:40])
+	}
+	if bytes.Contains(raw, []byte("v1-value")) || bytes.Contains(raw, []byte("my-secret")) {
+		t.Error("store file leaks plaintext")
+	}
+
+	// A fresh instance reads the same data back
+	reopened := newTestFileStore(t, path)
+	for v, want := range map[string]string{"1": "v1-value", "2": "v2-value"} {
+		got, err := reopened.AccessVersion(ctx, resource, v)
+		if err != nil {
+			t.Fatalf("AccessVersion(%s) failed: %v", v, err)
+		}
+		if string(got) != want {
+			t.Errorf("version %s = %q, want %q", v, got, want)
+		}
+	}
+}
+
+func TestFileStore_MissingFileIsEmpty(t *testing.T) {
+	ctx := context.Background()
+	s := newTestFileStore(t, filepath.Join(t.TempDir(), "vault.age"))
+
+	exists, err := s.SecretExists(ctx, "projects/test/secrets/x")
+	if err != nil {
+		t.Fatalf("SecretExists failed: %v", err)
+	}
+	if exists {
+		t.Error("secret should not exist in empty store")
+	}
+
+	_, err = s.AccessVersion(ctx, "projects/test/secrets/x", "1")
+	if !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFileStore_Errors(t *testing.T) {
+	ctx := context.Background()
+	s := newTestFileStore(t, filepath.Join(t.TempDir(), "vault.age"))
+
+	resource := "projects/test/secrets/dup"
+	s.CreateSecret(ctx, resource, []byte("a"))
+
+	if _, err := s.CreateSecret(ctx, resource, []byte("b")); !errors.Is(err, core.ErrAlreadyExists) {
+		t.Errorf("CreateSecret: expected ErrAlreadyExists, got %v", err)
+	}
+	if _, err := s.AddVersion(ctx, "projects/test/secrets/missing", []byte("b")); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("AddVersion: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.AccessVersion(ctx, resource, "latest"); !errors.Is(err, core.ErrValidation) {
+		t.Errorf("AccessVersion alias: expected ErrValidation, got %v", err)
+	}
+	if _, err := s.AccessVersion(ctx, resource, "9"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("AccessVersion missing version: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.CreateSecret(ctx, "bad-resource", []byte("x")); !errors.Is(err, core.ErrValidation) {
+		t.Errorf("CreateSecret bad resource: expected ErrValidation, got %v", err)
+	}
+}
+
+func TestFileStore_WrongPassphrase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.age")
+	s := newTestFileStore(t, path)
+	s.CreateSecret(ctx, "projects/test/secrets/x", []byte("value"))
+
+	wrong, err := NewFileStore(FileStoreOptions{Path: path, Passphrase: "wrong", scryptWorkFactor: 10})
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	_, err = wrong.AccessVersion(ctx, "projects/test/secrets/x", "1")
+	if !errors.Is(err, core.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestFileStore_Recipients(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity failed: %v", err)
+	}
+	identityFile := filepath.Join(dir, "key.txt")
+	os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0o600)
+
+	path := filepath.Join(dir, "vault.age")
+	s, err := NewFileStore(FileStoreOptions{
+		Path:         path,
+		Recipients:   []string{identity.Recipient().String()},
+		IdentityFile: identityFile,
+	})
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+
+	version, err := s.CreateSecretVersion(ctx, "projects/test/secrets/x", []byte("value"))
+	if err != nil {
+		t.Fatalf("CreateSecretVersion failed: %v", err)
+	}
+	got, err := s.AccessVersion(ctx, "projects/test/secrets/x", version)
+	if err != nil {
+		t.Fatalf("AccessVersion failed: %v", err)
+	}
+	if string(got) != "value" {
+		t.Errorf("got %q, want %q", got, "value")
+	}
+
+	// Without an identity the store can be written to only while it's empty
+	writeOnly, _ := NewFileStore(FileStoreOptions{
+		Path:       path,
+		Recipients: []string{identity.Recipient().String()},
+	})
+	if _, err := writeOnly.AccessVersion(ctx, "projects/test/secrets/x", "1"); !errors.Is(err, core.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated without identity, got %v", err)
+	}
+}
+
+func TestNewFileStore_Validation(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts FileStoreOptions
+	}{
+		{"no path", FileStoreOptions{Passphrase: "p"}},
+		{"no key material", FileStoreOptions{Path: "x"}},
+		{"both modes", FileStoreOptions{Path: "x", Passphrase: "p", Recipients: []string{"age1x"}}},
+		{"bad recipient", FileStoreOptions{Path: "x", Recipients: []string{"not-a-recipient"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewFileStore(tc.opts); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
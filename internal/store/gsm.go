@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"time"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
@@ -168,6 +169,16 @@ func (g *GSMStore) SecretVersionExists(ctx context.Context, secretResource strin
 	return true, enabled, nil
 }
 
+// VersionCreateTime returns when a version was created.
+func (g *GSMStore) VersionCreateTime(ctx context.Context, secretResource string, version string) (time.Time, error) {
+	name := secretResource + "/versions/" + version
+	result, err := g.client.GetSecretVersion(ctx, &secretmanagerpb.GetSecretVersionRequest{Name: name})
+	if err != nil {
+		return time.Time{}, wrapGRPCError(err, name, "get secret version")
+	}
+	return result.GetCreateTime().AsTime(), nil
+}
+
 // extractVersionFromName extracts the version number from a full version resource name.
 func extractVersionFromName(name string) string {
 	// name format: projects/.../secrets/.../versions/<version>
@@ -221,5 +232,10 @@ func ValidateNumericVersion(version string) error {
 	return nil
 }
 
-// Compile-time check that GSMStore implements Store.
-var _ Store = (*GSMStore)(nil)
+// Compile-time checks that GSMStore implements Store, VersionChecker and
+// VersionTimer.
+var (
+	_ Store          = (*GSMStore)(nil)
+	_ VersionChecker = (*GSMStore)(nil)
+	_ VersionTimer   = (*GSMStore)(nil)
+)
@@ -4,10 +4,12 @@ package store
 import (
 	"context"
 	"strings"
+	"time"
 )
 
 // Store is the interface for secret storage backends.
-// Implementations include GSM (Google Secret Manager) and in-memory fakes for testing.
+// Implementations include GSM (Google Secret Manager), HashiCorp Vault KV v2,
+// and in-memory fakes for testing.
 type Store interface {
 	// AccessVersion retrieves a specific version of a secret.
 	// Returns the secret value as bytes.
@@ -34,6 +36,21 @@ type Store interface {
 	SecretExists(ctx context.Context, secretResource string) (bool, error)
 }
 
+// VersionChecker is implemented by stores that can check whether a version
+// exists without reading its payload.
+type VersionChecker interface {
+	// SecretVersionExists returns (exists, stateEnabled, error).
+	SecretVersionExists(ctx context.Context, secretResource string, version string) (bool, bool, error)
+}
+
+// VersionTimer is implemented by stores that record when each version was
+// created.
+type VersionTimer interface {
+	// VersionCreateTime returns when a version was created.
+	// Returns ErrNotFound if the secret or version doesn't exist.
+	VersionCreateTime(ctx context.Context, secretResource string, version string) (time.Time, error)
+}
+
 // SecretResource constructs a GSM secret resource path.
 // Format: projects/<project>/secrets/<secretId>
 func SecretResource(project, secretID string) string {
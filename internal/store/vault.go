@@ -0,0 +1,352 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// VaultStore implements Store using the HashiCorp Vault KV v2 secrets engine.
+//
+// Secret resources keep the GSM-style "projects/<project>/secrets/<secretId>"
+// form used throughout metadata. They map onto KV v2 paths as
+// <mount>/data/<project>/<secretId>, so the config's projectId acts as the
+// folder that groups waxseal-managed secrets inside the mount.
+//
+// Values are stored base64-encoded under the "value" field of each KV
+// version so that arbitrary bytes round-trip unchanged.
+type VaultStore struct {
+	client    *http.Client
+	address   string
+	token     string
+	mount     string
+	namespace string
+}
+
+// VaultOptions configures a VaultStore.
+type VaultOptions struct {
+	Address   string // e.g. "https://vault.example.com:8200"
+	Token     string // Vault token (typically from $VAULT_TOKEN)
+	Mount     string // KV v2 mount path, default "secret"
+	Namespace string // Vault Enterprise namespace (optional)
+}
+
+// vaultValueField is the KV v2 data field that holds the secret payload.
+const vaultValueField = "value"
+
+// NewVaultStore creates a new Vault KV v2 store.
+func NewVaultStore(opts VaultOptions) (*VaultStore, error) {
+	if opts.Address == "" {
+		return nil, core.NewValidationError("vault.address", "required")
+	}
+	if opts.Token == "" {
+		return nil, core.NewValidationError("vault.token", "required (set VAULT_TOKEN)")
+	}
+	mount := strings.Trim(opts.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+
+	return &VaultStore{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		address:   strings.TrimRight(opts.Address, "/"),
+		token:     opts.Token,
+		mount:     mount,
+		namespace: opts.Namespace,
+	}, nil
+}
+
+// vaultSecretData is the "data" object of a KV v2 read response.
+type vaultSecretData struct {
+	Data     map[string]string   `json:"data"`
+	Metadata vaultVersionSummary `json:"metadata"`
+}
+
+// vaultVersionSummary describes a single KV v2 version.
+type vaultVersionSummary struct {
+	Version      int    `json:"version"`
+	CreatedTime  string `json:"created_time"`
+	DeletionTime string `json:"deletion_time"`
+	Destroyed    bool   `json:"destroyed"`
+}
+
+// vaultMetadata is the "data" object of a KV v2 metadata response.
+type vaultMetadata struct {
+	CurrentVersion int                            `json:"current_version"`
+	Versions       map[string]vaultVersionSummary `json:"versions"`
+}
+
+// AccessVersion retrieves a specific version of a secret.
+func (v *VaultStore) AccessVersion(ctx context.Context, secretResource string, version string) ([]byte, error) {
+	if !numericVersionPattern.MatchString(version) {
+		return nil, core.NewValidationError("version", "must be numeric (aliases like 'latest' are not supported)")
+	}
+
+	path, err := v.kvPath(secretResource)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data vaultSecretData `json:"data"`
+	}
+	resource := secretResource + "/versions/" + version
+	query := url.Values{"version": []string{version}}
+	if err := v.do(ctx, http.MethodGet, v.mount+"/data/"+path, query, nil, &resp, resource); err != nil {
+		return nil, err
+	}
+
+	// KV v2 returns 200 with null data for soft-deleted versions
+	encoded, ok := resp.Data.Data[vaultValueField]
+	if !ok {
+		return nil, core.WrapNotFound(resource, nil)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode value: %w", resource, err)
+	}
+	return data, nil
+}
+
+// AddVersion adds a new version to an existing secret.
+func (v *VaultStore) AddVersion(ctx context.Context, secretResource string, data []byte) (string, error) {
+	meta, err := v.readMetadata(ctx, secretResource)
+	if err != nil {
+		return "", err
+	}
+	return v.write(ctx, secretResource, data, meta.CurrentVersion)
+}
+
+// CreateSecret creates a new secret with an initial version.
+func (v *VaultStore) CreateSecret(ctx context.Context, secretResource string, data []byte) (string, error) {
+	exists, err := v.SecretExists(ctx, secretResource)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("%s: %w", secretResource, core.ErrAlreadyExists)
+	}
+
+	// cas=0 only succeeds if the path has never been written
+	return v.write(ctx, secretResource, data, 0)
+}
+
+// CreateSecretVersion creates a secret if it doesn't exist and adds a version.
+func (v *VaultStore) CreateSecretVersion(ctx context.Context, secretResource string, data []byte) (string, error) {
+	version, err := v.AddVersion(ctx, secretResource, data)
+	if err == nil {
+		return version, nil
+	}
+
+	if core.IsNotFound(err) {
+		return v.CreateSecret(ctx, secretResource, data)
+	}
+
+	return "", err
+}
+
+// SecretExists checks if a secret exists.
+func (v *VaultStore) SecretExists(ctx context.Context, secretResource string) (bool, error) {
+	_, err := v.readMetadata(ctx, secretResource)
+	if err != nil {
+		if core.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// SecretVersionExists checks if a specific version of a secret exists.
+// Returns (exists, stateEnabled, error). Soft-deleted and destroyed versions
+// exist but are not enabled.
+func (v *VaultStore) SecretVersionExists(ctx context.Context, secretResource string, version string) (bool, bool, error) {
+	meta, err := v.readMetadata(ctx, secretResource)
+	if err != nil {
+		if core.IsNotFound(err) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	summary, ok := meta.Versions[version]
+	if !ok {
+		return false, false, nil
+	}
+	enabled := !summary.Destroyed && summary.DeletionTime == ""
+	return true, enabled, nil
+}
+
+// VersionCreateTime returns when a version was created, from its KV v2
+// metadata.
+func (v *VaultStore) VersionCreateTime(ctx context.Context, secretResource string, version string) (time.Time, error) {
+	meta, err := v.readMetadata(ctx, secretResource)
+	if err != nil {
+		return time.Time{}, err
+	}
+	summary, ok := meta.Versions[version]
+	if !ok {
+		return time.Time{}, core.WrapNotFound(secretResource+"/versions/"+version, nil)
+	}
+	created, err := time.Parse(time.RFC3339Nano, summary.CreatedTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s/versions/%s: parse created_time: %w", secretResource, version, err)
+	}
+	return created, nil
+}
+
+// readMetadata fetches the KV v2 metadata for a secret.
+func (v *VaultStore) readMetadata(ctx context.Context, secretResource string) (*vaultMetadata, error) {
+	path, err := v.kvPath(secretResource)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data vaultMetadata `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodGet, v.mount+"/metadata/"+path, nil, nil, &resp, secretResource); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// write stores a new version using check-and-set against the expected
+// current version, so concurrent writers cannot silently clobber each other.
+func (v *VaultStore) write(ctx context.Context, secretResource string, data []byte, cas int) (string, error) {
+	path, err := v.kvPath(secretResource)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]any{
+		"data": map[string]string{
+			vaultValueField: base64.StdEncoding.EncodeToString(data),
+		},
+		"options": map[string]int{"cas": cas},
+	}
+
+	var resp struct {
+		Data vaultVersionSummary `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodPost, v.mount+"/data/"+path, nil, body, &resp, secretResource); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(resp.Data.Version), nil
+}
+
+// kvPath maps a secret resource onto a path relative to the KV mount.
+func (v *VaultStore) kvPath(secretResource string) (string, error) {
+	project, secretID := splitSecretResource(secretResource)
+	if project == "" || secretID == "" {
+		return "", core.NewValidationError("secretResource", "must be projects/<project>/secrets/<secretId>")
+	}
+	return project + "/" + secretID, nil
+}
+
+// do performs a Vault API request and decodes the JSON response into out.
+func (v *VaultStore) do(ctx context.Context, method, path string, query url.Values, body any, out any, resource string) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode vault request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	u := v.address + "/v1/" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read vault response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return wrapVaultError(resp.StatusCode, respBody, resource)
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("decode vault response: %w", err)
+		}
+	}
+	return nil
+}
+
+// wrapVaultError maps Vault HTTP status codes onto domain error types.
+func wrapVaultError(statusCode int, body []byte, resource string) error {
+	var apiErr struct {
+		Errors []string `json:"errors"`
+	}
+	_ = json.Unmarshal(body, &apiErr)
+	detail := fmt.Errorf("vault: HTTP %d: %s", statusCode, strings.Join(apiErr.Errors, "; "))
+
+	switch statusCode {
+	case http.StatusNotFound:
+		return core.WrapNotFound(resource, nil)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%s: %w — check VAULT_TOKEN: %v", resource, core.ErrUnauthenticated, detail)
+	case http.StatusForbidden:
+		return core.WrapPermissionDenied(resource, detail)
+	case http.StatusBadRequest:
+		// KV v2 reports check-and-set mismatches as 400
+		for _, msg := range apiErr.Errors {
+			if strings.Contains(msg, "check-and-set") {
+				return fmt.Errorf("%s: %w: %v", resource, core.ErrAlreadyExists, detail)
+			}
+		}
+		return core.WrapValidation(resource, detail)
+	}
+	return fmt.Errorf("%s: %w", resource, detail)
+}
+
+// splitSecretResource splits "projects/<project>/secrets/<secretId>" into
+// its project and secret ID components. Returns empty strings if malformed.
+func splitSecretResource(resource string) (project, secretID string) {
+	parts := strings.Split(resource, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != "secrets" {
+		return "", ""
+	}
+	return parts[1], parts[3]
+}
+
+// Compile-time checks that VaultStore implements Store, VersionChecker and
+// VersionTimer.
+var (
+	_ Store          = (*VaultStore)(nil)
+	_ VersionChecker = (*VaultStore)(nil)
+	_ VersionTimer   = (*VaultStore)(nil)
+)
@@ -0,0 +1,347 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shermanhuman/waxseal/internal/core"
+)
+
+// fakeVaultKV is a minimal in-process implementation of the Vault KV v2 API.
+type fakeVaultKV struct {
+	mu      sync.Mutex
+	token   string
+	mount   string
+	secrets map[string][]map[string]string // path -> versions (index 0 = version 1)
+}
+
+func newFakeVaultServer(t *testing.T) (*httptest.Server, *fakeVaultKV) {
+	t.Helper()
+	kv := &fakeVaultKV{
+		token:   "test-token",
+		mount:   "secret",
+		secrets: make(map[string][]map[string]string),
+	}
+	srv := httptest.NewServer(kv)
+	t.Cleanup(srv.Close)
+	return srv, kv
+}
+
+func (kv *fakeVaultKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != kv.token {
+		writeVaultJSON(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	dataPrefix := "/v1/" + kv.mount + "/data/"
+	metaPrefix := "/v1/" + kv.mount + "/metadata/"
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, metaPrefix) && r.Method == http.MethodGet:
+		path := strings.TrimPrefix(r.URL.Path, metaPrefix)
+		versions, ok := kv.secrets[path]
+		if !ok {
+			writeVaultJSON(w, http.StatusNotFound, map[string]any{"errors": []string{}})
+			return
+		}
+		summaries := make(map[string]any)
+		for i := range versions {
+			summaries[strconv.Itoa(i+1)] = map[string]any{
+				"created_time":  fmt.Sprintf("2026-01-%02dT10:00:00.123456Z", i+1),
+				"deletion_time": "",
+				"destroyed":     false,
+			}
+		}
+		writeVaultJSON(w, http.StatusOK, map[string]any{
+			"data": map[string]any{"current_version": len(versions), "versions": summaries},
+		})
+
+	case strings.HasPrefix(r.URL.Path, dataPrefix) && r.Method == http.MethodGet:
+		path := strings.TrimPrefix(r.URL.Path, dataPrefix)
+		versions, ok := kv.secrets[path]
+		if !ok {
+			writeVaultJSON(w, http.StatusNotFound, map[string]any{"errors": []string{}})
+			return
+		}
+		v, err := strconv.Atoi(r.URL.Query().Get("version"))
+		if err != nil || v < 1 || v > len(versions) {
+			writeVaultJSON(w, http.StatusNotFound, map[string]any{"errors": []string{}})
+			return
+		}
+		writeVaultJSON(w, http.StatusOK, map[string]any{
+			"data": map[string]any{"data": versions[v-1], "metadata": map[string]any{"version": v}},
+		})
+
+	case strings.HasPrefix(r.URL.Path, dataPrefix) && r.Method == http.MethodPost:
+		path := strings.TrimPrefix(r.URL.Path, dataPrefix)
+		var body struct {
+			Data    map[string]string `json:"data"`
+			Options struct {
+				CAS *int `json:"cas"`
+			} `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeVaultJSON(w, http.StatusBadRequest, map[string]any{"errors": []string{err.Error()}})
+			return
+		}
+		current := len(kv.secrets[path])
+		if body.Options.CAS != nil && *body.Options.CAS != current {
+			writeVaultJSON(w, http.StatusBadRequest, map[string]any{
+				"errors": []string{"check-and-set parameter did not match the current version"},
+			})
+			return
+		}
+		kv.secrets[path] = append(kv.secrets[path], body.Data)
+		writeVaultJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"version": current + 1}})
+
+	default:
+		writeVaultJSON(w, http.StatusMethodNotAllowed, map[string]any{"errors": []string{"unsupported"}})
+	}
+}
+
+func writeVaultJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestVaultStore(t *testing.T) (*VaultStore, *fakeVaultKV) {
+	t.Helper()
+	srv, kv := newFakeVaultServer(t)
+	s, err := NewVaultStore(VaultOptions{Address: srv.URL, Token: kv.token})
+	if err != nil {
+		t.Fatalf("NewVaultStore failed: %v", err)
+	}
+	return s, kv
+}
+
+func TestVaultStore_CreateAndAccess(t *testing.T) {
+	ctx := context.Background()
+	s, kv := newTestVaultStore(t)
+
+	resource := "projects/test/secrets/my-secret"
+	version, err := s.CreateSecret(ctx, resource, []byte("secret-value"))
+	if err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+	if version != "1" {
+		t.Errorf("version = %q, want %q", version, "1")
+	}
+
+	// Resource maps onto <mount>/data/<project>/<secretId>
+	if _, ok := kv.secrets["test/my-secret"]; !ok {
+		t.Errorf("expected KV path test/my-secret, have %v", kv.secrets)
+	}
+
+	got, err := s.AccessVersion(ctx, resource, "1")
+	if err != nil {
+		t.Fatalf("AccessVersion failed: %v", err)
+	}
+	if string(got) != "secret-value" {
+		t.Errorf("got %q, want %q", got, "secret-value")
+	}
+}
+
+func TestVaultStore_AddVersion(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	resource := "projects/test/secrets/my-secret"
+	if _, err := s.CreateSecret(ctx, resource, []byte("v1-value")); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+
+	version, err := s.AddVersion(ctx, resource, []byte("v2-value"))
+	if err != nil {
+		t.Fatalf("AddVersion failed: %v", err)
+	}
+	if version != "2" {
+		t.Errorf("version = %q, want %q", version, "2")
+	}
+
+	v1, _ := s.AccessVersion(ctx, resource, "1")
+	if string(v1) != "v1-value" {
+		t.Errorf("v1 = %q, want %q", v1, "v1-value")
+	}
+	v2, _ := s.AccessVersion(ctx, resource, "2")
+	if string(v2) != "v2-value" {
+		t.Errorf("v2 = %q, want %q", v2, "v2-value")
+	}
+}
+
+func TestVaultStore_BinaryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	resource := "projects/test/secrets/binary"
+	data := []byte{0x00, 0xff, 0x10, '\n', 0x80}
+	if _, err := s.CreateSecret(ctx, resource, data); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+
+	got, err := s.AccessVersion(ctx, resource, "1")
+	if err != nil {
+		t.Fatalf("AccessVersion failed: %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("got %v, want %v", got, data)
+	}
+}
+
+func TestVaultStore_NotFound(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	_, err := s.AccessVersion(ctx, "projects/test/secrets/nonexistent", "1")
+	if !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("AccessVersion: expected ErrNotFound, got %v", err)
+	}
+
+	_, err = s.AddVersion(ctx, "projects/test/secrets/nonexistent", []byte("x"))
+	if !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("AddVersion: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestVaultStore_CreateSecretAlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	resource := "projects/test/secrets/dup"
+	if _, err := s.CreateSecret(ctx, resource, []byte("a")); err != nil {
+		t.Fatalf("CreateSecret failed: %v", err)
+	}
+
+	_, err := s.CreateSecret(ctx, resource, []byte("b"))
+	if !errors.Is(err, core.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+}
+
+func TestVaultStore_CreateSecretVersion(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	resource := "projects/test/secrets/idempotent"
+	v1, err := s.CreateSecretVersion(ctx, resource, []byte("first"))
+	if err != nil {
+		t.Fatalf("CreateSecretVersion (create) failed: %v", err)
+	}
+	v2, err := s.CreateSecretVersion(ctx, resource, []byte("second"))
+	if err != nil {
+		t.Fatalf("CreateSecretVersion (add) failed: %v", err)
+	}
+	if v1 != "1" || v2 != "2" {
+		t.Errorf("versions = %q, %q, want 1, 2", v1, v2)
+	}
+}
+
+func TestVaultStore_SecretExists(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	resource := "projects/test/secrets/exists"
+	exists, err := s.SecretExists(ctx, resource)
+	if err != nil {
+		t.Fatalf("SecretExists failed: %v", err)
+	}
+	if exists {
+		t.Error("secret should not exist yet")
+	}
+
+	s.CreateSecret(ctx, resource, []byte("x"))
+
+	exists, err = s.SecretExists(ctx, resource)
+	if err != nil {
+		t.Fatalf("SecretExists failed: %v", err)
+	}
+	if !exists {
+		t.Error("secret should exist")
+	}
+
+	found, enabled, err := s.SecretVersionExists(ctx, resource, "1")
+	if err != nil || !found || !enabled {
+		t.Errorf("SecretVersionExists(1) = %v, %v, %v; want true, true, nil", found, enabled, err)
+	}
+	found, _, err = s.SecretVersionExists(ctx, resource, "2")
+	if err != nil || found {
+		t.Errorf("SecretVersionExists(2) = %v, %v; want false, nil", found, err)
+	}
+}
+
+func TestVaultStore_VersionCreateTime(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	resource := "projects/test/secrets/timed"
+	s.CreateSecret(ctx, resource, []byte("v1"))
+	s.AddVersion(ctx, resource, []byte("v2"))
+
+	created, err := s.VersionCreateTime(ctx, resource, "2")
+	if err != nil {
+		t.Fatalf("VersionCreateTime failed: %v", err)
+	}
+	want := time.Date(2026, 1, 2, 10, 0, 0, 123456000, time.UTC)
+	if !created.Equal(want) {
+		t.Errorf("VersionCreateTime = %v, want %v", created, want)
+	}
+
+	if _, err := s.VersionCreateTime(ctx, resource, "3"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing version, got %v", err)
+	}
+}
+
+func TestVaultStore_RejectsAliasVersion(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	_, err := s.AccessVersion(ctx, "projects/test/secrets/x", "latest")
+	if !errors.Is(err, core.ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+}
+
+func TestVaultStore_PermissionDenied(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := newFakeVaultServer(t)
+	s, err := NewVaultStore(VaultOptions{Address: srv.URL, Token: "wrong-token"})
+	if err != nil {
+		t.Fatalf("NewVaultStore failed: %v", err)
+	}
+
+	_, err = s.AccessVersion(ctx, "projects/test/secrets/x", "1")
+	if !errors.Is(err, core.ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+}
+
+func TestVaultStore_InvalidResource(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestVaultStore(t)
+
+	_, err := s.CreateSecret(ctx, "not-a-resource", []byte("x"))
+	if !errors.Is(err, core.ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+}
+
+func TestNewVaultStore_RequiresAddressAndToken(t *testing.T) {
+	if _, err := NewVaultStore(VaultOptions{Token: "t"}); err == nil {
+		t.Error("expected error for missing address")
+	}
+	if _, err := NewVaultStore(VaultOptions{Address: "http://127.0.0.1:8200"}); err == nil {
+		t.Error("expected error for missing token")
+	}
+}